@@ -25,5 +25,5 @@ function chooseBinary() {
 
 const binary = chooseBinary()
 const mainScript = `${__dirname}/dist/${binary}`
-const spawnSyncReturns = childProcess.spawnSync(mainScript, { stdio: 'inherit' })
+const spawnSyncReturns = childProcess.spawnSync(mainScript, process.argv.slice(2), { stdio: 'inherit' })
 process.exit(spawnSyncReturns.status ?? 0)
@@ -0,0 +1,146 @@
+package approval
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+)
+
+const (
+	// DecisionApproved is the decision of an approver who approved the request
+	DecisionApproved = "approved"
+
+	// DecisionRejected is the decision of an approver who rejected the request
+	DecisionRejected = "rejected"
+
+	// PolicyFailOnReject fails the step when the request is rejected
+	PolicyFailOnReject = "fail-on-reject"
+
+	// PolicyAlwaysSucceed lets the step succeed whatever the decision, leaving later steps
+	// to act on the decision output
+	PolicyAlwaysSucceed = "always-succeed"
+
+	// OutputKeyDecision is the key of the output holding the decision (approved or rejected)
+	OutputKeyDecision = "decision"
+
+	// OutputKeyComment is the key of the output holding the comment left with the decision
+	OutputKeyComment = "comment"
+
+	// OutputKeyApprover is the key of the output holding who made the decision
+	OutputKeyApprover = "approver"
+
+	// OutputKeyApprovers is the key of the output holding every approver and their
+	// decision, as a JSON array
+	OutputKeyApprovers = "approvers"
+
+	// FormFieldDecision is the name of the approve and reject buttons of the portal
+	FormFieldDecision = "approval-decision"
+
+	// FormFieldComment is the name of the portal's comment field
+	FormFieldComment = "approval-comment"
+
+	// FormValueApprove is the value submitted by the approve button
+	FormValueApprove = "approve"
+
+	// FormValueReject is the value submitted by the reject button
+	FormValueReject = "reject"
+)
+
+var (
+	// ValidPolicies is the list of ways the step can end once a decision is made
+	ValidPolicies = []string{PolicyFailOnReject, PolicyAlwaysSucceed}
+
+	// OutputKeys is the list of outputs set with the decision
+	OutputKeys = []string{OutputKeyDecision, OutputKeyComment, OutputKeyApprover}
+)
+
+// Decision represents an approver's decision on the request
+type Decision struct {
+
+	// Decision is whether the request was approved or rejected
+	Decision string `json:"decision"`
+
+	// Comment is the comment left with the decision
+	Comment string `json:"comment"`
+
+	// Approver is who made the decision, their GitHub login when known or else the IP
+	// address they made it from
+	Approver string `json:"approver"`
+}
+
+// NewDecisionRequest is the request object for creating a new instance of Decision
+type NewDecisionRequest struct {
+
+	// FormValue is the value submitted by the button that was clicked (approve or reject)
+	FormValue string
+
+	// Comment is the comment left with the decision
+	Comment string
+
+	// Approver is who made the decision
+	Approver string
+}
+
+// NewDecision returns the decision made with the portal's buttons. It returns
+// ErrInvalidApprovalDecision when neither button was clicked and ErrApprovalCommentRequired
+// when the request is rejected without a comment
+func NewDecision(r *NewDecisionRequest) (*Decision, error) {
+
+	comment := strings.TrimSpace(r.Comment)
+
+	var decision string
+	switch strings.ToLower(strings.TrimSpace(r.FormValue)) {
+	case FormValueApprove:
+		decision = DecisionApproved
+	case FormValueReject:
+		decision = DecisionRejected
+	default:
+		return nil, errors.ErrInvalidApprovalDecision
+	}
+
+	if decision == DecisionRejected && comment == "" {
+		return nil, errors.ErrApprovalCommentRequired
+	}
+
+	return &Decision{
+		Decision: decision,
+		Comment:  comment,
+		Approver: r.Approver,
+	}, nil
+}
+
+// Approved returns whether the request was approved
+func (d *Decision) Approved() bool {
+	return d.Decision == DecisionApproved
+}
+
+// FailsStep returns whether the step should fail with the decision under the policy,
+// an empty policy being treated as fail-on-reject
+func (d *Decision) FailsStep(policy string) bool {
+	return !d.Approved() && policy != PolicyAlwaysSucceed
+}
+
+// Outputs returns the decision keyed by output key
+func (d *Decision) Outputs() map[string]string {
+	return map[string]string{
+		OutputKeyDecision: d.Decision,
+		OutputKeyComment:  d.Comment,
+		OutputKeyApprover: d.Approver,
+	}
+}
+
+// Message returns the decision in a human readable form, i.e. for the notifiers
+func (d *Decision) Message() string {
+
+	message := fmt.Sprintf("✅ Approved by %s", d.Approver)
+	if !d.Approved() {
+		message = fmt.Sprintf("❌ Rejected by %s", d.Approver)
+	}
+
+	if d.Comment != "" {
+		message += fmt.Sprintf(": %s", d.Comment)
+	}
+
+	return message
+}
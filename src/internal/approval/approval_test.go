@@ -0,0 +1,78 @@
+package approval_test
+
+import (
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/approval"
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDecision(t *testing.T) {
+
+	tests := []struct {
+		name             string
+		request          *approval.NewDecisionRequest
+		expectedDecision *approval.Decision
+		expectedErr      error
+	}{
+		{
+			name:             "approved without a comment",
+			request:          &approval.NewDecisionRequest{FormValue: "approve", Approver: "octocat"},
+			expectedDecision: &approval.Decision{Decision: approval.DecisionApproved, Approver: "octocat"},
+		},
+		{
+			name:             "rejected with a comment",
+			request:          &approval.NewDecisionRequest{FormValue: "reject", Comment: "  Not during the freeze \n", Approver: "octocat"},
+			expectedDecision: &approval.Decision{Decision: approval.DecisionRejected, Comment: "Not during the freeze", Approver: "octocat"},
+		},
+		{
+			name:        "rejected without a comment",
+			request:     &approval.NewDecisionRequest{FormValue: "reject", Comment: "   ", Approver: "octocat"},
+			expectedErr: errors.ErrApprovalCommentRequired,
+		},
+		{
+			name:        "neither approved nor rejected",
+			request:     &approval.NewDecisionRequest{FormValue: "maybe", Approver: "octocat"},
+			expectedErr: errors.ErrInvalidApprovalDecision,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := approval.NewDecision(tt.request)
+
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expectedDecision, decision)
+		})
+	}
+}
+
+func TestDecision_FailsStep(t *testing.T) {
+
+	approved := &approval.Decision{Decision: approval.DecisionApproved, Approver: "octocat"}
+	rejected := &approval.Decision{Decision: approval.DecisionRejected, Comment: "Not yet", Approver: "octocat"}
+
+	tests := []struct {
+		name     string
+		decision *approval.Decision
+		policy   string
+		expected bool
+	}{
+		{name: "approved", decision: approved, policy: approval.PolicyFailOnReject, expected: false},
+		{name: "rejected", decision: rejected, policy: approval.PolicyFailOnReject, expected: true},
+		{name: "rejected with the default policy", decision: rejected, policy: "", expected: true},
+		{name: "rejected but always succeeding", decision: rejected, policy: approval.PolicyAlwaysSucceed, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.decision.FailsStep(tt.policy))
+		})
+	}
+}
+
+func TestDecision_Message(t *testing.T) {
+	assert.Equal(t, "✅ Approved by octocat", (&approval.Decision{Decision: approval.DecisionApproved, Approver: "octocat"}).Message())
+	assert.Equal(t, "❌ Rejected by octocat: Not yet", (&approval.Decision{Decision: approval.DecisionRejected, Comment: "Not yet", Approver: "octocat"}).Message())
+}
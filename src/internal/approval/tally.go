@@ -0,0 +1,198 @@
+package approval
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+)
+
+// NewTallyRequest is the request object for creating a new instance of Tally
+type NewTallyRequest struct {
+
+	// Quorum is how many distinct approvers have to approve the request
+	Quorum int
+
+	// RequiredUsers is the list of GitHub logins who have to approve the request
+	RequiredUsers []string
+
+	// RequiredTeams is the list of teams (org/team-slug) at least one member of which has
+	// to approve the request
+	RequiredTeams []string
+}
+
+// NewTally returns a new instance of Tally, a quorum below 1 being treated as 1
+func NewTally(r *NewTallyRequest) *Tally {
+
+	quorum := r.Quorum
+	if quorum < 1 {
+		quorum = 1
+	}
+
+	requiredUsers := []string{}
+	for _, user := range r.RequiredUsers {
+		requiredUsers = append(requiredUsers, strings.TrimPrefix(user, "@"))
+	}
+
+	return &Tally{
+		quorum:        quorum,
+		requiredUsers: requiredUsers,
+		requiredTeams: r.RequiredTeams,
+		approverTeams: make(map[string][]string),
+	}
+}
+
+// Tally collects the decisions of distinct approvers until the quorum is reached, or
+// one of them rejects (vetoes) the request
+type Tally struct {
+
+	// mutex guards the tally
+	mutex sync.Mutex
+
+	// quorum is how many distinct approvers have to approve the request
+	quorum int
+
+	// requiredUsers is the list of GitHub logins who have to approve the request
+	requiredUsers []string
+
+	// requiredTeams is the list of teams at least one member of which has to approve
+	requiredTeams []string
+
+	// decisions is the list of decisions made, in the order they were made
+	decisions []Decision
+
+	// approverTeams mapping of approver to the required teams they are a member of
+	approverTeams map[string][]string
+
+	// outcome is the decision the tally came to, nil while it is still collecting
+	outcome *Decision
+}
+
+// TallyStatus represents how far the tally is from coming to a decision
+type TallyStatus struct {
+
+	// Quorum is how many distinct approvers have to approve the request
+	Quorum int
+
+	// Approvals is how many distinct approvers have approved the request
+	Approvals int
+
+	// Decisions is the list of decisions made, in the order they were made
+	Decisions []Decision
+
+	// PendingUsers is the list of required users who haven't approved the request yet
+	PendingUsers []string
+
+	// PendingTeams is the list of required teams no member of which has approved the
+	// request yet
+	PendingTeams []string
+
+	// Outcome is the decision the tally came to, nil while it is still collecting
+	Outcome *Decision
+}
+
+// RequiredTeams returns the teams at least one member of which has to approve the request
+func (t *Tally) RequiredTeams() []string {
+	return t.requiredTeams
+}
+
+// Add counts the decision, along with the required teams its approver is a member of. It
+// returns the decision the tally came to once the quorum is reached (and every required
+// user and team has approved) or as soon as the request is rejected, and nil while more
+// decisions are needed. Approvers can only decide once, ErrApproverAlreadyDecided is
+// returned otherwise, and ErrTallyAlreadyDecided once the tally has come to a decision
+func (t *Tally) Add(decision *Decision, teams []string) (*Decision, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.outcome != nil {
+		return nil, errors.ErrTallyAlreadyDecided
+	}
+
+	for _, made := range t.decisions {
+		if strings.EqualFold(made.Approver, decision.Approver) {
+			return nil, errors.ErrApproverAlreadyDecided
+		}
+	}
+
+	t.decisions = append(t.decisions, *decision)
+	t.approverTeams[strings.ToLower(decision.Approver)] = teams
+
+	// a single rejection is enough to veto the request
+	if !decision.Approved() {
+		t.outcome = decision
+		return t.outcome, nil
+	}
+
+	if t.approvals() >= t.quorum && len(t.pendingUsers()) == 0 && len(t.pendingTeams()) == 0 {
+		t.outcome = decision
+	}
+
+	return t.outcome, nil
+}
+
+// Decisions returns the decisions made, in the order they were made
+func (t *Tally) Decisions() []Decision {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return slices.Clone(t.decisions)
+}
+
+// Status returns how far the tally is from coming to a decision
+func (t *Tally) Status() TallyStatus {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return TallyStatus{
+		Quorum:       t.quorum,
+		Approvals:    t.approvals(),
+		Decisions:    slices.Clone(t.decisions),
+		PendingUsers: t.pendingUsers(),
+		PendingTeams: t.pendingTeams(),
+		Outcome:      t.outcome,
+	}
+}
+
+// approvals returns how many distinct approvers have approved the request
+func (t *Tally) approvals() int {
+	approvals := 0
+	for _, made := range t.decisions {
+		if made.Approved() {
+			approvals++
+		}
+	}
+
+	return approvals
+}
+
+// pendingUsers returns the required users who haven't approved the request yet
+func (t *Tally) pendingUsers() []string {
+	pendingUsers := []string{}
+	for _, user := range t.requiredUsers {
+		if !slices.ContainsFunc(t.decisions, func(made Decision) bool {
+			return made.Approved() && strings.EqualFold(made.Approver, user)
+		}) {
+			pendingUsers = append(pendingUsers, user)
+		}
+	}
+
+	return pendingUsers
+}
+
+// pendingTeams returns the required teams no member of which has approved the request yet
+func (t *Tally) pendingTeams() []string {
+	pendingTeams := []string{}
+	for _, team := range t.requiredTeams {
+		if !slices.ContainsFunc(t.decisions, func(made Decision) bool {
+			return made.Approved() && slices.ContainsFunc(t.approverTeams[strings.ToLower(made.Approver)], func(memberOf string) bool {
+				return strings.EqualFold(memberOf, team)
+			})
+		}) {
+			pendingTeams = append(pendingTeams, team)
+		}
+	}
+
+	return pendingTeams
+}
@@ -0,0 +1,117 @@
+package approval_test
+
+import (
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/approval"
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTally_Add(t *testing.T) {
+
+	approve := func(approver string) *approval.Decision {
+		return &approval.Decision{Decision: approval.DecisionApproved, Approver: approver}
+	}
+	reject := func(approver string) *approval.Decision {
+		return &approval.Decision{Decision: approval.DecisionRejected, Comment: "Not yet", Approver: approver}
+	}
+
+	type vote struct {
+		decision *approval.Decision
+		teams    []string
+	}
+
+	tests := []struct {
+		name                 string
+		request              *approval.NewTallyRequest
+		votes                []vote
+		expectedOutcomes     []*approval.Decision
+		expectedErrors       []error
+		expectedPendingUsers []string
+		expectedPendingTeams []string
+	}{
+		{
+			name:             "quorum reached",
+			request:          &approval.NewTallyRequest{Quorum: 2},
+			votes:            []vote{{decision: approve("octocat")}, {decision: approve("hubot")}},
+			expectedOutcomes: []*approval.Decision{nil, approve("hubot")},
+			expectedErrors:   []error{nil, nil},
+		},
+		{
+			name:             "vetoed",
+			request:          &approval.NewTallyRequest{Quorum: 3},
+			votes:            []vote{{decision: approve("octocat")}, {decision: reject("hubot")}, {decision: approve("monalisa")}},
+			expectedOutcomes: []*approval.Decision{nil, reject("hubot"), nil},
+			expectedErrors:   []error{nil, nil, errors.ErrTallyAlreadyDecided},
+		},
+		{
+			name:             "approvers only decide once",
+			request:          &approval.NewTallyRequest{Quorum: 2},
+			votes:            []vote{{decision: approve("octocat")}, {decision: approve("OctoCat")}},
+			expectedOutcomes: []*approval.Decision{nil, nil},
+			expectedErrors:   []error{nil, errors.ErrApproverAlreadyDecided},
+		},
+		{
+			name:                 "waiting for a required user",
+			request:              &approval.NewTallyRequest{Quorum: 1, RequiredUsers: []string{"@monalisa"}},
+			votes:                []vote{{decision: approve("octocat")}, {decision: approve("hubot")}},
+			expectedOutcomes:     []*approval.Decision{nil, nil},
+			expectedErrors:       []error{nil, nil},
+			expectedPendingUsers: []string{"monalisa"},
+			expectedPendingTeams: []string{},
+		},
+		{
+			name:    "required team approved by a member",
+			request: &approval.NewTallyRequest{Quorum: 2, RequiredTeams: []string{"octo-org/release-managers"}},
+			votes: []vote{
+				{decision: approve("octocat")},
+				{decision: approve("hubot")},
+				{decision: approve("monalisa"), teams: []string{"octo-org/Release-Managers"}},
+			},
+			expectedOutcomes:     []*approval.Decision{nil, nil, approve("monalisa")},
+			expectedErrors:       []error{nil, nil, nil},
+			expectedPendingUsers: []string{},
+			expectedPendingTeams: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tally := approval.NewTally(tt.request)
+
+			outcomes := []*approval.Decision{}
+			errs := []error{}
+			for _, v := range tt.votes {
+				outcome, err := tally.Add(v.decision, v.teams)
+				outcomes = append(outcomes, outcome)
+				errs = append(errs, err)
+			}
+
+			assert.Equal(t, tt.expectedOutcomes, outcomes)
+			assert.Equal(t, tt.expectedErrors, errs)
+
+			if tt.expectedPendingUsers != nil {
+				status := tally.Status()
+				assert.Equal(t, tt.expectedPendingUsers, status.PendingUsers)
+				assert.Equal(t, tt.expectedPendingTeams, status.PendingTeams)
+			}
+		})
+	}
+}
+
+func TestTally_Status(t *testing.T) {
+	tally := approval.NewTally(&approval.NewTallyRequest{})
+
+	status := tally.Status()
+	assert.Equal(t, 1, status.Quorum)
+	assert.Equal(t, 0, status.Approvals)
+	assert.Nil(t, status.Outcome)
+
+	tally.Add(&approval.Decision{Decision: approval.DecisionApproved, Approver: "octocat"}, nil)
+
+	status = tally.Status()
+	assert.Equal(t, 1, status.Approvals)
+	assert.Equal(t, []approval.Decision{{Decision: approval.DecisionApproved, Approver: "octocat"}}, status.Decisions)
+	assert.Equal(t, &approval.Decision{Decision: approval.DecisionApproved, Approver: "octocat"}, status.Outcome)
+}
@@ -0,0 +1,303 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/sethvargo/go-githubactions"
+)
+
+const (
+	// OutputKeyAuditLogFile is the key of the output holding the path the audit log is written to
+	OutputKeyAuditLogFile = "audit-log-file"
+
+	// DefaultFileName is the name of the file the audit log is written to when a path isn't provided
+	DefaultFileName = "interactive-inputs-audit.jsonl"
+
+	// RequestIdHeaderName is the header every response carries its request's ID in, so
+	// visitors can quote it when something goes wrong
+	RequestIdHeaderName = "X-Request-Id"
+
+	// EventPageView is recorded when the portal is viewed
+	EventPageView = "page-view"
+
+	// EventUpload is recorded when files are uploaded to a file field
+	EventUpload = "upload"
+
+	// EventReset is recorded when the files uploaded to a file field are reset
+	EventReset = "reset"
+
+	// EventValidationFailure is recorded when a submission or an uploaded file is rejected
+	EventValidationFailure = "validation-failure"
+
+	// EventSubmit is recorded when the portal is submitted
+	EventSubmit = "submit"
+
+	// EventCancel is recorded when the portal is cancelled
+	EventCancel = "cancel"
+
+	// EventDecision is recorded when an approver's decision is counted towards the quorum,
+	// without closing the portal
+	EventDecision = "decision"
+
+	// forwardQueueSize is how many entries can wait to be forwarded to the webhook
+	forwardQueueSize = 256
+
+	// forwardTimeout is how long forwarding a single entry to the webhook can take
+	forwardTimeout = 5 * time.Second
+
+	// closeTimeout is how long closing the logger waits for queued entries to be forwarded
+	closeTimeout = 10 * time.Second
+)
+
+// requestIdContextKey is the key the request's ID is stored under in the request context
+type requestIdContextKey struct{}
+
+// Entry is a single interaction with the portal, written to the audit log as a line of JSON
+type Entry struct {
+
+	// Timestamp is when the interaction happened (UTC)
+	Timestamp time.Time `json:"timestamp"`
+
+	// Event is the kind of interaction, i.e. upload
+	Event string `json:"event"`
+
+	// RequestId is the ID of the request the interaction was made with
+	RequestId string `json:"request_id"`
+
+	// Ip is the IP address the request was made from
+	Ip string `json:"ip"`
+
+	// UserAgent is the user agent the request was made with
+	UserAgent string `json:"user_agent"`
+
+	// Login is the GitHub login of the visitor (if known)
+	Login string `json:"login,omitempty"`
+
+	// Link is the recipient of the signed link the portal was opened with (if used)
+	Link string `json:"link,omitempty"`
+
+	// Field is the label of the field the interaction was made with (if any)
+	Field string `json:"field,omitempty"`
+
+	// Detail describes the outcome of the interaction
+	Detail string `json:"detail,omitempty"`
+}
+
+// NewLoggerRequest is the request object for creating a new instance of Logger
+type NewLoggerRequest struct {
+
+	// ActionPkg represents the githubactions package
+	ActionPkg *githubactions.Action
+
+	// Path is the path the audit log is appended to
+	Path string
+
+	// WebhookUrl is the URL every entry is also posted to, entries aren't forwarded when
+	// it is empty
+	WebhookUrl string
+
+	// HttpClient is the client entries are forwarded with, defaults to http.DefaultClient
+	HttpClient *http.Client
+}
+
+// NewLogger returns a new instance of Logger, opening (or creating) the audit log
+func NewLogger(r *NewLoggerRequest) (*Logger, error) {
+
+	var httpClient *http.Client = http.DefaultClient
+
+	if r.HttpClient != nil {
+		httpClient = r.HttpClient
+	}
+
+	err := os.MkdirAll(filepath.Dir(r.Path), 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	// entries are only ever appended, so existing entries can't be rewritten
+	file, err := os.OpenFile(r.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := &Logger{
+		actionPkg:  r.ActionPkg,
+		path:       r.Path,
+		file:       file,
+		webhookUrl: r.WebhookUrl,
+		httpClient: httpClient,
+	}
+
+	if logger.webhookUrl != "" {
+		logger.forwardQueue = make(chan []byte, forwardQueueSize)
+		logger.forwarded = make(chan struct{})
+		go logger.forward()
+	}
+
+	return logger, nil
+}
+
+// Logger appends every interaction with the portal to the audit log, forwarding them to
+// a webhook (if requested) in the background so visitors aren't kept waiting
+type Logger struct {
+
+	// actionPkg represents the githubactions package
+	actionPkg *githubactions.Action
+
+	// path is the path the audit log is appended to
+	path string
+
+	// mutex guards the audit log, keeping entries on their own lines
+	mutex sync.Mutex
+
+	// file is the audit log, nil once the logger is closed
+	file *os.File
+
+	// webhookUrl is the URL every entry is also posted to
+	webhookUrl string
+
+	// httpClient is the client entries are forwarded with
+	httpClient *http.Client
+
+	// forwardQueue holds the entries waiting to be forwarded to the webhook
+	forwardQueue chan []byte
+
+	// forwarded is closed once every queued entry has been forwarded
+	forwarded chan struct{}
+}
+
+// Path returns the path the audit log is appended to
+func (l *Logger) Path() string {
+	return l.path
+}
+
+// Record appends the entry to the audit log and queues it to be forwarded to the webhook,
+// timestamping it if it hasn't been
+func (l *Logger) Record(entry Entry) error {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	entry.Timestamp = entry.Timestamp.UTC()
+
+	encodedEntry, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.file == nil {
+		return os.ErrClosed
+	}
+
+	_, err = l.file.Write(append(encodedEntry, '\n'))
+	if err != nil {
+		return err
+	}
+
+	if l.forwardQueue != nil {
+		select {
+		case l.forwardQueue <- encodedEntry:
+		default:
+			l.actionPkg.Warningf("Unable to forward the %s audit log entry, too many entries are waiting to be forwarded", entry.Event)
+		}
+	}
+
+	return nil
+}
+
+// Close closes the audit log, waiting for the queued entries to be forwarded to the webhook
+func (l *Logger) Close() error {
+	l.mutex.Lock()
+
+	if l.file == nil {
+		l.mutex.Unlock()
+		return nil
+	}
+
+	err := l.file.Close()
+	l.file = nil
+
+	if l.forwardQueue != nil {
+		close(l.forwardQueue)
+	}
+
+	l.mutex.Unlock()
+
+	if l.forwarded != nil {
+		select {
+		case <-l.forwarded:
+		case <-time.After(closeTimeout):
+			l.actionPkg.Warningf("Timed out forwarding the audit log to the webhook, some entries may not have been forwarded")
+		}
+	}
+
+	return err
+}
+
+// forward posts the queued entries to the webhook, in the order they were recorded
+func (l *Logger) forward() {
+	defer close(l.forwarded)
+
+	for encodedEntry := range l.forwardQueue {
+		err := l.post(encodedEntry)
+		if err != nil {
+			l.actionPkg.Warningf("Unable to forward an audit log entry to the webhook: %v", err)
+		}
+	}
+}
+
+// post posts a single entry to the webhook
+func (l *Logger) post(encodedEntry []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), forwardTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.webhookUrl, bytes.NewReader(encodedEntry))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := l.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%w: %d", errors.ErrUnexpectedAuditLogWebhookStatusCode, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// NewRequestId returns a new, random request ID
+func NewRequestId() string {
+	randomBytes := make([]byte, 8)
+	rand.Read(randomBytes)
+
+	return hex.EncodeToString(randomBytes)
+}
+
+// WithRequestId returns a copy of the context holding the request's ID
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdContextKey{}, requestId)
+}
+
+// RequestIdFromContext returns the request's ID held by the context, or an empty string
+func RequestIdFromContext(ctx context.Context) string {
+	requestId, _ := ctx.Value(requestIdContextKey{}).(string)
+	return requestId
+}
@@ -0,0 +1,148 @@
+package audit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/audit"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger_Record(t *testing.T) {
+
+	timestamp := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                string
+		existingContent     string
+		webhookStatusCode   int
+		entries             []audit.Entry
+		expectedContent     string
+		expectedForwarded   []string
+		expectedWarningPart string
+	}{
+		{
+			name: "written to the audit log",
+			entries: []audit.Entry{
+				{Timestamp: timestamp, Event: audit.EventPageView, RequestId: "a1", Ip: "192.0.2.1", UserAgent: "curl/8.0"},
+				{Timestamp: timestamp, Event: audit.EventReset, RequestId: "b2", Ip: "192.0.2.1", UserAgent: "curl/8.0", Login: "octocat", Field: "notes", Detail: "1 of 1 file(s) removed"},
+			},
+			expectedContent: `{"timestamp":"2024-01-01T12:00:00Z","event":"page-view","request_id":"a1","ip":"192.0.2.1","user_agent":"curl/8.0"}
+{"timestamp":"2024-01-01T12:00:00Z","event":"reset","request_id":"b2","ip":"192.0.2.1","user_agent":"curl/8.0","login":"octocat","field":"notes","detail":"1 of 1 file(s) removed"}
+`,
+		},
+		{
+			name:            "appended to an existing audit log",
+			existingContent: "{\"event\":\"submit\"}\n",
+			entries: []audit.Entry{
+				{Timestamp: timestamp, Event: audit.EventCancel, RequestId: "c3", Ip: "192.0.2.2", UserAgent: "curl/8.0"},
+			},
+			expectedContent: `{"event":"submit"}
+{"timestamp":"2024-01-01T12:00:00Z","event":"cancel","request_id":"c3","ip":"192.0.2.2","user_agent":"curl/8.0"}
+`,
+		},
+		{
+			name:              "forwarded to the webhook",
+			webhookStatusCode: http.StatusNoContent,
+			entries: []audit.Entry{
+				{Timestamp: timestamp, Event: audit.EventUpload, RequestId: "d4", Ip: "192.0.2.3", UserAgent: "curl/8.0", Field: "notes", Detail: "1 of 1 file(s) uploaded: notes.md"},
+				{Timestamp: timestamp, Event: audit.EventSubmit, RequestId: "e5", Ip: "192.0.2.3", UserAgent: "curl/8.0"},
+			},
+			expectedContent: `{"timestamp":"2024-01-01T12:00:00Z","event":"upload","request_id":"d4","ip":"192.0.2.3","user_agent":"curl/8.0","field":"notes","detail":"1 of 1 file(s) uploaded: notes.md"}
+{"timestamp":"2024-01-01T12:00:00Z","event":"submit","request_id":"e5","ip":"192.0.2.3","user_agent":"curl/8.0"}
+`,
+			expectedForwarded: []string{"upload", "submit"},
+		},
+		{
+			name:              "webhook failures only warn",
+			webhookStatusCode: http.StatusInternalServerError,
+			entries: []audit.Entry{
+				{Timestamp: timestamp, Event: audit.EventSubmit, RequestId: "f6", Ip: "192.0.2.4", UserAgent: "curl/8.0"},
+			},
+			expectedContent: `{"timestamp":"2024-01-01T12:00:00Z","event":"submit","request_id":"f6","ip":"192.0.2.4","user_agent":"curl/8.0"}
+`,
+			expectedForwarded:   []string{"submit"},
+			expectedWarningPart: "UnexpectedAuditLogWebhookStatusCode: 500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+			if tt.existingContent != "" {
+				os.MkdirAll(filepath.Dir(path), 0700)
+				os.WriteFile(path, []byte(tt.existingContent), 0600)
+			}
+
+			var forwardedMutex sync.Mutex
+			forwarded := []string{}
+
+			var webhookUrl string
+			if tt.webhookStatusCode != 0 {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					body, _ := io.ReadAll(r.Body)
+
+					var entry audit.Entry
+					json.Unmarshal(body, &entry)
+
+					forwardedMutex.Lock()
+					forwarded = append(forwarded, entry.Event)
+					forwardedMutex.Unlock()
+
+					w.WriteHeader(tt.webhookStatusCode)
+				}))
+				defer server.Close()
+
+				webhookUrl = server.URL
+			}
+
+			var actionLog bytes.Buffer
+			logger, err := audit.NewLogger(&audit.NewLoggerRequest{
+				ActionPkg:  githubactions.New(githubactions.WithWriter(&actionLog)),
+				Path:       path,
+				WebhookUrl: webhookUrl,
+			})
+			assert.NoError(t, err)
+
+			for _, entry := range tt.entries {
+				assert.NoError(t, logger.Record(entry))
+			}
+
+			assert.NoError(t, logger.Close())
+
+			content, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedContent, string(content))
+
+			if tt.expectedForwarded != nil {
+				assert.Equal(t, tt.expectedForwarded, forwarded)
+			}
+
+			if tt.expectedWarningPart != "" {
+				assert.True(t, strings.Contains(actionLog.String(), tt.expectedWarningPart), actionLog.String())
+			}
+
+			// nothing can be recorded once the logger is closed
+			assert.ErrorIs(t, logger.Record(tt.entries[0]), os.ErrClosed)
+		})
+	}
+}
+
+func TestRequestIdFromContext(t *testing.T) {
+	requestId := audit.NewRequestId()
+
+	assert.Len(t, requestId, 16)
+	assert.Equal(t, requestId, audit.RequestIdFromContext(audit.WithRequestId(context.Background(), requestId)))
+	assert.Equal(t, "", audit.RequestIdFromContext(context.Background()))
+}
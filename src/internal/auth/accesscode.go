@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+)
+
+const (
+	// DefaultAccessCodeLength is the number of digits in a generated access code
+	DefaultAccessCodeLength = 6
+
+	// DefaultAccessCodeMaxAttempts is how many incorrect access codes a visitor can enter
+	// before they have to wait for the cooldown
+	DefaultAccessCodeMaxAttempts = 5
+
+	// DefaultAccessCodeCooldown is how long a visitor has to wait once they have entered
+	// too many incorrect access codes
+	DefaultAccessCodeCooldown = 15 * time.Minute
+
+	// accessCodeGlobalAttemptsFactor is how many times the per visitor maximum of incorrect
+	// access codes are accepted across every visitor before the portal is locked, so the
+	// code can't be brute forced from many addresses
+	accessCodeGlobalAttemptsFactor = 20
+)
+
+// accessCodeClient represents the incorrect access codes entered by a visitor
+type accessCodeClient struct {
+
+	// failedAttempts is how many incorrect access codes the visitor has entered since their
+	// last cooldown
+	failedAttempts int
+
+	// cooldownUntil is when the visitor can enter an access code again, zero unless they
+	// have entered too many incorrect ones
+	cooldownUntil time.Time
+}
+
+// GenerateAccessCode returns a random numeric access code
+func GenerateAccessCode() (string, error) {
+	var accessCode strings.Builder
+
+	for i := 0; i < DefaultAccessCodeLength; i++ {
+		digit, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		accessCode.WriteString(digit.String())
+	}
+
+	return accessCode.String(), nil
+}
+
+// Unlock checks the access code entered by the client (i.e. their IP address), returning the
+// (anonymous) identity to start a session for when it is correct. Once a client has entered
+// too many incorrect codes they have to wait for the cooldown, getting ErrAccessCodeCoolingDown,
+// so one visitor can't lock everyone else out. Incorrect codes are also counted across every
+// client, and once far more have been entered than any one client can, the portal stays
+// locked with ErrAccessCodeLockedOut, even for the correct code, so the code can't be guessed
+// by spreading attempts out
+func (a *Authenticator) Unlock(client, accessCode string) (*Identity, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.failedAccessCodeAttempts >= a.accessCodeGlobalMaxAttempts() {
+		return nil, errors.ErrAccessCodeLockedOut
+	}
+
+	now := a.now()
+	visitor := a.accessCodeClients[client]
+	if visitor != nil && now.Before(visitor.cooldownUntil) {
+		return nil, fmt.Errorf("%w: try again in %s", errors.ErrAccessCodeCoolingDown, visitor.cooldownUntil.Sub(now).Round(time.Second))
+	}
+
+	if a.accessCode != "" && subtle.ConstantTimeCompare([]byte(strings.TrimSpace(accessCode)), []byte(a.accessCode)) == 1 {
+		delete(a.accessCodeClients, client)
+		return &Identity{}, nil
+	}
+
+	// the client's attempts start over once their cooldown has passed
+	if visitor == nil || !visitor.cooldownUntil.IsZero() {
+		visitor = &accessCodeClient{}
+		a.accessCodeClients[client] = visitor
+	}
+
+	visitor.failedAttempts++
+	a.failedAccessCodeAttempts++
+
+	if a.failedAccessCodeAttempts >= a.accessCodeGlobalMaxAttempts() {
+		return nil, errors.ErrAccessCodeLockedOut
+	}
+
+	if visitor.failedAttempts >= a.accessCodeMaxAttempts {
+		visitor.cooldownUntil = now.Add(a.accessCodeCooldown)
+		return nil, fmt.Errorf("%w: try again in %s", errors.ErrAccessCodeCoolingDown, a.accessCodeCooldown)
+	}
+
+	return nil, fmt.Errorf("%w: %d attempt(s) remaining", errors.ErrInvalidAccessCode, a.accessCodeMaxAttempts-visitor.failedAttempts)
+}
+
+// RemainingAccessCodeAttempts returns how many more incorrect access codes the client can
+// enter before they have to wait for the cooldown, 0 while they are cooling down or once
+// the portal is locked
+func (a *Authenticator) RemainingAccessCodeAttempts(client string) int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.failedAccessCodeAttempts >= a.accessCodeGlobalMaxAttempts() {
+		return 0
+	}
+
+	visitor := a.accessCodeClients[client]
+	if visitor == nil {
+		return a.accessCodeMaxAttempts
+	}
+
+	if !visitor.cooldownUntil.IsZero() {
+		if a.now().Before(visitor.cooldownUntil) {
+			return 0
+		}
+		return a.accessCodeMaxAttempts
+	}
+
+	return max(a.accessCodeMaxAttempts-visitor.failedAttempts, 0)
+}
+
+// AccessCodeLocked returns whether the portal has been locked after too many incorrect
+// access codes were entered across every visitor
+func (a *Authenticator) AccessCodeLocked() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.failedAccessCodeAttempts >= a.accessCodeGlobalMaxAttempts()
+}
+
+// accessCodeGlobalMaxAttempts returns how many incorrect access codes are accepted across
+// every visitor before the portal is locked
+func (a *Authenticator) accessCodeGlobalMaxAttempts() int {
+	return a.accessCodeMaxAttempts * accessCodeGlobalAttemptsFactor
+}
@@ -0,0 +1,377 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ModeOff disables signing in to the portal, so anyone with the URL can use it
+	ModeOff = "off"
+
+	// ModeWeb signs visitors in with the GitHub OAuth web flow
+	ModeWeb = "web"
+
+	// ModeDevice signs visitors in with the GitHub OAuth device flow
+	ModeDevice = "device"
+
+	// ModeAccessCode unlocks the portal with an access code, which is only sent to the
+	// configured notifiers
+	ModeAccessCode = "access-code"
+
+	// DefaultOAuthUrl is the default base URL of GitHub's OAuth endpoints
+	DefaultOAuthUrl = "https://github.com"
+
+	// DefaultApiUrl is the default base URL of the GitHub API
+	DefaultApiUrl = "https://api.github.com"
+
+	// SessionCookieName is the name of the cookie holding the ID of the visitor's session
+	SessionCookieName = "iaip_session"
+
+	// LoginCookieName is the name of the cookie tying an in-progress sign in to the browser
+	// that started it
+	LoginCookieName = "iaip_login"
+
+	// loginExpiry is how long a visitor has to finish signing in
+	loginExpiry = 15 * time.Minute
+)
+
+// ValidModes is the list of supported ways of signing in to the portal
+var ValidModes = []string{ModeOff, ModeWeb, ModeDevice, ModeAccessCode}
+
+// identityContextKey is the key the signed in identity is stored under in the request context
+type identityContextKey struct{}
+
+// Identity represents a signed in visitor
+type Identity struct {
+
+	// Login is the GitHub login of the visitor, empty when the portal was unlocked with
+	// an access code
+	Login string
+
+	// token is the user's OAuth access token
+	token string
+}
+
+// NewAuthenticatorRequest is the request object for creating a new instance of Authenticator
+type NewAuthenticatorRequest struct {
+
+	// Mode is how visitors sign in (off, web, device or access-code)
+	Mode string
+
+	// ClientId is the client ID of the GitHub OAuth app
+	ClientId string
+
+	// ClientSecret is the client secret of the GitHub OAuth app (web flow only)
+	ClientSecret string
+
+	// OAuthUrl is the base URL of GitHub's OAuth endpoints, i.e. https://github.com
+	OAuthUrl string
+
+	// ApiUrl is the base URL of the GitHub API, i.e. https://api.github.com
+	ApiUrl string
+
+	// GithubToken is the token used to look up the repository permission of visitors
+	GithubToken string
+
+	// Repository is the repository permissions are checked against, i.e. owner/repo
+	Repository string
+
+	// AllowedUsers is the list of GitHub logins allowed to use the portal
+	AllowedUsers []string
+
+	// AllowedTeams is the list of teams (org/team-slug) whose members are allowed to use the portal
+	AllowedTeams []string
+
+	// MinimumPermission is the minimum repository permission needed to use the portal
+	MinimumPermission string
+
+	// AccessCode is the code that unlocks the portal (access-code mode only)
+	AccessCode string
+
+	// AccessCodeMaxAttempts is how many incorrect access codes a visitor can enter before
+	// they have to wait for the cooldown
+	AccessCodeMaxAttempts int
+
+	// AccessCodeCooldown is how long a visitor has to wait once they have entered too many
+	// incorrect access codes, defaults to DefaultAccessCodeCooldown
+	AccessCodeCooldown time.Duration
+
+	// Now returns the current time, defaults to time.Now
+	Now func() time.Time
+}
+
+// NewAuthenticator returns a new instance of Authenticator
+func NewAuthenticator(r *NewAuthenticatorRequest) *Authenticator {
+
+	var mode string = ModeOff
+	var oauthUrl string = DefaultOAuthUrl
+	var apiUrl string = DefaultApiUrl
+	var accessCodeMaxAttempts int = DefaultAccessCodeMaxAttempts
+	var accessCodeCooldown time.Duration = DefaultAccessCodeCooldown
+	var now func() time.Time = time.Now
+
+	if r.Mode != "" {
+		mode = r.Mode
+	}
+
+	if r.OAuthUrl != "" {
+		oauthUrl = strings.TrimSuffix(r.OAuthUrl, "/")
+	}
+
+	if r.ApiUrl != "" {
+		apiUrl = strings.TrimSuffix(r.ApiUrl, "/")
+	}
+
+	if r.AccessCodeMaxAttempts > 0 {
+		accessCodeMaxAttempts = r.AccessCodeMaxAttempts
+	}
+
+	if r.AccessCodeCooldown > 0 {
+		accessCodeCooldown = r.AccessCodeCooldown
+	}
+
+	if r.Now != nil {
+		now = r.Now
+	}
+
+	// the key session cookies are signed with only lives as long as the portal
+	cookieKey := make([]byte, 32)
+	rand.Read(cookieKey)
+
+	return &Authenticator{
+		mode:                  mode,
+		clientId:              r.ClientId,
+		clientSecret:          r.ClientSecret,
+		oauthUrl:              oauthUrl,
+		apiUrl:                apiUrl,
+		githubToken:           r.GithubToken,
+		repository:            r.Repository,
+		allowedUsers:          r.AllowedUsers,
+		allowedTeams:          r.AllowedTeams,
+		minimumPermission:     r.MinimumPermission,
+		accessCode:            r.AccessCode,
+		accessCodeMaxAttempts: accessCodeMaxAttempts,
+		accessCodeCooldown:    accessCodeCooldown,
+		accessCodeClients:     make(map[string]*accessCodeClient),
+		now:                   now,
+		cookieKey:             cookieKey,
+		httpClient:            &http.Client{Timeout: 30 * time.Second},
+		sessions:              make(map[string]*Identity),
+		logins:                make(map[string]*pendingLogin),
+	}
+}
+
+// Authenticator signs visitors in to the portal with their GitHub identity and checks
+// whether they are allowed to use it
+type Authenticator struct {
+
+	// mode is how visitors sign in (off, web, device or access-code)
+	mode string
+
+	// clientId is the client ID of the GitHub OAuth app
+	clientId string
+
+	// clientSecret is the client secret of the GitHub OAuth app
+	clientSecret string
+
+	// oauthUrl is the base URL of GitHub's OAuth endpoints
+	oauthUrl string
+
+	// apiUrl is the base URL of the GitHub API
+	apiUrl string
+
+	// githubToken is the token used to look up the repository permission of visitors
+	githubToken string
+
+	// repository is the repository permissions are checked against
+	repository string
+
+	// allowedUsers is the list of GitHub logins allowed to use the portal
+	allowedUsers []string
+
+	// allowedTeams is the list of teams whose members are allowed to use the portal
+	allowedTeams []string
+
+	// minimumPermission is the minimum repository permission needed to use the portal
+	minimumPermission string
+
+	// accessCode is the code that unlocks the portal
+	accessCode string
+
+	// accessCodeMaxAttempts is how many incorrect access codes a visitor can enter before
+	// they have to wait for the cooldown
+	accessCodeMaxAttempts int
+
+	// accessCodeCooldown is how long a visitor has to wait once they have entered too many
+	// incorrect access codes
+	accessCodeCooldown time.Duration
+
+	// accessCodeClients mapping of visitor (i.e. IP address) to the incorrect access codes
+	// they have entered
+	accessCodeClients map[string]*accessCodeClient
+
+	// failedAccessCodeAttempts is how many incorrect access codes have been entered across
+	// every visitor
+	failedAccessCodeAttempts int
+
+	// now returns the current time
+	now func() time.Time
+
+	// cookieKey is the key session cookies are signed with
+	cookieKey []byte
+
+	// httpClient is the client used to call GitHub
+	httpClient *http.Client
+
+	// mutex guards the sessions, in-progress sign ins and failed access code attempts
+	mutex sync.Mutex
+
+	// sessions mapping of session ID to the identity signed in with it
+	sessions map[string]*Identity
+
+	// logins mapping of login ID to the sign in in progress
+	logins map[string]*pendingLogin
+}
+
+// pendingLogin represents a sign in that has been started but not finished
+type pendingLogin struct {
+
+	// state is the OAuth state the web flow was started with
+	state string
+
+	// deviceCode is the device code the device flow was started with
+	deviceCode string
+
+	// interval is how often GitHub may be polled during the device flow
+	interval time.Duration
+
+	// nextPollAt is when GitHub may next be polled during the device flow
+	nextPollAt time.Time
+
+	// expiresAt is when the sign in expires
+	expiresAt time.Time
+}
+
+// Enabled returns whether visitors have to sign in to use the portal
+func (a *Authenticator) Enabled() bool {
+	return a.mode != ModeOff
+}
+
+// Mode returns how visitors sign in (off, web, device or access-code)
+func (a *Authenticator) Mode() string {
+	return a.mode
+}
+
+// NewSession starts a session for the identity, returning the signed value of its cookie
+func (a *Authenticator) NewSession(identity *Identity) (string, error) {
+	sessionId, err := randomId()
+	if err != nil {
+		return "", err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.sessions[sessionId] = identity
+
+	return sessionId + "." + a.signSessionId(sessionId), nil
+}
+
+// Identity returns the identity signed in with the request's session cookie, or nil
+// if the visitor isn't signed in or the cookie's signature doesn't match
+func (a *Authenticator) Identity(r *http.Request) *Identity {
+	cookie, err := r.Cookie(SessionCookieName)
+	if err != nil {
+		return nil
+	}
+
+	sessionId, signature, ok := strings.Cut(cookie.Value, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(a.signSessionId(sessionId))) {
+		return nil
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.sessions[sessionId]
+}
+
+// signSessionId returns the signature of the session ID
+func (a *Authenticator) signSessionId(sessionId string) string {
+	mac := hmac.New(sha256.New, a.cookieKey)
+	mac.Write([]byte(sessionId))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// WithIdentity returns a copy of the context holding the signed in identity
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityContextKey{}, identity)
+}
+
+// IdentityFromContext returns the signed in identity held by the context, or nil
+func IdentityFromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityContextKey{}).(*Identity)
+	return identity
+}
+
+// startLogin records a sign in that has been started, returning its ID
+func (a *Authenticator) startLogin(login *pendingLogin) (string, error) {
+	loginId, err := randomId()
+	if err != nil {
+		return "", err
+	}
+
+	login.expiresAt = time.Now().Add(loginExpiry)
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.logins[loginId] = login
+
+	return loginId, nil
+}
+
+// getLogin returns the (unexpired) sign in with the given ID, or nil
+func (a *Authenticator) getLogin(loginId string) *pendingLogin {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	login, ok := a.logins[loginId]
+	if !ok {
+		return nil
+	}
+
+	if time.Now().After(login.expiresAt) {
+		delete(a.logins, loginId)
+		return nil
+	}
+
+	return login
+}
+
+// finishLogin forgets the sign in with the given ID, so it can't be used again
+func (a *Authenticator) finishLogin(loginId string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	delete(a.logins, loginId)
+}
+
+// randomId returns a random, URL safe ID
+func randomId() (string, error) {
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(randomBytes), nil
+}
@@ -0,0 +1,406 @@
+package auth_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/auth"
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeGithub is a minimal implementation of GitHub's OAuth endpoints and the API endpoints
+// used to authorise visitors
+type fakeGithub struct {
+	mutex        sync.Mutex
+	devicePolls  int
+	pendingPolls int
+}
+
+func startFakeGithub(t *testing.T) (*fakeGithub, string) {
+	github := &fakeGithub{}
+
+	tokens := map[string]string{
+		"web-code":    "octocat-token",
+		"device-code": "hubot-token",
+	}
+	logins := map[string]string{
+		"octocat-token": "octocat",
+		"hubot-token":   "hubot",
+		"mona-token":    "monalisa",
+	}
+
+	router := http.NewServeMux()
+	router.HandleFunc("POST /login/device/code", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"device_code":      "device-code",
+			"user_code":        "ABCD-1234",
+			"verification_uri": "https://github.com/login/device",
+			"expires_in":       900,
+			"interval":         0,
+		})
+	})
+	router.HandleFunc("POST /login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		if deviceCode := r.Form.Get("device_code"); deviceCode != "" {
+			github.mutex.Lock()
+			github.devicePolls++
+			pending := github.devicePolls <= github.pendingPolls
+			github.mutex.Unlock()
+
+			if pending {
+				json.NewEncoder(w).Encode(map[string]string{"error": "authorization_pending"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"access_token": tokens[deviceCode]})
+			return
+		}
+
+		if r.Form.Get("client_secret") != "client-secret" {
+			json.NewEncoder(w).Encode(map[string]string{"error": "incorrect_client_credentials"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": tokens[r.Form.Get("code")]})
+	})
+	router.HandleFunc("GET /user", func(w http.ResponseWriter, r *http.Request) {
+		login, ok := logins[r.Header.Get("Authorization")[len("Bearer "):]]
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"login": login})
+	})
+	router.HandleFunc("GET /orgs/octo-org/teams/release-managers/memberships/{login}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("login") != "hubot" || r.Header.Get("Authorization") != "Bearer hubot-token" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"state": "active"})
+	})
+	router.HandleFunc("GET /repos/octo-org/octo-repo/collaborators/{login}/permission", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer github-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.PathValue("login") {
+		case "monalisa":
+			json.NewEncoder(w).Encode(map[string]string{"permission": "write", "role_name": "maintain"})
+		case "octocat":
+			json.NewEncoder(w).Encode(map[string]string{"permission": "write", "role_name": "write"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return github, server.URL
+}
+
+func newAuthenticator(serverUrl string, r auth.NewAuthenticatorRequest) *auth.Authenticator {
+	r.ClientId = "client-id"
+	r.ClientSecret = "client-secret"
+	r.OAuthUrl = serverUrl
+	r.ApiUrl = serverUrl
+	r.GithubToken = "github-token"
+	r.Repository = "octo-org/octo-repo"
+
+	return auth.NewAuthenticator(&r)
+}
+
+func TestAuthenticator_WebLogin(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		request       auth.NewAuthenticatorRequest
+		state         string
+		expectedLogin string
+		expectedError error
+	}{
+		{
+			name:          "allowed user",
+			request:       auth.NewAuthenticatorRequest{Mode: auth.ModeWeb, AllowedUsers: []string{"@OctoCat"}},
+			expectedLogin: "octocat",
+		},
+		{
+			name:          "not enough permission",
+			request:       auth.NewAuthenticatorRequest{Mode: auth.ModeWeb, AllowedUsers: []string{"hubot"}, MinimumPermission: "maintain"},
+			expectedLogin: "octocat",
+			expectedError: errors.ErrSubmitterNotAuthorised,
+		},
+		{
+			name:          "state mismatch",
+			request:       auth.NewAuthenticatorRequest{Mode: auth.ModeWeb, AllowedUsers: []string{"octocat"}},
+			state:         "forged",
+			expectedError: errors.ErrInvalidAuthState,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, serverUrl := startFakeGithub(t)
+			authenticator := newAuthenticator(serverUrl, tt.request)
+
+			loginId, authorizeUrl, err := authenticator.StartWebLogin("https://example.ngrok.app/auth/callback")
+			assert.NoError(t, err)
+
+			parsedAuthorizeUrl, err := url.Parse(authorizeUrl)
+			assert.NoError(t, err)
+			assert.Equal(t, "/login/oauth/authorize", parsedAuthorizeUrl.Path)
+			assert.Equal(t, "client-id", parsedAuthorizeUrl.Query().Get("client_id"))
+
+			state := tt.state
+			if state == "" {
+				state = parsedAuthorizeUrl.Query().Get("state")
+			}
+
+			identity, err := authenticator.FinishWebLogin(loginId, state, "web-code", "https://example.ngrok.app/auth/callback")
+			assert.ErrorIs(t, err, tt.expectedError)
+			if tt.expectedLogin != "" {
+				assert.Equal(t, tt.expectedLogin, identity.Login)
+			}
+
+			// the sign in can't be finished twice
+			_, err = authenticator.FinishWebLogin(loginId, state, "web-code", "https://example.ngrok.app/auth/callback")
+			assert.ErrorIs(t, err, errors.ErrInvalidAuthState)
+		})
+	}
+}
+
+func TestAuthenticator_DeviceLogin(t *testing.T) {
+
+	github, serverUrl := startFakeGithub(t)
+	github.pendingPolls = 1
+
+	authenticator := newAuthenticator(serverUrl, auth.NewAuthenticatorRequest{
+		Mode:         auth.ModeDevice,
+		AllowedTeams: []string{"octo-org/release-managers"},
+	})
+
+	loginId, deviceCode, err := authenticator.StartDeviceLogin()
+	assert.NoError(t, err)
+	assert.Equal(t, &auth.DeviceCode{UserCode: "ABCD-1234", VerificationUri: "https://github.com/login/device", Interval: 1}, deviceCode)
+
+	// GitHub isn't polled before the interval has passed
+	_, err = authenticator.PollDeviceLogin(loginId)
+	assert.ErrorIs(t, err, errors.ErrAuthorisationPending)
+	assert.Equal(t, 0, github.devicePolls)
+
+	time.Sleep(1100 * time.Millisecond)
+	_, err = authenticator.PollDeviceLogin(loginId)
+	assert.ErrorIs(t, err, errors.ErrAuthorisationPending)
+	assert.Equal(t, 1, github.devicePolls)
+
+	// hubot is a member of the allowed team
+	time.Sleep(1100 * time.Millisecond)
+	identity, err := authenticator.PollDeviceLogin(loginId)
+	assert.NoError(t, err)
+	assert.Equal(t, "hubot", identity.Login)
+
+	memberTeams, err := authenticator.MemberTeams(identity, []string{"octo-org/security", "octo-org/release-managers"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"octo-org/release-managers"}, memberTeams)
+
+	_, err = authenticator.PollDeviceLogin(loginId)
+	assert.ErrorIs(t, err, errors.ErrInvalidAuthState)
+}
+
+func TestAuthenticator_Authorise(t *testing.T) {
+
+	_, serverUrl := startFakeGithub(t)
+
+	tests := []struct {
+		name          string
+		request       auth.NewAuthenticatorRequest
+		login         string
+		expectedError error
+	}{
+		{
+			name:    "allowed user",
+			request: auth.NewAuthenticatorRequest{AllowedUsers: []string{"octocat"}},
+			login:   "octocat",
+		},
+		{
+			name:    "maintainer with minimum permission write",
+			request: auth.NewAuthenticatorRequest{MinimumPermission: "write"},
+			login:   "monalisa",
+		},
+		{
+			name:          "writer with minimum permission maintain",
+			request:       auth.NewAuthenticatorRequest{MinimumPermission: "maintain"},
+			login:         "octocat",
+			expectedError: errors.ErrSubmitterNotAuthorised,
+		},
+		{
+			name:          "not a collaborator",
+			request:       auth.NewAuthenticatorRequest{MinimumPermission: "read"},
+			login:         "hubot",
+			expectedError: errors.ErrSubmitterNotAuthorised,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator := newAuthenticator(serverUrl, tt.request)
+			err := authenticator.Authorise(&auth.Identity{Login: tt.login})
+			assert.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestAuthenticator_Unlock(t *testing.T) {
+
+	type attempt struct {
+		client        string
+		accessCode    string
+		wait          time.Duration
+		expectedError error
+	}
+
+	tests := []struct {
+		name     string
+		attempts []attempt
+	}{
+		{
+			name: "correct access code",
+			attempts: []attempt{
+				{client: "192.0.2.1", accessCode: " 482913 "},
+			},
+		},
+		{
+			name: "correct access code after an incorrect one",
+			attempts: []attempt{
+				{client: "192.0.2.1", accessCode: "000000", expectedError: errors.ErrInvalidAccessCode},
+				{client: "192.0.2.1", accessCode: "482913"},
+			},
+		},
+		{
+			name: "visitor cooling down after too many incorrect access codes",
+			attempts: []attempt{
+				{client: "192.0.2.1", accessCode: "000000", expectedError: errors.ErrInvalidAccessCode},
+				{client: "192.0.2.1", accessCode: "111111", expectedError: errors.ErrInvalidAccessCode},
+				{client: "192.0.2.1", accessCode: "222222", expectedError: errors.ErrAccessCodeCoolingDown},
+				{client: "192.0.2.1", accessCode: "482913", expectedError: errors.ErrAccessCodeCoolingDown},
+			},
+		},
+		{
+			name: "other visitors aren't locked out",
+			attempts: []attempt{
+				{client: "192.0.2.1", accessCode: "000000", expectedError: errors.ErrInvalidAccessCode},
+				{client: "192.0.2.1", accessCode: "111111", expectedError: errors.ErrInvalidAccessCode},
+				{client: "192.0.2.1", accessCode: "222222", expectedError: errors.ErrAccessCodeCoolingDown},
+				{client: "198.51.100.7", accessCode: "482913"},
+			},
+		},
+		{
+			name: "visitor can try again after the cooldown",
+			attempts: []attempt{
+				{client: "192.0.2.1", accessCode: "000000", expectedError: errors.ErrInvalidAccessCode},
+				{client: "192.0.2.1", accessCode: "111111", expectedError: errors.ErrInvalidAccessCode},
+				{client: "192.0.2.1", accessCode: "222222", expectedError: errors.ErrAccessCodeCoolingDown},
+				{client: "192.0.2.1", accessCode: "333333", wait: 14 * time.Minute, expectedError: errors.ErrAccessCodeCoolingDown},
+				{client: "192.0.2.1", accessCode: "444444", wait: time.Minute, expectedError: errors.ErrInvalidAccessCode},
+				{client: "192.0.2.1", accessCode: "482913"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+			authenticator := auth.NewAuthenticator(&auth.NewAuthenticatorRequest{
+				Mode:                  auth.ModeAccessCode,
+				AccessCode:            "482913",
+				AccessCodeMaxAttempts: 3,
+				Now:                   func() time.Time { return now },
+			})
+
+			for _, attempt := range tt.attempts {
+				now = now.Add(attempt.wait)
+
+				identity, err := authenticator.Unlock(attempt.client, attempt.accessCode)
+				assert.ErrorIs(t, err, attempt.expectedError)
+				if attempt.expectedError == nil {
+					assert.Equal(t, &auth.Identity{}, identity)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthenticator_Unlock_LockedAcrossVisitors(t *testing.T) {
+
+	authenticator := auth.NewAuthenticator(&auth.NewAuthenticatorRequest{
+		Mode:                  auth.ModeAccessCode,
+		AccessCode:            "482913",
+		AccessCodeMaxAttempts: 1,
+	})
+
+	// far more incorrect codes than any one visitor can enter lock the portal for everyone
+	for i := 0; i < 19; i++ {
+		_, err := authenticator.Unlock(fmt.Sprintf("192.0.2.%d", i), "000000")
+		assert.ErrorIs(t, err, errors.ErrAccessCodeCoolingDown)
+	}
+	assert.False(t, authenticator.AccessCodeLocked())
+	assert.Equal(t, 1, authenticator.RemainingAccessCodeAttempts("198.51.100.7"))
+
+	_, err := authenticator.Unlock("192.0.2.19", "000000")
+	assert.ErrorIs(t, err, errors.ErrAccessCodeLockedOut)
+	assert.True(t, authenticator.AccessCodeLocked())
+	assert.Equal(t, 0, authenticator.RemainingAccessCodeAttempts("198.51.100.7"))
+
+	_, err = authenticator.Unlock("198.51.100.7", "482913")
+	assert.ErrorIs(t, err, errors.ErrAccessCodeLockedOut)
+}
+
+func TestAuthenticator_Identity(t *testing.T) {
+
+	authenticator := auth.NewAuthenticator(&auth.NewAuthenticatorRequest{Mode: auth.ModeAccessCode, AccessCode: "482913"})
+
+	identity, err := authenticator.Unlock("192.0.2.1", "482913")
+	assert.NoError(t, err)
+
+	cookieValue, err := authenticator.NewSession(identity)
+	assert.NoError(t, err)
+
+	sessionId, _, _ := strings.Cut(cookieValue, ".")
+
+	tests := []struct {
+		name             string
+		cookieValue      string
+		expectedIdentity *auth.Identity
+	}{
+		{
+			name:             "signed session cookie",
+			cookieValue:      cookieValue,
+			expectedIdentity: identity,
+		},
+		{
+			name:        "unsigned session cookie",
+			cookieValue: sessionId,
+		},
+		{
+			name:        "tampered session cookie",
+			cookieValue: sessionId + ".forged",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.AddCookie(&http.Cookie{Name: auth.SessionCookieName, Value: tt.cookieValue})
+
+			assert.Equal(t, tt.expectedIdentity, authenticator.Identity(r))
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+)
+
+// ValidPermissions is the list of repository permissions, from least to most privileged
+var ValidPermissions = []string{"read", "triage", "write", "maintain", "admin"}
+
+// teamMembershipResponse represents the (relevant parts of the) response of the GitHub API
+// team membership endpoint
+type teamMembershipResponse struct {
+	State string `json:"state"`
+}
+
+// permissionResponse represents the (relevant parts of the) response of the GitHub API
+// collaborator permission endpoint
+type permissionResponse struct {
+	Permission string `json:"permission"`
+	RoleName   string `json:"role_name"`
+}
+
+// Authorise checks whether the identity is allowed to use the portal, which it is when
+// it is one of the allowed users, a member of one of the allowed teams or has at least
+// the minimum repository permission
+func (a *Authenticator) Authorise(identity *Identity) error {
+
+	for _, allowedUser := range a.allowedUsers {
+		if strings.EqualFold(strings.TrimPrefix(allowedUser, "@"), identity.Login) {
+			return nil
+		}
+	}
+
+	for _, allowedTeam := range a.allowedTeams {
+		isMember, err := a.isTeamMember(identity, allowedTeam)
+		if err != nil {
+			return err
+		}
+
+		if isMember {
+			return nil
+		}
+	}
+
+	if a.minimumPermission != "" {
+		hasPermission, err := a.hasMinimumPermission(identity)
+		if err != nil {
+			return err
+		}
+
+		if hasPermission {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s", errors.ErrSubmitterNotAuthorised, identity.Login)
+}
+
+// MemberTeams returns the teams (org/team-slug) of the list the identity is an active
+// member of
+func (a *Authenticator) MemberTeams(identity *Identity, teams []string) ([]string, error) {
+
+	memberTeams := []string{}
+	for _, team := range teams {
+		isMember, err := a.isTeamMember(identity, team)
+		if err != nil {
+			return nil, err
+		}
+
+		if isMember {
+			memberTeams = append(memberTeams, team)
+		}
+	}
+
+	return memberTeams, nil
+}
+
+// isTeamMember returns whether the identity is an active member of the team (org/team-slug).
+// It is checked with the visitor's own token, as the workflow's token usually can't read
+// team memberships
+func (a *Authenticator) isTeamMember(identity *Identity, team string) (bool, error) {
+	org, teamSlug, ok := strings.Cut(team, "/")
+	if !ok {
+		return false, fmt.Errorf("%w: %s", errors.ErrInvalidAuthAllowedTeamProvided, team)
+	}
+
+	var membership teamMembershipResponse
+	statusCode, err := a.getApi(fmt.Sprintf("/orgs/%s/teams/%s/memberships/%s", url.PathEscape(org), url.PathEscape(teamSlug), url.PathEscape(identity.Login)), identity.token, &membership)
+	if err != nil {
+		return false, err
+	}
+
+	return statusCode != http.StatusNotFound && membership.State == "active", nil
+}
+
+// hasMinimumPermission returns whether the identity has at least the minimum permission on
+// the repository, checked with the workflow's token
+func (a *Authenticator) hasMinimumPermission(identity *Identity) (bool, error) {
+	var permission permissionResponse
+	statusCode, err := a.getApi(fmt.Sprintf("/repos/%s/collaborators/%s/permission", a.repository, url.PathEscape(identity.Login)), a.githubToken, &permission)
+	if err != nil {
+		return false, err
+	}
+
+	if statusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	// role_name includes the triage and maintain roles, which permission folds into read and write
+	role := permission.RoleName
+	if !slices.Contains(ValidPermissions, role) {
+		role = permission.Permission
+	}
+
+	return slices.Index(ValidPermissions, role) >= slices.Index(ValidPermissions, a.minimumPermission), nil
+}
@@ -0,0 +1,291 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+)
+
+const (
+	// deviceGrantType is the grant type used to poll for the access token during the device flow
+	deviceGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+	// oauthErrorAuthorizationPending is returned while the visitor hasn't entered their code
+	oauthErrorAuthorizationPending = "authorization_pending"
+
+	// oauthErrorSlowDown is returned when GitHub is polled too often
+	oauthErrorSlowDown = "slow_down"
+)
+
+// DeviceCode holds what the visitor needs to finish signing in with the device flow
+type DeviceCode struct {
+
+	// UserCode is the code the visitor enters on GitHub
+	UserCode string
+
+	// VerificationUri is where the visitor enters the code
+	VerificationUri string
+
+	// Interval is how often (in seconds) the portal checks whether the code has been entered
+	Interval int
+}
+
+// accessTokenResponse represents the response of GitHub's access token endpoint
+type accessTokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+	Interval         int    `json:"interval"`
+}
+
+// deviceCodeResponse represents the response of GitHub's device code endpoint
+type deviceCodeResponse struct {
+	DeviceCode       string `json:"device_code"`
+	UserCode         string `json:"user_code"`
+	VerificationUri  string `json:"verification_uri"`
+	ExpiresIn        int    `json:"expires_in"`
+	Interval         int    `json:"interval"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// userResponse represents the (relevant parts of the) response of the GitHub API
+// authenticated user endpoint
+type userResponse struct {
+	Login string `json:"login"`
+}
+
+// StartWebLogin starts signing the visitor in with the web flow, returning the ID of the
+// sign in (to be kept in a cookie) and the GitHub URL to send the visitor to
+func (a *Authenticator) StartWebLogin(redirectUri string) (string, string, error) {
+	state, err := randomId()
+	if err != nil {
+		return "", "", err
+	}
+
+	loginId, err := a.startLogin(&pendingLogin{state: state})
+	if err != nil {
+		return "", "", err
+	}
+
+	query := url.Values{}
+	query.Set("client_id", a.clientId)
+	query.Set("redirect_uri", redirectUri)
+	query.Set("state", state)
+	query.Set("allow_signup", "false")
+	if scope := a.scope(); scope != "" {
+		query.Set("scope", scope)
+	}
+
+	return loginId, fmt.Sprintf("%s/login/oauth/authorize?%s", a.oauthUrl, query.Encode()), nil
+}
+
+// FinishWebLogin exchanges the code GitHub redirected the visitor back with for their
+// identity, and checks that they are allowed to use the portal. The identity is returned
+// with ErrSubmitterNotAuthorised when they aren't
+func (a *Authenticator) FinishWebLogin(loginId, state, code, redirectUri string) (*Identity, error) {
+	login := a.getLogin(loginId)
+	if login == nil || login.state == "" || subtle.ConstantTimeCompare([]byte(login.state), []byte(state)) != 1 {
+		return nil, errors.ErrInvalidAuthState
+	}
+	a.finishLogin(loginId)
+
+	var tokenResponse accessTokenResponse
+	err := a.postOAuth("/login/oauth/access_token", url.Values{
+		"client_id":     {a.clientId},
+		"client_secret": {a.clientSecret},
+		"code":          {code},
+		"redirect_uri":  {redirectUri},
+	}, &tokenResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenResponse.Error != "" || tokenResponse.AccessToken == "" {
+		return nil, fmt.Errorf("%w: %s %s", errors.ErrUnexpectedOAuthResponse, tokenResponse.Error, tokenResponse.ErrorDescription)
+	}
+
+	return a.signIn(tokenResponse.AccessToken)
+}
+
+// StartDeviceLogin starts signing the visitor in with the device flow, returning the ID of
+// the sign in (to be kept in a cookie) and the code the visitor has to enter on GitHub
+func (a *Authenticator) StartDeviceLogin() (string, *DeviceCode, error) {
+	values := url.Values{"client_id": {a.clientId}}
+	if scope := a.scope(); scope != "" {
+		values.Set("scope", scope)
+	}
+
+	var codeResponse deviceCodeResponse
+	err := a.postOAuth("/login/device/code", values, &codeResponse)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if codeResponse.Error != "" || codeResponse.DeviceCode == "" {
+		return "", nil, fmt.Errorf("%w: %s %s", errors.ErrUnexpectedOAuthResponse, codeResponse.Error, codeResponse.ErrorDescription)
+	}
+
+	interval := time.Duration(max(codeResponse.Interval, 1)) * time.Second
+
+	loginId, err := a.startLogin(&pendingLogin{
+		deviceCode: codeResponse.DeviceCode,
+		interval:   interval,
+		nextPollAt: time.Now().Add(interval),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return loginId, &DeviceCode{
+		UserCode:        codeResponse.UserCode,
+		VerificationUri: codeResponse.VerificationUri,
+		Interval:        int(interval.Seconds()),
+	}, nil
+}
+
+// PollDeviceLogin checks whether the visitor has entered their code on GitHub, returning
+// ErrAuthorisationPending until they have. GitHub is polled no more often than it allows,
+// however often this is called. Once the code is entered, the visitor's identity is returned
+// (with ErrSubmitterNotAuthorised when they aren't allowed to use the portal)
+func (a *Authenticator) PollDeviceLogin(loginId string) (*Identity, error) {
+	login := a.getLogin(loginId)
+	if login == nil || login.deviceCode == "" {
+		return nil, errors.ErrInvalidAuthState
+	}
+
+	a.mutex.Lock()
+	if time.Now().Before(login.nextPollAt) {
+		a.mutex.Unlock()
+		return nil, errors.ErrAuthorisationPending
+	}
+	login.nextPollAt = time.Now().Add(login.interval)
+	a.mutex.Unlock()
+
+	var tokenResponse accessTokenResponse
+	err := a.postOAuth("/login/oauth/access_token", url.Values{
+		"client_id":   {a.clientId},
+		"device_code": {login.deviceCode},
+		"grant_type":  {deviceGrantType},
+	}, &tokenResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	switch tokenResponse.Error {
+	case "":
+	case oauthErrorAuthorizationPending:
+		return nil, errors.ErrAuthorisationPending
+	case oauthErrorSlowDown:
+		a.mutex.Lock()
+		login.interval = time.Duration(max(tokenResponse.Interval, int(login.interval.Seconds())+5)) * time.Second
+		login.nextPollAt = time.Now().Add(login.interval)
+		a.mutex.Unlock()
+		return nil, errors.ErrAuthorisationPending
+	default:
+		a.finishLogin(loginId)
+		return nil, fmt.Errorf("%w: %s %s", errors.ErrUnexpectedOAuthResponse, tokenResponse.Error, tokenResponse.ErrorDescription)
+	}
+
+	a.finishLogin(loginId)
+
+	if tokenResponse.AccessToken == "" {
+		return nil, fmt.Errorf("%w: no access token returned", errors.ErrUnexpectedOAuthResponse)
+	}
+
+	return a.signIn(tokenResponse.AccessToken)
+}
+
+// signIn looks up who the access token belongs to and checks that they are allowed
+// to use the portal
+func (a *Authenticator) signIn(accessToken string) (*Identity, error) {
+	var user userResponse
+	statusCode, err := a.getApi("/user", accessToken, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode == http.StatusNotFound || user.Login == "" {
+		return nil, fmt.Errorf("%w: unable to look up the signed in user", errors.ErrUnexpectedGithubApiResponse)
+	}
+
+	identity := &Identity{
+		Login: user.Login,
+		token: accessToken,
+	}
+
+	err = a.Authorise(identity)
+	if err != nil {
+		return identity, err
+	}
+
+	return identity, nil
+}
+
+// scope returns the OAuth scope to request, reading org membership is only needed when
+// team membership is checked
+func (a *Authenticator) scope() string {
+	if len(a.allowedTeams) > 0 {
+		return "read:org"
+	}
+
+	return ""
+}
+
+// postOAuth posts the form to GitHub's OAuth endpoint, decoding the JSON response into target
+func (a *Authenticator) postOAuth(path string, values url.Values, target interface{}) error {
+	request, err := http.NewRequest(http.MethodPost, a.oauthUrl+path, strings.NewReader(values.Encode()))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Add("Accept", "application/json")
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := a.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("%w: POST %s responded with %d", errors.ErrUnexpectedOAuthResponse, path, response.StatusCode)
+	}
+
+	return json.NewDecoder(response.Body).Decode(target)
+}
+
+// getApi calls the GitHub API with the token, decoding the JSON response into target. The
+// status code is returned so that callers can handle not found responses
+func (a *Authenticator) getApi(path, token string, target interface{}) (int, error) {
+	request, err := http.NewRequest(http.MethodGet, a.apiUrl+path, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	request.Header.Add("Accept", "application/vnd.github+json")
+	request.Header.Add("Authorization", "Bearer "+token)
+	request.Header.Add("X-GitHub-Api-Version", "2022-11-28")
+
+	response, err := a.httpClient.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return response.StatusCode, nil
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("%w: GET %s responded with %d", errors.ErrUnexpectedGithubApiResponse, path, response.StatusCode)
+	}
+
+	return response.StatusCode, json.NewDecoder(response.Body).Decode(target)
+}
@@ -0,0 +1,255 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sethvargo/go-githubactions"
+)
+
+const (
+	// DirName is the name of the directory created within the cache root to hold
+	// the uploaded files of every run
+	DirName = ".__interactive-inputs-cache"
+
+	// ManifestFileSuffix is the suffix appended to an input field's cache directory
+	// to get the path its upload manifest is written to
+	ManifestFileSuffix = ".manifest.json"
+
+	// DefaultFileMode is the default permissions uploaded files are written with
+	DefaultFileMode os.FileMode = 0644
+
+	// CleanupEventCancel is the event triggered when the portal is cancelled
+	CleanupEventCancel = "cancel"
+
+	// CleanupEventTimeout is the event triggered when the portal times out
+	CleanupEventTimeout = "timeout"
+
+	// CleanupEventJobEnd is the event triggered when the job ends (via the post step)
+	CleanupEventJobEnd = "job-end"
+)
+
+var (
+	// ValidCleanupEvents is the list of events the cached uploads can be removed on
+	ValidCleanupEvents = []string{CleanupEventCancel, CleanupEventTimeout, CleanupEventJobEnd}
+)
+
+// NewCacheRequest is the request object for creating a new instance of Cache
+type NewCacheRequest struct {
+
+	// RootDir is the directory the cache directory is created in, i.e. RUNNER_TEMP
+	RootDir string
+
+	// FileMode is the permissions uploaded files are written with, directories are
+	// given the same permissions with execute added wherever read is set
+	FileMode os.FileMode
+
+	// StaleAfter is how long (in seconds) a directory left over from a previous run is
+	// kept before it is swept. A value of 0 disables the sweep
+	StaleAfter int
+
+	// CleanupOn is the list of events the cached uploads will be removed on
+	CleanupOn []string
+
+	// ActionPkg represents the githubactions package
+	ActionPkg *githubactions.Action
+}
+
+// New returns a new instance of Cache
+func New(r *NewCacheRequest) *Cache {
+
+	var fileMode os.FileMode = DefaultFileMode
+
+	if r.FileMode != 0 {
+		fileMode = r.FileMode
+	}
+
+	return &Cache{
+		baseDir:    filepath.Join(r.RootDir, DirName),
+		fileMode:   fileMode,
+		staleAfter: time.Duration(r.StaleAfter) * time.Second,
+		cleanupOn:  r.CleanupOn,
+		action:     r.ActionPkg,
+	}
+}
+
+// Cache manages the directories uploaded files are written to
+type Cache struct {
+
+	// baseDir is the directory every input field's cache directory is created in
+	baseDir string
+
+	// fileMode is the permissions uploaded files are written with
+	fileMode os.FileMode
+
+	// staleAfter is how long a directory left over from a previous run is kept
+	staleAfter time.Duration
+
+	// cleanupOn is the list of events the cached uploads will be removed on
+	cleanupOn []string
+
+	// action represents the githubactions package
+	action *githubactions.Action
+
+	// inputFieldDirsMutex guards the input field directories
+	inputFieldDirsMutex sync.Mutex
+
+	// inputFieldDirs is the list of directories created during this run
+	inputFieldDirs []string
+}
+
+// BaseDir returns the directory every input field's cache directory is created in
+func (c *Cache) BaseDir() string {
+	return c.baseDir
+}
+
+// FileMode returns the permissions uploaded files should be written with
+func (c *Cache) FileMode() os.FileMode {
+	return c.fileMode
+}
+
+// DirMode returns the permissions cache directories are created with, which is the
+// file mode with execute added wherever read is set
+func (c *Cache) DirMode() os.FileMode {
+	return c.fileMode | (c.fileMode&0444)>>2
+}
+
+// InputFieldDirs returns the directories created during this run
+func (c *Cache) InputFieldDirs() []string {
+	c.inputFieldDirsMutex.Lock()
+	defer c.inputFieldDirsMutex.Unlock()
+
+	return slices.Clone(c.inputFieldDirs)
+}
+
+// CleanupOn returns whether the cached uploads should be removed on the given event
+func (c *Cache) CleanupOn(event string) bool {
+	return slices.Contains(c.cleanupOn, event)
+}
+
+// CreateInputFieldDir creates the directory the uploaded files of the given input
+// field are written to, creating the base directory if it does not exist
+func (c *Cache) CreateInputFieldDir(label string) (string, error) {
+
+	err := os.MkdirAll(c.baseDir, c.DirMode())
+	if err != nil {
+		return "", err
+	}
+
+	inputFieldDir, err := os.MkdirTemp(c.baseDir, fmt.Sprintf("%s-%d", label, time.Now().UnixNano()))
+	if err != nil {
+		return "", err
+	}
+
+	// MkdirTemp always creates the directory with 0700
+	err = os.Chmod(inputFieldDir, c.DirMode())
+	if err != nil {
+		return "", err
+	}
+
+	c.inputFieldDirsMutex.Lock()
+	c.inputFieldDirs = append(c.inputFieldDirs, inputFieldDir)
+	c.inputFieldDirsMutex.Unlock()
+
+	return inputFieldDir, nil
+}
+
+// SweepStale removes the directories (and manifests) left over from previous runs that
+// have not been modified within the stale period, returning how many were removed
+func (c *Cache) SweepStale() int {
+
+	if c.staleAfter <= 0 {
+		return 0
+	}
+
+	entries, err := os.ReadDir(c.baseDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			c.action.Warningf("Unable to read cache directory for stale uploads: %v", err)
+		}
+		return 0
+	}
+
+	var totalRemoved int
+	staleBefore := time.Now().Add(-c.staleAfter)
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(staleBefore) {
+			continue
+		}
+
+		entryPath := filepath.Join(c.baseDir, entry.Name())
+		c.action.Debugf("Removing stale cache entry: %s (last modified %s)", entryPath, info.ModTime().UTC().Format(time.RFC3339))
+		if err := os.RemoveAll(entryPath); err != nil {
+			c.action.Warningf("Unable to remove stale cache entry %s: %v", entryPath, err)
+			continue
+		}
+
+		if entry.IsDir() {
+			totalRemoved++
+		}
+	}
+
+	return totalRemoved
+}
+
+// Cleanup removes the directories created during this run if the cached uploads
+// should be removed on the given event
+func (c *Cache) Cleanup(event string) {
+
+	if !c.CleanupOn(event) {
+		return
+	}
+
+	c.action.Infof("Removing cached uploads (%s)", event)
+	RemoveDirs(c.InputFieldDirs(), c.action)
+}
+
+// RemoveDirs removes the given input field cache directories along with their manifests,
+// and the base directory if it is left empty
+func RemoveDirs(inputFieldDirs []string, action *githubactions.Action) {
+
+	baseDirs := map[string]bool{}
+
+	for _, inputFieldDir := range inputFieldDirs {
+		if strings.TrimSpace(inputFieldDir) == "" {
+			continue
+		}
+
+		action.Debugf("Removing cache directory: %s", inputFieldDir)
+		if err := os.RemoveAll(inputFieldDir); err != nil {
+			action.Warningf("Unable to remove cache directory %s: %v", inputFieldDir, err)
+		}
+
+		if err := os.Remove(inputFieldDir + ManifestFileSuffix); err != nil && !os.IsNotExist(err) {
+			action.Warningf("Unable to remove upload manifest %s: %v", inputFieldDir+ManifestFileSuffix, err)
+		}
+
+		baseDirs[filepath.Dir(inputFieldDir)] = true
+	}
+
+	// only removes the base directory if it is empty, so other runs sharing
+	// the cache root are left alone
+	for baseDir := range baseDirs {
+		if filepath.Base(baseDir) == DirName {
+			os.Remove(baseDir)
+		}
+	}
+}
+
+// ParseFileMode parses an octal file mode, i.e. 0600
+func ParseFileMode(fileMode string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimSpace(fileMode), "0o"), 8, 32)
+	if err != nil || mode == 0 || mode > 0777 {
+		return 0, fmt.Errorf("invalid file mode: %s", fileMode)
+	}
+
+	return os.FileMode(mode), nil
+}
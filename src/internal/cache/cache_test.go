@@ -0,0 +1,136 @@
+package cache_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/cache"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_CreateInputFieldDirAndCleanup(t *testing.T) {
+
+	action := githubactions.New(githubactions.WithWriter(io.Discard))
+
+	tests := []struct {
+		name            string
+		fileMode        os.FileMode
+		cleanupOn       []string
+		cleanupEvent    string
+		expectedDirMode os.FileMode
+		expectedRemoved bool
+	}{
+		{
+			name:            "default permissions kept on cancel when cleanup not requested",
+			cleanupEvent:    cache.CleanupEventCancel,
+			expectedDirMode: 0755,
+			expectedRemoved: false,
+		},
+		{
+			name:            "owner only permissions removed on cancel",
+			fileMode:        0600,
+			cleanupOn:       []string{cache.CleanupEventCancel},
+			cleanupEvent:    cache.CleanupEventCancel,
+			expectedDirMode: 0700,
+			expectedRemoved: true,
+		},
+		{
+			name:            "kept on timeout when only cleaning up on job end",
+			fileMode:        0640,
+			cleanupOn:       []string{cache.CleanupEventJobEnd},
+			cleanupEvent:    cache.CleanupEventTimeout,
+			expectedDirMode: 0750,
+			expectedRemoved: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rootDir := t.TempDir()
+
+			uploadCache := cache.New(&cache.NewCacheRequest{
+				RootDir:   rootDir,
+				FileMode:  tt.fileMode,
+				CleanupOn: tt.cleanupOn,
+				ActionPkg: action,
+			})
+
+			inputFieldDir, err := uploadCache.CreateInputFieldDir("certificates")
+			assert.NoError(t, err)
+			assert.Equal(t, filepath.Join(rootDir, cache.DirName), filepath.Dir(inputFieldDir))
+
+			info, err := os.Stat(inputFieldDir)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedDirMode, info.Mode().Perm())
+
+			assert.NoError(t, os.WriteFile(inputFieldDir+cache.ManifestFileSuffix, []byte("{}"), uploadCache.FileMode()))
+
+			uploadCache.Cleanup(tt.cleanupEvent)
+
+			_, dirErr := os.Stat(inputFieldDir)
+			_, manifestErr := os.Stat(inputFieldDir + cache.ManifestFileSuffix)
+			assert.Equal(t, tt.expectedRemoved, os.IsNotExist(dirErr))
+			assert.Equal(t, tt.expectedRemoved, os.IsNotExist(manifestErr))
+		})
+	}
+}
+
+func TestCache_SweepStale(t *testing.T) {
+
+	action := githubactions.New(githubactions.WithWriter(io.Discard))
+	rootDir := t.TempDir()
+	baseDir := filepath.Join(rootDir, cache.DirName)
+
+	staleDir := filepath.Join(baseDir, "stale-123")
+	freshDir := filepath.Join(baseDir, "fresh-456")
+	assert.NoError(t, os.MkdirAll(staleDir, 0755))
+	assert.NoError(t, os.MkdirAll(freshDir, 0755))
+	assert.NoError(t, os.WriteFile(staleDir+cache.ManifestFileSuffix, []byte("{}"), 0644))
+
+	staleTime := time.Now().Add(-48 * time.Hour)
+	assert.NoError(t, os.Chtimes(staleDir, staleTime, staleTime))
+	assert.NoError(t, os.Chtimes(staleDir+cache.ManifestFileSuffix, staleTime, staleTime))
+
+	disabledCache := cache.New(&cache.NewCacheRequest{RootDir: rootDir, ActionPkg: action})
+	assert.Equal(t, 0, disabledCache.SweepStale())
+
+	uploadCache := cache.New(&cache.NewCacheRequest{RootDir: rootDir, StaleAfter: 86400, ActionPkg: action})
+	assert.Equal(t, 1, uploadCache.SweepStale())
+
+	assert.NoDirExists(t, staleDir)
+	assert.NoFileExists(t, staleDir+cache.ManifestFileSuffix)
+	assert.DirExists(t, freshDir)
+}
+
+func TestParseFileMode(t *testing.T) {
+
+	tests := []struct {
+		input        string
+		expectedMode os.FileMode
+		expectError  bool
+	}{
+		{input: "0600", expectedMode: 0600},
+		{input: "640", expectedMode: 0640},
+		{input: "0o644", expectedMode: 0644},
+		{input: "0999", expectError: true},
+		{input: "01777", expectError: true},
+		{input: "rw-r--r--", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			mode, err := cache.ParseFileMode(tt.input)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedMode, mode)
+		})
+	}
+}
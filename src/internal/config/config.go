@@ -1,11 +1,27 @@
 package config
 
 import (
+	"net/url"
+	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"text/template"
 
+	"github.com/boasihq/interactive-inputs/internal/approval"
+	"github.com/boasihq/interactive-inputs/internal/audit"
+	"github.com/boasihq/interactive-inputs/internal/auth"
+	"github.com/boasihq/interactive-inputs/internal/cache"
+	"github.com/boasihq/interactive-inputs/internal/encryption"
 	"github.com/boasihq/interactive-inputs/internal/errors"
 	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/boasihq/interactive-inputs/internal/output"
+	"github.com/boasihq/interactive-inputs/internal/receipt"
+	"github.com/boasihq/interactive-inputs/internal/secrets"
+	"github.com/boasihq/interactive-inputs/internal/session"
+	"github.com/boasihq/interactive-inputs/internal/subcommand"
+	"github.com/boasihq/interactive-inputs/internal/toolbox"
+	"github.com/boasihq/interactive-inputs/internal/tunnel"
 	githubactions "github.com/sethvargo/go-githubactions"
 )
 
@@ -39,6 +55,10 @@ type Config struct {
 	// when sending notifications
 	NotifierSlackBotName string
 
+	// NotifierCompletionMessage is the message (a text/template rendered with the session's
+	// metadata) sent with the enabled notifiers once the portal is closed
+	NotifierCompletionMessage string
+
 	// NotifierDiscordEnabled will be used to determine whether the Slack notifier
 	// is enabled or not
 	NotifierDiscordEnabled bool
@@ -58,10 +78,207 @@ type Config struct {
 	// GithubToken is the token that will be used to allow action to leverage the GitHub API
 	GithubToken string
 
+	// GithubApiUrl is the base URL of the GitHub API, defaults to the API URL of the
+	// action context when not provided
+	GithubApiUrl string
+
 	// NgrokAuthtoken is the authtoken that will be used to make Ngrok tunnels to host the
 	// interactive inputs portals
 	NgrokAuthtoken string
 
+	// NgrokDomain is the reserved (static) ngrok domain the portal is served on, a random
+	// one is used when not provided
+	NgrokDomain string
+
+	// NgrokRegion is the ngrok region the tunnel is started in, the closest one is used
+	// when not provided
+	NgrokRegion string
+
+	// NgrokMetadata is the opaque metadata attached to the ngrok endpoint
+	NgrokMetadata string
+
+	// NgrokOAuthProvider is the provider ngrok signs visitors in with before they reach
+	// the portal, i.e. github or google
+	NgrokOAuthProvider string
+
+	// NgrokOAuthAllowedEmails is the list of email addresses allowed through ngrok's OAuth
+	NgrokOAuthAllowedEmails []string
+
+	// NgrokOAuthAllowedDomains is the list of email domains allowed through ngrok's OAuth
+	NgrokOAuthAllowedDomains []string
+
+	// NgrokBasicAuth is the list of username:password credentials ngrok accepts before
+	// requests reach the portal
+	NgrokBasicAuth []string
+
+	// NgrokAllowCidrs is the list of CIDRs allowed to reach the portal through ngrok
+	NgrokAllowCidrs []string
+
+	// NgrokDenyCidrs is the list of CIDRs refused from reaching the portal through ngrok
+	NgrokDenyCidrs []string
+
+	// ScannerClamdAddress is the address of the clamd daemon uploaded files will be
+	// streamed to for malware scanning, i.e. unix:///var/run/clamav/clamd.ctl
+	ScannerClamdAddress string
+
+	// ScannerCommand is the command uploaded files will be scanned with, i.e. `clamscan {file}`
+	ScannerCommand string
+
+	// ScannerTimeout is how long (in seconds) the scan of an uploaded file can take
+	ScannerTimeout int
+
+	// UploadEncryptionRecipients is the list of age or SSH public keys uploaded files
+	// will be encrypted to before they are written to the cache directory
+	UploadEncryptionRecipients []string
+
+	// UploadCacheDir is the directory the cache directory holding uploaded files will be
+	// created in, defaults to the GITHUB_WORKSPACE when not provided
+	UploadCacheDir string
+
+	// UploadCacheFileMode is the permissions uploaded files will be written with
+	UploadCacheFileMode os.FileMode
+
+	// UploadCacheCleanup is the list of events (cancel, timeout, job-end) on which the
+	// cached uploads will be removed
+	UploadCacheCleanup []string
+
+	// UploadCacheStaleAfter is how long (in seconds) cache directories left over from previous
+	// runs are kept before they are swept on start up, 0 disables the sweep
+	UploadCacheStaleAfter int
+
+	// ExportEnv is whether the submitted values will also be exported to GITHUB_ENV
+	ExportEnv bool
+
+	// ExportEnvPrefix is the prefix added to the names of the exported environment variables
+	ExportEnvPrefix string
+
+	// ExportEnvNameStyle is how labels are mangled into environment variable names
+	// (upper-snake, lower-snake or preserve)
+	ExportEnvNameStyle string
+
+	// ExportFile is the path the submitted values will be written to
+	ExportFile string
+
+	// ExportFileFormat is the format (json, yaml or dotenv) the submitted values will be
+	// written to the export file in
+	ExportFileFormat string
+
+	// ExportSensitive is how sensitive fields are exported (exclude or mask)
+	ExportSensitive string
+
+	// OutputSpillThreshold is the size (in bytes) above which values are written to a file
+	// in the runner's temporary directory rather than set as an output, 0 disables it
+	OutputSpillThreshold int
+
+	// SecretDetection is how secrets accidentally pasted into fields that aren't sensitive
+	// are handled (off, reject or mask)
+	SecretDetection string
+
+	// RedactLogValues is whether submitted values are left out of the logs entirely
+	RedactLogValues bool
+
+	// ReceiptSigningKey is the key used to sign the submission receipt, no receipt is
+	// produced when it isn't provided
+	ReceiptSigningKey string
+
+	// ReceiptSigningAlgorithm is the algorithm (ed25519 or hmac-sha256) used to sign the
+	// submission receipt
+	ReceiptSigningAlgorithm string
+
+	// ReceiptFile is the path the signed submission receipt will be written to
+	ReceiptFile string
+
+	// ReceiptHashValues is whether only the checksums of submitted values are recorded in
+	// the submission receipt
+	ReceiptHashValues bool
+
+	// AuthMode is how visitors sign in to the portal, with their GitHub identity (web or
+	// device) or with an access code (access-code), or off
+	AuthMode string
+
+	// AuthClientId is the client ID of the GitHub OAuth app visitors sign in with
+	AuthClientId string
+
+	// AuthClientSecret is the client secret of the GitHub OAuth app (web flow only)
+	AuthClientSecret string
+
+	// AuthOAuthUrl is the base URL of GitHub's OAuth endpoints, defaults to the server the
+	// workflow runs on
+	AuthOAuthUrl string
+
+	// AuthAllowedUsers is the list of GitHub logins allowed to use the portal
+	AuthAllowedUsers []string
+
+	// AuthAllowedTeams is the list of teams (org/team-slug) whose members are allowed to use
+	// the portal
+	AuthAllowedTeams []string
+
+	// AuthMinimumPermission is the minimum repository permission needed to use the portal
+	AuthMinimumPermission string
+
+	// AccessCode is the code that unlocks the portal in access-code mode, one is generated
+	// when it isn't provided
+	AccessCode string
+
+	// AccessCodeMaxAttempts is how many incorrect access codes a visitor can enter before
+	// they have to wait for the cooldown
+	AccessCodeMaxAttempts int
+
+	// SignedLinks is whether the portal can only be opened with the signed, expiring links
+	// sent to the notifiers
+	SignedLinks bool
+
+	// SignedLinkExpiry is how long (in seconds) signed links can be opened for, the timeout
+	// is used when it is 0
+	SignedLinkExpiry int
+
+	// SignedLinkSingleUse is whether each signed link can only be opened once
+	SignedLinkSingleUse bool
+
+	// RateLimitPerIp is how many requests a single IP address can make to the portal per
+	// minute, unlimited when 0
+	RateLimitPerIp int
+
+	// RateLimitGlobal is how many requests the portal accepts per minute across every IP
+	// address, unlimited when 0
+	RateLimitGlobal int
+
+	// MaxConcurrentUploads is how many uploads the portal handles at the same time,
+	// unlimited when 0
+	MaxConcurrentUploads int
+
+	// MaxUploadSize is the largest (in MiB) upload request the portal accepts
+	MaxUploadSize int
+
+	// AuditLogFile is the path the audit log of the portal's interactions is appended to
+	AuditLogFile string
+
+	// AuditLogWebhook is the URL every audit log entry is also posted to
+	AuditLogWebhook string
+
+	// Approval is whether the portal asks for an approve or reject decision (with a comment)
+	// on top of its fields
+	Approval bool
+
+	// ApprovalPolicy is whether the step fails when the request is rejected (fail-on-reject)
+	// or always succeeds (always-succeed)
+	ApprovalPolicy string
+
+	// ApprovalQuorum is how many distinct (signed in) approvers have to approve the request
+	// before the portal closes
+	ApprovalQuorum int
+
+	// ApprovalRequiredUsers is the list of GitHub logins who have to approve the request
+	// before the portal closes
+	ApprovalRequiredUsers []string
+
+	// ApprovalRequiredTeams is the list of teams (org/team-slug) at least one member of which
+	// has to approve the request before the portal closes
+	ApprovalRequiredTeams []string
+
+	// JobSummary is whether a report of the portal session will be added to the job summary
+	JobSummary bool
+
 	Action *githubactions.Action
 }
 
@@ -117,12 +334,43 @@ func NewFromInputs(action *githubactions.Action) (*Config, error) {
 		action.Debugf("Title input provided: %s", titleInput)
 	}
 
-	// handle input for fetching interactive inputs portal fields if provided
+	// handle input for fetching approval settings
+	approvalInput := action.GetInput("approval") == "true"
+	approvalPolicyInput := toolbox.StringStandardisedToLower(action.GetInput("approval-policy"))
+	if approvalPolicyInput != "" && !slices.Contains(approval.ValidPolicies, approvalPolicyInput) {
+		action.Errorf("Invalid approval-policy '%s' provided. Valid policies are: %s", approvalPolicyInput, strings.Join(approval.ValidPolicies, ", "))
+		return nil, errors.ErrInvalidApprovalPolicyProvided
+	}
+
+	// handle input for fetching interactive inputs portal fields if provided, an approval
+	// portal doesn't need any fields on top of its buttons
 	interactiveInput := action.GetInput("interactive")
-	fields, err := fields.MarshalStringIntoValidFieldsStruct(interactiveInput, action)
-	if err != nil {
-		action.Errorf("Can't convert the 'fields' input to a valid fields config: %s", interactiveInput)
-		return nil, errors.ErrMalformedFieldsInputDataProvided
+	portalFields := &fields.Fields{Fields: []fields.Field{}}
+	if !approvalInput || strings.TrimSpace(interactiveInput) != "" {
+		portalFields, err = fields.MarshalStringIntoValidFieldsStruct(interactiveInput, action)
+		if err != nil {
+			action.Errorf("Can't convert the 'fields' input to a valid fields config: %s", interactiveInput)
+			return nil, errors.ErrMalformedFieldsInputDataProvided
+		}
+	}
+
+	// make sure no field sets an output the action sets itself, including the outputs
+	// holding the path of values too large to be set as outputs
+	reservedOutputNames := []string{output.AggregateKey, output.AggregateKey + output.SpillFileKeySuffix}
+	reservedOutputNames = append(reservedOutputNames, session.OutputKeys...)
+	reservedOutputNames = append(reservedOutputNames, receipt.OutputKeyReceipt, receipt.OutputKeyReceiptFile, audit.OutputKeyAuditLogFile, subcommand.OutputKeyCliPath)
+	if approvalInput {
+		for _, outputKey := range append(slices.Clone(approval.OutputKeys), approval.OutputKeyApprovers) {
+			reservedOutputNames = append(reservedOutputNames, outputKey, outputKey+output.SpillFileKeySuffix)
+		}
+	}
+	for _, field := range portalFields.Fields {
+		for _, outputName := range field.OutputNames() {
+			if slices.Contains(reservedOutputNames, outputName) {
+				action.Errorf("The '%s' output of field '%s' is reserved, please use a different label or output name. Reserved output names are: %s", outputName, field.Label, strings.Join(reservedOutputNames, ", "))
+				return nil, errors.ErrReservedOutputNameProvided
+			}
+		}
 	}
 
 	// handle input for fetching slack notifier
@@ -145,6 +393,16 @@ func NewFromInputs(action *githubactions.Action) (*Config, error) {
 		notifierSlackThreadTs = strings.TrimSpace(action.GetInput("notifier-slack-thread-ts"))
 	}
 
+	// handle input for fetching the notifier completion message
+	notifierCompletionMessage := action.GetInput("notifier-completion-message")
+	if notifierCompletionMessage != "" {
+		_, err = template.New("notifier-completion-message").Parse(notifierCompletionMessage)
+		if err != nil {
+			action.Errorf("Invalid notifier-completion-message template provided: %v", err)
+			return nil, errors.ErrInvalidNotifierCompletionMessageProvided
+		}
+	}
+
 	// handle input for fetching discord notifier
 	var notifierDiscordWebhook string = "secret-webhook"
 	var notifierDiscordUsernameOverride string
@@ -164,6 +422,349 @@ func NewFromInputs(action *githubactions.Action) (*Config, error) {
 		notifierDiscordThreadId = strings.TrimSpace(action.GetInput("notifier-discord-thread-id"))
 	}
 
+	// handle input for fetching upload scanner
+	scannerClamdAddressInput := strings.TrimSpace(action.GetInput("scanner-clamd-address"))
+	scannerCommandInput := strings.TrimSpace(action.GetInput("scanner-command"))
+	if scannerClamdAddressInput != "" && scannerCommandInput != "" {
+		action.Errorf("Both a scanner-clamd-address and a scanner-command were provided, please only provide one")
+		return nil, errors.ErrMultipleScannersProvided
+	}
+
+	var scannerTimeout int
+	scannerTimeoutInput := action.GetInput("scanner-timeout")
+	if scannerTimeoutInput != "" {
+		scannerTimeout, err = strconv.Atoi(scannerTimeoutInput)
+		if err != nil {
+			action.Errorf("Cannot convert the 'scanner-timeout' input (%s) to an int!", scannerTimeoutInput)
+			return nil, errors.ErrInvalidScannerTimeoutValueProvided
+		}
+	}
+
+	// handle input for fetching upload encryption recipients
+	var uploadEncryptionRecipients []string
+	for _, recipient := range strings.Split(action.GetInput("upload-encryption-recipients"), "\n") {
+		if recipient = strings.TrimSpace(recipient); recipient != "" {
+			uploadEncryptionRecipients = append(uploadEncryptionRecipients, recipient)
+		}
+	}
+
+	if _, err = encryption.ParseRecipients(uploadEncryptionRecipients); err != nil {
+		action.Errorf("Invalid upload-encryption-recipients provided: %v", err)
+		return nil, errors.ErrInvalidEncryptionRecipientProvided
+	}
+
+	// handle input for fetching upload cache settings
+	var uploadCacheFileMode os.FileMode
+	uploadCacheFileModeInput := action.GetInput("upload-cache-file-mode")
+	if uploadCacheFileModeInput != "" {
+		uploadCacheFileMode, err = cache.ParseFileMode(uploadCacheFileModeInput)
+		if err != nil {
+			action.Errorf("Cannot convert the 'upload-cache-file-mode' input (%s) to a file mode, i.e. 0600", uploadCacheFileModeInput)
+			return nil, errors.ErrInvalidUploadCacheFileModeProvided
+		}
+	}
+
+	var uploadCacheCleanup []string
+	for _, event := range strings.Split(action.GetInput("upload-cache-cleanup"), ",") {
+		if event = strings.ToLower(strings.TrimSpace(event)); event == "" {
+			continue
+		}
+
+		if !slices.Contains(cache.ValidCleanupEvents, event) {
+			action.Errorf("Invalid upload-cache-cleanup event '%s' provided. Valid events are: %s", event, strings.Join(cache.ValidCleanupEvents, ", "))
+			return nil, errors.ErrInvalidUploadCacheCleanupEventProvided
+		}
+
+		uploadCacheCleanup = append(uploadCacheCleanup, event)
+	}
+
+	var uploadCacheStaleAfter int
+	uploadCacheStaleAfterInput := action.GetInput("upload-cache-stale-after")
+	if uploadCacheStaleAfterInput != "" {
+		uploadCacheStaleAfter, err = strconv.Atoi(uploadCacheStaleAfterInput)
+		if err != nil {
+			action.Errorf("Cannot convert the 'upload-cache-stale-after' input (%s) to an int!", uploadCacheStaleAfterInput)
+			return nil, errors.ErrInvalidUploadCacheStaleAfterValueProvided
+		}
+	}
+
+	var outputSpillThreshold int
+	outputSpillThresholdInput := action.GetInput("output-spill-threshold")
+	if outputSpillThresholdInput != "" {
+		outputSpillThreshold, err = strconv.Atoi(outputSpillThresholdInput)
+		if err != nil || outputSpillThreshold < 0 {
+			action.Errorf("Cannot convert the 'output-spill-threshold' input (%s) to a positive int!", outputSpillThresholdInput)
+			return nil, errors.ErrInvalidOutputSpillThresholdProvided
+		}
+	}
+
+	// handle input for fetching secret detection settings
+	secretDetectionInput := toolbox.StringStandardisedToLower(action.GetInput("secret-detection"))
+	if secretDetectionInput != "" && !slices.Contains(secrets.ValidPolicies, secretDetectionInput) {
+		action.Errorf("Invalid secret-detection policy '%s' provided. Valid policies are: %s", secretDetectionInput, strings.Join(secrets.ValidPolicies, ", "))
+		return nil, errors.ErrInvalidSecretDetectionPolicyProvided
+	}
+
+	// handle input for fetching receipt settings
+	receiptSigningAlgorithmInput := toolbox.StringStandardisedToLower(action.GetInput("receipt-signing-algorithm"))
+	if receiptSigningAlgorithmInput != "" && !slices.Contains(receipt.ValidAlgorithms, receiptSigningAlgorithmInput) {
+		action.Errorf("Invalid receipt-signing-algorithm '%s' provided. Valid algorithms are: %s", receiptSigningAlgorithmInput, strings.Join(receipt.ValidAlgorithms, ", "))
+		return nil, errors.ErrInvalidReceiptSigningAlgorithmProvided
+	}
+
+	receiptSigningKeyInput := action.GetInput("receipt-signing-key")
+	if receiptSigningKeyInput != "" {
+		action.AddMask(receiptSigningKeyInput)
+
+		_, err = receipt.NewSigner(&receipt.NewSignerRequest{Algorithm: receiptSigningAlgorithmInput, Key: []byte(receiptSigningKeyInput)})
+		if err != nil {
+			action.Errorf("Invalid receipt-signing-key provided: %v", err)
+			return nil, errors.ErrInvalidReceiptSigningKeyProvided
+		}
+	}
+
+	// handle input for fetching auth settings
+	authModeInput := toolbox.StringStandardisedToLower(action.GetInput("auth-mode"))
+	if authModeInput != "" && !slices.Contains(auth.ValidModes, authModeInput) {
+		action.Errorf("Invalid auth-mode '%s' provided. Valid modes are: %s", authModeInput, strings.Join(auth.ValidModes, ", "))
+		return nil, errors.ErrInvalidAuthModeProvided
+	}
+
+	authClientIdInput := strings.TrimSpace(action.GetInput("auth-client-id"))
+	authClientSecretInput := strings.TrimSpace(action.GetInput("auth-client-secret"))
+	authAllowedUsers := splitList(action.GetInput("auth-allowed-users"))
+	authAllowedTeams := splitList(action.GetInput("auth-allowed-teams"))
+	authMinimumPermissionInput := toolbox.StringStandardisedToLower(action.GetInput("auth-minimum-permission"))
+
+	if authModeInput == auth.ModeWeb || authModeInput == auth.ModeDevice {
+		if authClientIdInput == "" || (authModeInput == auth.ModeWeb && authClientSecretInput == "") {
+			action.Errorf("The auth-client-id (and for the web flow the auth-client-secret) of a GitHub OAuth app must be provided when auth-mode is '%s'", authModeInput)
+			return nil, errors.ErrAuthClientNotProvided
+		}
+
+		if len(authAllowedUsers) == 0 && len(authAllowedTeams) == 0 && authMinimumPermissionInput == "" {
+			action.Errorf("At least one of auth-allowed-users, auth-allowed-teams or auth-minimum-permission must be provided when auth-mode is '%s'", authModeInput)
+			return nil, errors.ErrAuthRuleNotProvided
+		}
+	}
+
+	for _, team := range authAllowedTeams {
+		if org, teamSlug, ok := strings.Cut(team, "/"); !ok || org == "" || teamSlug == "" || strings.Contains(teamSlug, "/") {
+			action.Errorf("Invalid auth-allowed-teams team '%s' provided, teams must be in the org/team-slug format", team)
+			return nil, errors.ErrInvalidAuthAllowedTeamProvided
+		}
+	}
+
+	if authMinimumPermissionInput != "" && !slices.Contains(auth.ValidPermissions, authMinimumPermissionInput) {
+		action.Errorf("Invalid auth-minimum-permission '%s' provided. Valid permissions are: %s", authMinimumPermissionInput, strings.Join(auth.ValidPermissions, ", "))
+		return nil, errors.ErrInvalidAuthMinimumPermissionProvided
+	}
+
+	if authClientSecretInput != "" {
+		action.AddMask(authClientSecretInput)
+	}
+
+	accessCodeInput := strings.TrimSpace(action.GetInput("access-code"))
+	if accessCodeInput != "" {
+		action.AddMask(accessCodeInput)
+	}
+
+	// the generated access code can only reach visitors through the notifiers
+	if authModeInput == auth.ModeAccessCode && accessCodeInput == "" && !notifierSlackEnabledInput && !notifierDiscordEnabledInput {
+		action.Errorf("A notifier must be enabled to send the generated access code to, or an access-code must be provided, when auth-mode is '%s'", authModeInput)
+		return nil, errors.ErrAccessCodeNotDeliverable
+	}
+
+	var accessCodeMaxAttempts int
+	accessCodeMaxAttemptsInput := action.GetInput("access-code-max-attempts")
+	if accessCodeMaxAttemptsInput != "" {
+		accessCodeMaxAttempts, err = strconv.Atoi(accessCodeMaxAttemptsInput)
+		if err != nil || accessCodeMaxAttempts < 1 {
+			action.Errorf("Cannot convert the 'access-code-max-attempts' input (%s) to a positive int!", accessCodeMaxAttemptsInput)
+			return nil, errors.ErrInvalidAccessCodeMaxAttemptsProvided
+		}
+	}
+
+	// handle input for fetching approval quorum settings, approvers can only be told apart
+	// when they sign in with their GitHub identity
+	var approvalQuorum int
+	approvalQuorumInput := action.GetInput("approval-quorum")
+	if approvalQuorumInput != "" {
+		approvalQuorum, err = strconv.Atoi(approvalQuorumInput)
+		if err != nil || approvalQuorum < 1 {
+			action.Errorf("Cannot convert the 'approval-quorum' input (%s) to a positive int!", approvalQuorumInput)
+			return nil, errors.ErrInvalidApprovalQuorumProvided
+		}
+	}
+
+	approvalRequiredUsers := splitList(action.GetInput("approval-required-users"))
+	approvalRequiredTeams := splitList(action.GetInput("approval-required-teams"))
+	if approvalQuorum > 1 || len(approvalRequiredUsers) > 0 || len(approvalRequiredTeams) > 0 {
+		if !approvalInput {
+			action.Errorf("The approval input must be enabled to use approval-quorum, approval-required-users or approval-required-teams")
+			return nil, errors.ErrApprovalNotEnabled
+		}
+
+		if authModeInput != auth.ModeWeb && authModeInput != auth.ModeDevice {
+			action.Errorf("The auth-mode must be '%s' or '%s' to use approval-quorum, approval-required-users or approval-required-teams, so approvers can be told apart", auth.ModeWeb, auth.ModeDevice)
+			return nil, errors.ErrApprovalQuorumNeedsSignIn
+		}
+	}
+
+	for _, team := range approvalRequiredTeams {
+		if org, teamSlug, ok := strings.Cut(team, "/"); !ok || org == "" || teamSlug == "" || strings.Contains(teamSlug, "/") {
+			action.Errorf("Invalid approval-required-teams team '%s' provided, teams must be in the org/team-slug format", team)
+			return nil, errors.ErrInvalidApprovalRequiredTeamProvided
+		}
+	}
+
+	// handle input for fetching signed link settings
+	signedLinksInput := action.GetInput("signed-links") == "true"
+	if signedLinksInput && !notifierSlackEnabledInput && !notifierDiscordEnabledInput {
+		action.Errorf("A notifier must be enabled to send the signed links to when signed-links is enabled")
+		return nil, errors.ErrSignedLinkNotDeliverable
+	}
+
+	var signedLinkExpiry int
+	signedLinkExpiryInput := action.GetInput("signed-link-expiry")
+	if signedLinkExpiryInput != "" {
+		signedLinkExpiry, err = strconv.Atoi(signedLinkExpiryInput)
+		if err != nil || signedLinkExpiry < 1 {
+			action.Errorf("Cannot convert the 'signed-link-expiry' input (%s) to a positive int!", signedLinkExpiryInput)
+			return nil, errors.ErrInvalidSignedLinkExpiryProvided
+		}
+	}
+
+	// handle input for fetching rate limit settings
+	var rateLimitPerIp int
+	rateLimitPerIpInput := action.GetInput("rate-limit-per-ip")
+	if rateLimitPerIpInput != "" {
+		rateLimitPerIp, err = strconv.Atoi(rateLimitPerIpInput)
+		if err != nil || rateLimitPerIp < 0 {
+			action.Errorf("Cannot convert the 'rate-limit-per-ip' input (%s) to a positive int!", rateLimitPerIpInput)
+			return nil, errors.ErrInvalidRateLimitProvided
+		}
+	}
+
+	var rateLimitGlobal int
+	rateLimitGlobalInput := action.GetInput("rate-limit-global")
+	if rateLimitGlobalInput != "" {
+		rateLimitGlobal, err = strconv.Atoi(rateLimitGlobalInput)
+		if err != nil || rateLimitGlobal < 0 {
+			action.Errorf("Cannot convert the 'rate-limit-global' input (%s) to a positive int!", rateLimitGlobalInput)
+			return nil, errors.ErrInvalidRateLimitProvided
+		}
+	}
+
+	var maxConcurrentUploads int
+	maxConcurrentUploadsInput := action.GetInput("max-concurrent-uploads")
+	if maxConcurrentUploadsInput != "" {
+		maxConcurrentUploads, err = strconv.Atoi(maxConcurrentUploadsInput)
+		if err != nil || maxConcurrentUploads < 0 {
+			action.Errorf("Cannot convert the 'max-concurrent-uploads' input (%s) to a positive int!", maxConcurrentUploadsInput)
+			return nil, errors.ErrInvalidMaxConcurrentUploadsProvided
+		}
+	}
+
+	var maxUploadSize int
+	maxUploadSizeInput := action.GetInput("max-upload-size")
+	if maxUploadSizeInput != "" {
+		maxUploadSize, err = strconv.Atoi(maxUploadSizeInput)
+		if err != nil || maxUploadSize < 1 {
+			action.Errorf("Cannot convert the 'max-upload-size' input (%s) to a positive int!", maxUploadSizeInput)
+			return nil, errors.ErrInvalidMaxUploadSizeProvided
+		}
+	}
+
+	// handle input for fetching audit log settings
+	auditLogWebhookInput := strings.TrimSpace(action.GetInput("audit-log-webhook"))
+	if auditLogWebhookInput != "" {
+		parsedAuditLogWebhook, err := url.Parse(auditLogWebhookInput)
+		if err != nil || (parsedAuditLogWebhook.Scheme != "http" && parsedAuditLogWebhook.Scheme != "https") || parsedAuditLogWebhook.Host == "" {
+			action.Errorf("Invalid audit-log-webhook provided, it must be an http(s) URL")
+			return nil, errors.ErrInvalidAuditLogWebhookProvided
+		}
+
+		// the webhook's URL often holds its secret
+		action.AddMask(auditLogWebhookInput)
+	}
+
+	// handle input for fetching ngrok endpoint settings
+	ngrokDomainInput := toolbox.StringStandardisedToLower(action.GetInput("ngrok-domain"))
+	if strings.ContainsAny(ngrokDomainInput, ":/") {
+		action.Errorf("Invalid ngrok-domain '%s' provided, only the hostname (i.e. portal.example.com) is needed", ngrokDomainInput)
+		return nil, errors.ErrInvalidNgrokDomainProvided
+	}
+
+	ngrokRegionInput := toolbox.StringStandardisedToLower(action.GetInput("ngrok-region"))
+	if ngrokRegionInput != "" && !slices.Contains(tunnel.ValidRegions, ngrokRegionInput) {
+		action.Errorf("Invalid ngrok-region '%s' provided. Valid regions are: %s", ngrokRegionInput, strings.Join(tunnel.ValidRegions, ", "))
+		return nil, errors.ErrInvalidNgrokRegionProvided
+	}
+
+	ngrokOAuthProviderInput := toolbox.StringStandardisedToLower(action.GetInput("ngrok-oauth-provider"))
+	if ngrokOAuthProviderInput != "" && !slices.Contains(tunnel.ValidOAuthProviders, ngrokOAuthProviderInput) {
+		action.Errorf("Invalid ngrok-oauth-provider '%s' provided. Valid providers are: %s", ngrokOAuthProviderInput, strings.Join(tunnel.ValidOAuthProviders, ", "))
+		return nil, errors.ErrInvalidNgrokOAuthProviderProvided
+	}
+
+	ngrokOAuthAllowedEmails := splitList(action.GetInput("ngrok-oauth-allowed-emails"))
+	ngrokOAuthAllowedDomains := splitList(action.GetInput("ngrok-oauth-allowed-domains"))
+	if ngrokOAuthProviderInput == "" && (len(ngrokOAuthAllowedEmails) > 0 || len(ngrokOAuthAllowedDomains) > 0) {
+		action.Errorf("The ngrok-oauth-provider must be provided when ngrok-oauth-allowed-emails or ngrok-oauth-allowed-domains are")
+		return nil, errors.ErrNgrokOAuthProviderNotProvided
+	}
+
+	ngrokBasicAuth := splitList(action.GetInput("ngrok-basic-auth"))
+	for _, credential := range ngrokBasicAuth {
+		_, password, err := tunnel.ParseBasicAuth(credential)
+		if err != nil {
+			action.Errorf("Invalid ngrok-basic-auth provided: %v", err)
+			return nil, errors.ErrInvalidNgrokBasicAuthProvided
+		}
+		action.AddMask(password)
+	}
+
+	ngrokAllowCidrs := splitList(action.GetInput("ngrok-allow-cidrs"))
+	ngrokDenyCidrs := splitList(action.GetInput("ngrok-deny-cidrs"))
+	for _, cidr := range append(slices.Clone(ngrokAllowCidrs), ngrokDenyCidrs...) {
+		if err := tunnel.ValidateCidr(cidr); err != nil {
+			action.Errorf("Invalid ngrok CIDR '%s' provided, CIDRs must be in the 10.0.0.0/8 format", cidr)
+			return nil, errors.ErrInvalidNgrokCidrProvided
+		}
+	}
+
+	// handle input for fetching export settings
+	exportEnvNameStyleInput := toolbox.StringStandardisedToLower(action.GetInput("export-env-name-style"))
+	if exportEnvNameStyleInput != "" && !slices.Contains(output.ValidEnvNameStyles, exportEnvNameStyleInput) {
+		action.Errorf("Invalid export-env-name-style '%s' provided. Valid styles are: %s", exportEnvNameStyleInput, strings.Join(output.ValidEnvNameStyles, ", "))
+		return nil, errors.ErrInvalidExportEnvNameStyleProvided
+	}
+
+	exportFileFormatInput := toolbox.StringStandardisedToLower(action.GetInput("export-file-format"))
+	if exportFileFormatInput != "" && !slices.Contains(output.ValidFileFormats, exportFileFormatInput) {
+		action.Errorf("Invalid export-file-format '%s' provided. Valid formats are: %s", exportFileFormatInput, strings.Join(output.ValidFileFormats, ", "))
+		return nil, errors.ErrInvalidExportFileFormatProvided
+	}
+
+	exportSensitiveInput := toolbox.StringStandardisedToLower(action.GetInput("export-sensitive"))
+	if exportSensitiveInput != "" && !slices.Contains(output.ValidSensitivePolicies, exportSensitiveInput) {
+		action.Errorf("Invalid export-sensitive policy '%s' provided. Valid policies are: %s", exportSensitiveInput, strings.Join(output.ValidSensitivePolicies, ", "))
+		return nil, errors.ErrInvalidExportSensitivePolicyProvided
+	}
+
+	// output names that only differ in characters the env name style replaces (i.e. deploy-env
+	// and deploy_env) would overwrite each other in GITHUB_ENV or a dotenv export file
+	exportFileInput := action.GetInput("export-file")
+	exportsEnvNames := exportFileInput != "" && (exportFileFormatInput == output.FileFormatDotenv || exportFileFormatInput == "" && output.DetectFileFormat(exportFileInput) == output.FileFormatDotenv)
+	if action.GetInput("export-env") == "true" || exportsEnvNames {
+		first, second, envName := output.EnvNameCollision(portalFields.Fields, action.GetInput("export-env-prefix"), exportEnvNameStyleInput)
+		if envName != "" {
+			action.Errorf("Export env name collision detected: fields '%s' and '%s' are both exported as '%s', set a custom env name for one of them or change the export-env-name-style", first, second, envName)
+			return nil, errors.ErrOutputNameCollisionDetected
+		}
+	}
+
 	// handle masking of sensitive data
 	action.AddMask(notifierSlackToken)
 	action.AddMask(notifierDiscordWebhook)
@@ -172,11 +773,22 @@ func NewFromInputs(action *githubactions.Action) (*Config, error) {
 
 	c := Config{
 		Title:   titleInput,
-		Fields:  fields,
+		Fields:  portalFields,
 		Timeout: timeout,
 
 		NgrokAuthtoken: ngrokAuthtokenInput,
-		GithubToken:    githubTokenInput,
+
+		NgrokDomain:              ngrokDomainInput,
+		NgrokRegion:              ngrokRegionInput,
+		NgrokMetadata:            action.GetInput("ngrok-metadata"),
+		NgrokOAuthProvider:       ngrokOAuthProviderInput,
+		NgrokOAuthAllowedEmails:  ngrokOAuthAllowedEmails,
+		NgrokOAuthAllowedDomains: ngrokOAuthAllowedDomains,
+		NgrokBasicAuth:           ngrokBasicAuth,
+		NgrokAllowCidrs:          ngrokAllowCidrs,
+		NgrokDenyCidrs:           ngrokDenyCidrs,
+		GithubToken:              githubTokenInput,
+		GithubApiUrl:             strings.TrimSpace(action.GetInput("github-api-url")),
 
 		NotifierSlackEnabled:  notifierSlackEnabledInput,
 		NotifierSlackToken:    notifierSlackToken,
@@ -184,12 +796,89 @@ func NewFromInputs(action *githubactions.Action) (*Config, error) {
 		NotifierSlackBotName:  notifierSlackBotName,
 		NotifierSlackThreadTs: notifierSlackThreadTs,
 
+		NotifierCompletionMessage: notifierCompletionMessage,
+
 		NotifierDiscordEnabled:          notifierDiscordEnabledInput,
 		NotifierDiscordWebhook:          notifierDiscordWebhook,
 		NotifierDiscordUsernameOverride: notifierDiscordUsernameOverride,
 		NotifierDiscordThreadId:         notifierDiscordThreadId,
 
+		ScannerClamdAddress: scannerClamdAddressInput,
+		ScannerCommand:      scannerCommandInput,
+		ScannerTimeout:      scannerTimeout,
+
+		UploadEncryptionRecipients: uploadEncryptionRecipients,
+
+		UploadCacheDir:        strings.TrimSpace(action.GetInput("upload-cache-dir")),
+		UploadCacheFileMode:   uploadCacheFileMode,
+		UploadCacheCleanup:    uploadCacheCleanup,
+		UploadCacheStaleAfter: uploadCacheStaleAfter,
+
+		ExportEnv:          action.GetInput("export-env") == "true",
+		ExportEnvPrefix:    action.GetInput("export-env-prefix"),
+		ExportEnvNameStyle: exportEnvNameStyleInput,
+		ExportFile:         exportFileInput,
+		ExportFileFormat:   exportFileFormatInput,
+		ExportSensitive:    exportSensitiveInput,
+
+		OutputSpillThreshold: outputSpillThreshold,
+		SecretDetection:      secretDetectionInput,
+		RedactLogValues:      action.GetInput("redact-log-values") == "true",
+		JobSummary:           action.GetInput("job-summary") == "true",
+
+		ReceiptSigningKey:       receiptSigningKeyInput,
+		ReceiptSigningAlgorithm: receiptSigningAlgorithmInput,
+		ReceiptFile:             strings.TrimSpace(action.GetInput("receipt-file")),
+		ReceiptHashValues:       action.GetInput("receipt-hash-values") == "true",
+
+		AuthMode:              authModeInput,
+		AuthClientId:          authClientIdInput,
+		AuthClientSecret:      authClientSecretInput,
+		AuthOAuthUrl:          strings.TrimSpace(action.GetInput("auth-oauth-url")),
+		AuthAllowedUsers:      authAllowedUsers,
+		AuthAllowedTeams:      authAllowedTeams,
+		AuthMinimumPermission: authMinimumPermissionInput,
+
+		AccessCode:            accessCodeInput,
+		AccessCodeMaxAttempts: accessCodeMaxAttempts,
+
+		SignedLinks:         signedLinksInput,
+		SignedLinkExpiry:    signedLinkExpiry,
+		SignedLinkSingleUse: action.GetInput("signed-link-single-use") == "true",
+
+		RateLimitPerIp:       rateLimitPerIp,
+		RateLimitGlobal:      rateLimitGlobal,
+		MaxConcurrentUploads: maxConcurrentUploads,
+		MaxUploadSize:        maxUploadSize,
+
+		AuditLogFile:    strings.TrimSpace(action.GetInput("audit-log-file")),
+		AuditLogWebhook: auditLogWebhookInput,
+
+		Approval:              approvalInput,
+		ApprovalPolicy:        approvalPolicyInput,
+		ApprovalQuorum:        approvalQuorum,
+		ApprovalRequiredUsers: approvalRequiredUsers,
+		ApprovalRequiredTeams: approvalRequiredTeams,
+
 		Action: action,
 	}
 	return &c, nil
 }
+
+// ApprovalQuorumEnabled returns whether the portal collects the decisions of several
+// approvers (or of specific ones) before it closes
+func (c *Config) ApprovalQuorumEnabled() bool {
+	return c.Approval && (c.ApprovalQuorum > 1 || len(c.ApprovalRequiredUsers) > 0 || len(c.ApprovalRequiredTeams) > 0)
+}
+
+// splitList returns the non-empty items of a comma or newline separated list
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
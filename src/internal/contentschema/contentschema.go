@@ -0,0 +1,388 @@
+package contentschema
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/boasihq/interactive-inputs/internal/toolbox"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// DefaultPreviewRows is the number of rows shown in the preview table when the
+	// content schema does not specify how many should be shown
+	DefaultPreviewRows int = 5
+
+	// maxValidationErrors is the maximum number of validation errors collected for a
+	// single file, so that a completely wrong file doesn't flood the portal
+	maxValidationErrors int = 50
+)
+
+// Result represents the outcome of validating an uploaded file against a content schema
+type Result struct {
+
+	// Format is the format the file was validated as
+	Format string `json:"format"`
+
+	// Errors is the list of row- or pointer-level validation errors found in the file
+	Errors []ValidationError `json:"errors,omitempty"`
+
+	// Preview is a table of the first rows of the file
+	Preview *Preview `json:"preview,omitempty"`
+}
+
+// Valid returns whether the file satisfied the content schema
+func (r *Result) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// ValidationError represents a single violation of the content schema
+type ValidationError struct {
+
+	// Location is where in the file the violation was found, i.e. `line 3, column "email"`
+	// for CSV files or a JSON pointer such as `/servers/0/port` for JSON and YAML files
+	Location string `json:"location"`
+
+	// Message is the human-friendly reason the value was rejected
+	Message string `json:"message"`
+}
+
+// Preview represents a table of the first rows of an uploaded file
+type Preview struct {
+
+	// Columns is the list of column headers
+	Columns []string `json:"columns"`
+
+	// Rows is the list of rows, each holding a value per column
+	Rows [][]string `json:"rows"`
+
+	// TotalRows is the total number of rows found in the file
+	TotalRows int `json:"total_rows"`
+}
+
+// Validate checks the content of the uploaded file against the provided content schema,
+// returning any validation errors along with a preview of the file's first rows.
+func Validate(schema *fields.ContentSchema, fileName string, content []byte) *Result {
+
+	result := &Result{
+		Format: detectFormat(schema.Format, fileName),
+		Errors: []ValidationError{},
+	}
+
+	previewRows := schema.PreviewRows
+	if previewRows == 0 {
+		previewRows = DefaultPreviewRows
+	}
+
+	switch result.Format {
+	case "csv":
+		validateCsv(schema.Columns, content, previewRows, result)
+	case "json", "yaml":
+		var document interface{}
+		var err error
+
+		if result.Format == "json" {
+			err = json.Unmarshal(content, &document)
+		} else {
+			err = yaml.Unmarshal(content, &document)
+		}
+		if err != nil {
+			result.addError("/", fmt.Sprintf("file is not valid %s: %v", toolbox.StringStandardisedToUpper(result.Format), err))
+			return result
+		}
+
+		document = normalise(document)
+		if schema.JsonSchema != nil {
+			validateJsonValue(normalise(schema.JsonSchema).(map[string]interface{}), document, "", result)
+		}
+		result.Preview = buildDocumentPreview(document, previewRows)
+	default:
+		result.addError("", fmt.Sprintf("unable to determine the format of '%s', expected one of: %s", fileName, strings.Join(fields.ValidContentSchemaFormats, ", ")))
+	}
+
+	return result
+}
+
+// detectFormat returns the configured format, falling back on the format implied by
+// the file's extension
+func detectFormat(configuredFormat, fileName string) string {
+	if configuredFormat != "" {
+		return configuredFormat
+	}
+
+	switch toolbox.StringStandardisedToLower(filepath.Ext(fileName)) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".csv":
+		return "csv"
+	}
+
+	return ""
+}
+
+// addError appends a validation error to the result, unless the maximum number
+// of validation errors has already been reached
+func (r *Result) addError(location, message string) {
+	if len(r.Errors) >= maxValidationErrors {
+		return
+	}
+
+	if location == "" {
+		location = "/"
+	}
+
+	r.Errors = append(r.Errors, ValidationError{Location: location, Message: message})
+}
+
+// validateCsv validates each row of the CSV content against the column definitions
+// and builds a preview of the first rows
+func validateCsv(columns []fields.ContentSchemaColumn, content []byte, previewRows int, result *Result) {
+
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		result.addError("line 1", "file is empty, expected a header row")
+		return
+	}
+	if err != nil {
+		result.addError("line 1", fmt.Sprintf("file is not valid CSV: %v", err))
+		return
+	}
+
+	result.Preview = &Preview{Columns: header, Rows: [][]string{}}
+
+	// map column definitions to their position in the header
+	columnIndexes := make(map[string]int, len(header))
+	for i, name := range header {
+		columnIndexes[strings.TrimSpace(name)] = i
+	}
+
+	for _, column := range columns {
+		if _, ok := columnIndexes[column.Name]; !ok && column.Required {
+			result.addError("line 1", fmt.Sprintf("required column \"%s\" is missing from the header", column.Name))
+		}
+	}
+
+	compiledPatterns := make(map[string]*regexp.Regexp, len(columns))
+	for _, column := range columns {
+		if column.Pattern != "" {
+			compiledPatterns[column.Name] = regexp.MustCompile(column.Pattern)
+		}
+	}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			result.addError(fmt.Sprintf("line %d", line), fmt.Sprintf("row is not valid CSV: %v", err))
+			continue
+		}
+
+		result.Preview.TotalRows++
+		if len(result.Preview.Rows) < previewRows {
+			result.Preview.Rows = append(result.Preview.Rows, record)
+		}
+
+		if len(record) != len(header) {
+			result.addError(fmt.Sprintf("line %d", line), fmt.Sprintf("expected %d values but found %d", len(header), len(record)))
+		}
+
+		for _, column := range columns {
+			index, ok := columnIndexes[column.Name]
+			if !ok {
+				continue
+			}
+
+			var value string
+			if index < len(record) {
+				value = strings.TrimSpace(record[index])
+			}
+
+			location := fmt.Sprintf("line %d, column \"%s\"", line, column.Name)
+			if value == "" {
+				if column.Required {
+					result.addError(location, "value is required")
+				}
+				continue
+			}
+
+			if message := checkCsvValueType(column.Type, value); message != "" {
+				result.addError(location, message)
+			}
+
+			if pattern, ok := compiledPatterns[column.Name]; ok && !pattern.MatchString(value) {
+				result.addError(location, fmt.Sprintf("value '%s' does not match pattern '%s'", value, column.Pattern))
+			}
+
+			if len(column.Choices) > 0 && !toolbox.StringInSlice(value, column.Choices) {
+				result.addError(location, fmt.Sprintf("value '%s' is not one of: %s", value, strings.Join(column.Choices, ", ")))
+			}
+		}
+	}
+}
+
+// checkCsvValueType returns a message describing why the value does not satisfy the
+// column type, or an empty string if it does
+func checkCsvValueType(columnType, value string) string {
+	switch columnType {
+	case "integer":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Sprintf("value '%s' is not an integer", value)
+		}
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Sprintf("value '%s' is not a number", value)
+		}
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Sprintf("value '%s' is not a boolean", value)
+		}
+	}
+
+	return ""
+}
+
+// buildDocumentPreview builds a preview table for a JSON or YAML document. Arrays of
+// objects are shown as one row per item, objects as key/value pairs and any other
+// value as a single cell.
+func buildDocumentPreview(document interface{}, previewRows int) *Preview {
+
+	switch typedDocument := document.(type) {
+	case []interface{}:
+		preview := &Preview{Columns: []string{}, Rows: [][]string{}, TotalRows: len(typedDocument)}
+
+		items := typedDocument
+		if len(items) > previewRows {
+			items = items[:previewRows]
+		}
+
+		// collect the columns from the keys of the previewed items
+		for _, item := range items {
+			object, ok := item.(map[string]interface{})
+			if !ok {
+				if !toolbox.StringInSlice("value", preview.Columns) {
+					preview.Columns = append(preview.Columns, "value")
+				}
+				continue
+			}
+			for _, key := range sortedKeys(object) {
+				if !toolbox.StringInSlice(key, preview.Columns) {
+					preview.Columns = append(preview.Columns, key)
+				}
+			}
+		}
+
+		for _, item := range items {
+			row := make([]string, len(preview.Columns))
+			object, ok := item.(map[string]interface{})
+			for i, column := range preview.Columns {
+				if !ok {
+					if column == "value" {
+						row[i] = stringifyValue(item)
+					}
+					continue
+				}
+				if value, exists := object[column]; exists {
+					row[i] = stringifyValue(value)
+				}
+			}
+			preview.Rows = append(preview.Rows, row)
+		}
+
+		return preview
+
+	case map[string]interface{}:
+		preview := &Preview{Columns: []string{"key", "value"}, Rows: [][]string{}, TotalRows: len(typedDocument)}
+		for _, key := range sortedKeys(typedDocument) {
+			if len(preview.Rows) >= previewRows {
+				break
+			}
+			preview.Rows = append(preview.Rows, []string{key, stringifyValue(typedDocument[key])})
+		}
+
+		return preview
+	}
+
+	return &Preview{Columns: []string{"value"}, Rows: [][]string{{stringifyValue(document)}}, TotalRows: 1}
+}
+
+// stringifyValue returns a string representation of a document value suitable for
+// displaying in a preview table cell
+func stringifyValue(value interface{}) string {
+	switch typedValue := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typedValue
+	case map[string]interface{}, []interface{}:
+		encoded, err := json.Marshal(typedValue)
+		if err != nil {
+			return fmt.Sprintf("%v", typedValue)
+		}
+		return string(encoded)
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
+// sortedKeys returns the keys of the object in alphabetical order
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// normalise converts the output of the YAML decoder (which uses map[interface{}]interface{})
+// and the JSON decoder into a single representation, using map[string]interface{} for objects,
+// []interface{} for arrays and float64 for numbers
+func normalise(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(typedValue))
+		for k, v := range typedValue {
+			object[fmt.Sprintf("%v", k)] = normalise(v)
+		}
+		return object
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(typedValue))
+		for k, v := range typedValue {
+			object[k] = normalise(v)
+		}
+		return object
+	case []interface{}:
+		array := make([]interface{}, len(typedValue))
+		for i, v := range typedValue {
+			array[i] = normalise(v)
+		}
+		return array
+	case int:
+		return float64(typedValue)
+	case int64:
+		return float64(typedValue)
+	case uint64:
+		return float64(typedValue)
+	case float32:
+		return float64(typedValue)
+	}
+
+	return value
+}
@@ -0,0 +1,153 @@
+package contentschema_test
+
+import (
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/contentschema"
+	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+
+	deploymentJsonSchema := map[interface{}]interface{}{
+		"type":     "object",
+		"required": []interface{}{"service", "replicas"},
+		"properties": map[interface{}]interface{}{
+			"service":  map[interface{}]interface{}{"type": "string", "pattern": "^[a-z-]+$"},
+			"replicas": map[interface{}]interface{}{"type": "integer", "minimum": 1, "maximum": 5},
+			"regions": map[interface{}]interface{}{
+				"type":  "array",
+				"items": map[interface{}]interface{}{"type": "string", "enum": []interface{}{"eu", "us"}},
+			},
+		},
+		"additionalProperties": false,
+	}
+
+	tests := []struct {
+		name             string
+		schema           *fields.ContentSchema
+		fileName         string
+		content          string
+		expectedFormat   string
+		expectedErrors   []contentschema.ValidationError
+		expectedPreview  *contentschema.Preview
+		expectedValidity bool
+	}{
+		{
+			name: "success - csv matches column definitions",
+			schema: &fields.ContentSchema{
+				Columns: []fields.ContentSchemaColumn{
+					{Name: "email", Type: "string", Required: true, Pattern: "@"},
+					{Name: "age", Type: "integer"},
+				},
+				PreviewRows: 1,
+			},
+			fileName:         "users.csv",
+			content:          "email,age\njane@example.com,31\njohn@example.com,\n",
+			expectedFormat:   "csv",
+			expectedErrors:   []contentschema.ValidationError{},
+			expectedPreview:  &contentschema.Preview{Columns: []string{"email", "age"}, Rows: [][]string{{"jane@example.com", "31"}}, TotalRows: 2},
+			expectedValidity: true,
+		},
+		{
+			name: "failed - csv rows violate column definitions",
+			schema: &fields.ContentSchema{
+				Columns: []fields.ContentSchemaColumn{
+					{Name: "email", Type: "string", Required: true},
+					{Name: "age", Type: "integer"},
+					{Name: "tier", Choices: []string{"gold", "silver"}},
+				},
+			},
+			fileName:       "users.csv",
+			content:        "email,age,tier\n,31,gold\njohn@example.com,old,bronze\n",
+			expectedFormat: "csv",
+			expectedErrors: []contentschema.ValidationError{
+				{Location: `line 2, column "email"`, Message: "value is required"},
+				{Location: `line 3, column "age"`, Message: "value 'old' is not an integer"},
+				{Location: `line 3, column "tier"`, Message: "value 'bronze' is not one of: gold, silver"},
+			},
+			expectedPreview: &contentschema.Preview{Columns: []string{"email", "age", "tier"}, Rows: [][]string{{"", "31", "gold"}, {"john@example.com", "old", "bronze"}}, TotalRows: 2},
+		},
+		{
+			name: "failed - csv missing required column",
+			schema: &fields.ContentSchema{
+				Format:  "csv",
+				Columns: []fields.ContentSchemaColumn{{Name: "email", Required: true}},
+			},
+			fileName:       "users.txt",
+			content:        "name\njane\n",
+			expectedFormat: "csv",
+			expectedErrors: []contentschema.ValidationError{
+				{Location: "line 1", Message: `required column "email" is missing from the header`},
+			},
+			expectedPreview: &contentschema.Preview{Columns: []string{"name"}, Rows: [][]string{{"jane"}}, TotalRows: 1},
+		},
+		{
+			name:             "success - json matches json schema",
+			schema:           &fields.ContentSchema{JsonSchema: deploymentJsonSchema},
+			fileName:         "deployment.json",
+			content:          `{"service": "checkout", "replicas": 3, "regions": ["eu"]}`,
+			expectedFormat:   "json",
+			expectedErrors:   []contentschema.ValidationError{},
+			expectedPreview:  &contentschema.Preview{Columns: []string{"key", "value"}, Rows: [][]string{{"regions", `["eu"]`}, {"replicas", "3"}, {"service", "checkout"}}, TotalRows: 3},
+			expectedValidity: true,
+		},
+		{
+			name:           "failed - yaml violates json schema",
+			schema:         &fields.ContentSchema{JsonSchema: deploymentJsonSchema},
+			fileName:       "deployment.yml",
+			content:        "service: Checkout\nreplicas: 9\nregions: [eu, asia]\nowner: me\n",
+			expectedFormat: "yaml",
+			expectedErrors: []contentschema.ValidationError{
+				{Location: "/owner", Message: "property 'owner' is not allowed"},
+				{Location: "/regions/1", Message: "value asia is not one of the allowed values"},
+				{Location: "/replicas", Message: "value 9 is greater than the maximum of 5"},
+				{Location: "/service", Message: "value 'Checkout' does not match pattern '^[a-z-]+$'"},
+			},
+			expectedPreview: &contentschema.Preview{Columns: []string{"key", "value"}, Rows: [][]string{{"owner", "me"}, {"regions", `["eu","asia"]`}, {"replicas", "9"}, {"service", "Checkout"}}, TotalRows: 4},
+		},
+		{
+			name:           "failed - json array items missing required property",
+			schema:         &fields.ContentSchema{JsonSchema: map[interface{}]interface{}{"type": "array", "items": deploymentJsonSchema}},
+			fileName:       "deployments.json",
+			content:        `[{"service": "checkout", "replicas": 1}, {"service": "basket"}]`,
+			expectedFormat: "json",
+			expectedErrors: []contentschema.ValidationError{
+				{Location: "/1", Message: "missing required property 'replicas'"},
+			},
+			expectedPreview: &contentschema.Preview{Columns: []string{"replicas", "service"}, Rows: [][]string{{"1", "checkout"}, {"", "basket"}}, TotalRows: 2},
+		},
+		{
+			name:           "failed - malformed json",
+			schema:         &fields.ContentSchema{},
+			fileName:       "deployment.json",
+			content:        `{"service": `,
+			expectedFormat: "json",
+			expectedErrors: []contentschema.ValidationError{
+				{Location: "/", Message: "file is not valid JSON: unexpected end of JSON input"},
+			},
+		},
+		{
+			name:           "failed - unknown format",
+			schema:         &fields.ContentSchema{},
+			fileName:       "deployment.txt",
+			content:        "hello",
+			expectedFormat: "",
+			expectedErrors: []contentschema.ValidationError{
+				{Location: "/", Message: "unable to determine the format of 'deployment.txt', expected one of: json, yaml, csv"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := contentschema.Validate(tt.schema, tt.fileName, []byte(tt.content))
+
+			assert.Equal(t, tt.expectedFormat, result.Format)
+			assert.Equal(t, tt.expectedErrors, result.Errors)
+			assert.Equal(t, tt.expectedPreview, result.Preview)
+			assert.Equal(t, tt.expectedValidity, result.Valid())
+		})
+	}
+}
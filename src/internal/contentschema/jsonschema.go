@@ -0,0 +1,190 @@
+package contentschema
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// validateJsonValue validates the value found at the given JSON pointer against the
+// provided JSON Schema. The commonly used subset of JSON Schema keywords is supported:
+// type, enum, const, required, properties, additionalProperties, items, minItems,
+// maxItems, minLength, maxLength, pattern, minimum and maximum. Schemas using any other
+// keyword are rejected when the fields are validated (see fields.SupportedJsonSchemaKeywords).
+func validateJsonValue(schema map[string]interface{}, value interface{}, pointer string, result *Result) {
+
+	if expectedTypes := schemaTypes(schema["type"]); len(expectedTypes) > 0 {
+		if !matchesAnyType(value, expectedTypes) {
+			result.addError(pointer, fmt.Sprintf("expected %s but found %s", strings.Join(expectedTypes, " or "), jsonTypeOf(value)))
+			return
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		var found bool
+		for _, allowed := range enum {
+			if reflect.DeepEqual(allowed, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result.addError(pointer, fmt.Sprintf("value %s is not one of the allowed values", stringifyValue(value)))
+		}
+	}
+
+	if constValue, ok := schema["const"]; ok && !reflect.DeepEqual(constValue, value) {
+		result.addError(pointer, fmt.Sprintf("value %s must be %s", stringifyValue(value), stringifyValue(constValue)))
+	}
+
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		validateJsonObject(schema, typedValue, pointer, result)
+
+	case []interface{}:
+		if minItems, ok := schemaNumber(schema["minItems"]); ok && float64(len(typedValue)) < minItems {
+			result.addError(pointer, fmt.Sprintf("expected at least %v item(s) but found %d", minItems, len(typedValue)))
+		}
+		if maxItems, ok := schemaNumber(schema["maxItems"]); ok && float64(len(typedValue)) > maxItems {
+			result.addError(pointer, fmt.Sprintf("expected at most %v item(s) but found %d", maxItems, len(typedValue)))
+		}
+		if itemSchema, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range typedValue {
+				validateJsonValue(itemSchema, item, fmt.Sprintf("%s/%d", pointer, i), result)
+			}
+		}
+
+	case string:
+		length := float64(utf8.RuneCountInString(typedValue))
+		if minLength, ok := schemaNumber(schema["minLength"]); ok && length < minLength {
+			result.addError(pointer, fmt.Sprintf("expected at least %v character(s) but found %v", minLength, length))
+		}
+		if maxLength, ok := schemaNumber(schema["maxLength"]); ok && length > maxLength {
+			result.addError(pointer, fmt.Sprintf("expected at most %v character(s) but found %v", maxLength, length))
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			compiledPattern, err := regexp.Compile(pattern)
+			if err != nil {
+				result.addError(pointer, fmt.Sprintf("schema pattern '%s' is invalid: %v", pattern, err))
+			} else if !compiledPattern.MatchString(typedValue) {
+				result.addError(pointer, fmt.Sprintf("value '%s' does not match pattern '%s'", typedValue, pattern))
+			}
+		}
+
+	case float64:
+		if minimum, ok := schemaNumber(schema["minimum"]); ok && typedValue < minimum {
+			result.addError(pointer, fmt.Sprintf("value %v is less than the minimum of %v", typedValue, minimum))
+		}
+		if maximum, ok := schemaNumber(schema["maximum"]); ok && typedValue > maximum {
+			result.addError(pointer, fmt.Sprintf("value %v is greater than the maximum of %v", typedValue, maximum))
+		}
+	}
+}
+
+// validateJsonObject validates the required, properties and additionalProperties
+// keywords against the provided object
+func validateJsonObject(schema map[string]interface{}, object map[string]interface{}, pointer string, result *Result) {
+
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, requiredKey := range required {
+			key := fmt.Sprintf("%v", requiredKey)
+			if _, exists := object[key]; !exists {
+				result.addError(pointer, fmt.Sprintf("missing required property '%s'", key))
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+
+	keys := sortedKeys(object)
+	for _, key := range keys {
+		propertyPointer := fmt.Sprintf("%s/%s", pointer, escapeJsonPointerToken(key))
+
+		if propertySchema, ok := properties[key].(map[string]interface{}); ok {
+			validateJsonValue(propertySchema, object[key], propertyPointer, result)
+			continue
+		}
+
+		switch additionalProperties := schema["additionalProperties"].(type) {
+		case bool:
+			if !additionalProperties {
+				result.addError(propertyPointer, fmt.Sprintf("property '%s' is not allowed", key))
+			}
+		case map[string]interface{}:
+			validateJsonValue(additionalProperties, object[key], propertyPointer, result)
+		}
+	}
+}
+
+// schemaTypes returns the list of types declared by the schema's type keyword
+func schemaTypes(typeKeyword interface{}) []string {
+	switch typedKeyword := typeKeyword.(type) {
+	case string:
+		return []string{typedKeyword}
+	case []interface{}:
+		types := make([]string, 0, len(typedKeyword))
+		for _, t := range typedKeyword {
+			types = append(types, fmt.Sprintf("%v", t))
+		}
+		sort.Strings(types)
+		return types
+	}
+
+	return nil
+}
+
+// matchesAnyType returns whether the value satisfies at least one of the expected types
+func matchesAnyType(value interface{}, expectedTypes []string) bool {
+	actualType := jsonTypeOf(value)
+	for _, expectedType := range expectedTypes {
+		if expectedType == actualType {
+			return true
+		}
+
+		// integers are also numbers
+		if expectedType == "number" && actualType == "integer" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// jsonTypeOf returns the JSON Schema type name of the value
+func jsonTypeOf(value interface{}) string {
+	switch typedValue := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if typedValue == math.Trunc(typedValue) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+
+	return fmt.Sprintf("%T", value)
+}
+
+// schemaNumber returns the numeric value of a schema keyword if it is a number
+func schemaNumber(keyword interface{}) (float64, bool) {
+	number, ok := keyword.(float64)
+	return number, ok
+}
+
+// escapeJsonPointerToken escapes a property name so it can be used within a JSON
+// pointer (RFC 6901)
+func escapeJsonPointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
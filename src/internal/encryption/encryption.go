@@ -0,0 +1,149 @@
+package encryption
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+	"github.com/boasihq/interactive-inputs/internal/errors"
+)
+
+const (
+	// EncryptedFileExtension is the extension appended to the name of encrypted files
+	EncryptedFileExtension = ".age"
+)
+
+// ParseRecipients parses the provided public keys into age recipients. Both native age
+// public keys (age1...) and SSH public keys (ssh-ed25519 and ssh-rsa) are supported.
+// Empty lines and lines starting with # are ignored.
+func ParseRecipients(publicKeys []string) ([]age.Recipient, error) {
+	var recipients []age.Recipient
+
+	for i, publicKey := range publicKeys {
+		publicKey = strings.TrimSpace(publicKey)
+		if publicKey == "" || strings.HasPrefix(publicKey, "#") {
+			continue
+		}
+
+		var recipient age.Recipient
+		var err error
+
+		if strings.HasPrefix(publicKey, "ssh-") {
+			recipient, err = agessh.ParseRecipient(publicKey)
+		} else {
+			recipient, err = age.ParseX25519Recipient(publicKey)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("recipient %d is not a valid age or SSH public key: %w", i+1, err)
+		}
+
+		recipients = append(recipients, recipient)
+	}
+
+	return recipients, nil
+}
+
+// ParseIdentities parses the provided private key(s) into age identities. The key can either be
+// an age identity file (holding one or more AGE-SECRET-KEY-... lines) or an unencrypted SSH
+// private key in PEM format.
+func ParseIdentities(privateKey []byte) ([]age.Identity, error) {
+	if bytes.Contains(privateKey, []byte("PRIVATE KEY-----")) {
+		identity, err := agessh.ParseIdentity(privateKey)
+		if err != nil {
+			return nil, err
+		}
+
+		return []age.Identity{identity}, nil
+	}
+
+	return age.ParseIdentities(bytes.NewReader(privateKey))
+}
+
+// Encrypt encrypts the content to all of the provided recipients
+func Encrypt(recipients []age.Recipient, content []byte) ([]byte, error) {
+	var encrypted bytes.Buffer
+
+	writer, err := age.Encrypt(&encrypted, recipients...)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = writer.Write(content); err != nil {
+		return nil, err
+	}
+
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return encrypted.Bytes(), nil
+}
+
+// DecryptFile decrypts the encrypted file at the source path with the provided identities,
+// writing the plaintext to the destination path. The plaintext is written to a temporary file
+// that is only moved into place once decrypted, so a failed decryption leaves nothing behind,
+// and ErrDecryptOverwritesInput is returned when the destination is the source itself
+func DecryptFile(identities []age.Identity, sourcePath, destinationPath string) (err error) {
+	if isSameFile(sourcePath, destinationPath) {
+		return errors.ErrDecryptOverwritesInput
+	}
+
+	encryptedFile, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer encryptedFile.Close()
+
+	reader, err := age.Decrypt(encryptedFile, identities...)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(destinationPath), 0700)
+	if err != nil {
+		return err
+	}
+
+	decryptedFile, err := os.CreateTemp(filepath.Dir(destinationPath), fmt.Sprintf(".%s.*.tmp", filepath.Base(destinationPath)))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			decryptedFile.Close()
+			os.Remove(decryptedFile.Name())
+		}
+	}()
+
+	_, err = io.Copy(decryptedFile, reader)
+	if err != nil {
+		return err
+	}
+
+	err = decryptedFile.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(decryptedFile.Name(), destinationPath)
+}
+
+// isSameFile returns whether both paths point at the same file, comparing the files
+// themselves when both exist (i.e. through a symlink) and their absolute paths otherwise
+func isSameFile(pathA, pathB string) bool {
+	infoA, errA := os.Stat(pathA)
+	infoB, errB := os.Stat(pathB)
+	if errA == nil && errB == nil {
+		return os.SameFile(infoA, infoB)
+	}
+
+	absolutePathA, errA := filepath.Abs(pathA)
+	absolutePathB, errB := filepath.Abs(pathB)
+
+	return errA == nil && errB == nil && absolutePathA == absolutePathB
+}
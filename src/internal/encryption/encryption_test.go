@@ -0,0 +1,137 @@
+package encryption_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/boasihq/interactive-inputs/internal/encryption"
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+
+	ageIdentity, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+
+	sshPublicKey, sshPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	sshAuthorizedKey, err := ssh.NewPublicKey(sshPublicKey)
+	assert.NoError(t, err)
+	sshPrivateKeyPem, err := ssh.MarshalPrivateKey(sshPrivateKey, "")
+	assert.NoError(t, err)
+
+	recipients, err := encryption.ParseRecipients([]string{
+		"# the deploy team",
+		ageIdentity.Recipient().String(),
+		string(ssh.MarshalAuthorizedKey(sshAuthorizedKey)),
+		"",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, recipients, 2)
+
+	encrypted, err := encryption.Encrypt(recipients, []byte("super secret certificate"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(encrypted), "super secret certificate")
+
+	tests := []struct {
+		name       string
+		privateKey []byte
+	}{
+		{
+			name:       "age identity",
+			privateKey: []byte(ageIdentity.String() + "\n"),
+		},
+		{
+			name:       "ssh private key",
+			privateKey: pem.EncodeToMemory(sshPrivateKeyPem),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			encryptedPath := filepath.Join(dir, "cert.pem"+encryption.EncryptedFileExtension)
+			decryptedPath := filepath.Join(dir, "out", "cert.pem")
+			assert.NoError(t, os.WriteFile(encryptedPath, encrypted, 0600))
+
+			identities, err := encryption.ParseIdentities(tt.privateKey)
+			assert.NoError(t, err)
+			assert.NoError(t, encryption.DecryptFile(identities, encryptedPath, decryptedPath))
+
+			decrypted, err := os.ReadFile(decryptedPath)
+			assert.NoError(t, err)
+			assert.Equal(t, "super secret certificate", string(decrypted))
+		})
+	}
+}
+
+func TestDecryptFile_KeepsEncryptedFile(t *testing.T) {
+
+	ageIdentity, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+	otherIdentity, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+
+	encrypted, err := encryption.Encrypt([]age.Recipient{ageIdentity.Recipient()}, []byte("super secret certificate"))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		identity    age.Identity
+		destination string
+		expectedErr error
+	}{
+		{
+			name:        "decrypted over itself",
+			identity:    ageIdentity,
+			destination: "cert.pem",
+			expectedErr: errors.ErrDecryptOverwritesInput,
+		},
+		{
+			name:        "decrypted over itself through a relative path",
+			identity:    ageIdentity,
+			destination: "./out/../cert.pem",
+			expectedErr: errors.ErrDecryptOverwritesInput,
+		},
+		{
+			name:        "decrypted with the wrong identity",
+			identity:    otherIdentity,
+			destination: "cert.pem.decrypted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			encryptedPath := filepath.Join(dir, "cert.pem")
+			assert.NoError(t, os.WriteFile(encryptedPath, encrypted, 0600))
+
+			err := encryption.DecryptFile([]age.Identity{tt.identity}, encryptedPath, filepath.Join(dir, tt.destination))
+			assert.Error(t, err)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			}
+
+			// the encrypted file is untouched and nothing is left behind
+			content, err := os.ReadFile(encryptedPath)
+			assert.NoError(t, err)
+			assert.Equal(t, encrypted, content)
+
+			entries, err := os.ReadDir(dir)
+			assert.NoError(t, err)
+			assert.Len(t, entries, 1)
+		})
+	}
+}
+
+func TestParseRecipients_Invalid(t *testing.T) {
+	_, err := encryption.ParseRecipients([]string{"age1notarealkey"})
+	assert.Error(t, err)
+}
@@ -52,4 +52,283 @@ var (
 
 	// ErrDuplicateFieldLabelDetected is returned when the same field label is detected in the input data
 	ErrDuplicateFieldLabelDetected = errors.New("DuplicateFieldLabelDetected")
+
+	// ErrInvalidContentSchemaProvided is returned when the content schema provided for a field
+	// is not valid or is used on a field type that does not support it
+	ErrInvalidContentSchemaProvided = errors.New("InvalidContentSchemaProvided")
+
+	// ErrUnexpectedScannerVerificationResponse is returned when the scanner does not respond
+	// as expected when its connection is verified
+	ErrUnexpectedScannerVerificationResponse = errors.New("UnexpectedScannerVerificationResponse")
+
+	// ErrInvalidScannerCommandProvided is returned when the scanner command provided cannot be found
+	ErrInvalidScannerCommandProvided = errors.New("InvalidScannerCommandProvided")
+
+	// ErrMultipleScannersProvided is returned when both a clamd address and a scanner command are provided
+	ErrMultipleScannersProvided = errors.New("MultipleScannersProvided")
+
+	// ErrInvalidScannerTimeoutValueProvided is returned when the scanner timeout provided cannot be
+	// converted to an integer
+	ErrInvalidScannerTimeoutValueProvided = errors.New("InvalidScannerTimeoutValueProvided")
+
+	// ErrInvalidEncryptionRecipientProvided is returned when a recipient provided for encrypting
+	// uploaded files is not a valid age or SSH public key
+	ErrInvalidEncryptionRecipientProvided = errors.New("InvalidEncryptionRecipientProvided")
+
+	// ErrUnknownSubcommand is returned when the binary is invoked with a subcommand that does not exist
+	ErrUnknownSubcommand = errors.New("UnknownSubcommand")
+
+	// ErrInvalidSubcommandArgumentsProvided is returned when a subcommand is invoked with missing
+	// or invalid arguments
+	ErrInvalidSubcommandArgumentsProvided = errors.New("InvalidSubcommandArgumentsProvided")
+
+	// ErrInvalidUploadCacheFileModeProvided is returned when the file mode provided for cached
+	// uploads is not a valid octal permission, i.e. 0600
+	ErrInvalidUploadCacheFileModeProvided = errors.New("InvalidUploadCacheFileModeProvided")
+
+	// ErrInvalidUploadCacheCleanupEventProvided is returned when an event provided for cleaning up
+	// cached uploads is not supported
+	ErrInvalidUploadCacheCleanupEventProvided = errors.New("InvalidUploadCacheCleanupEventProvided")
+
+	// ErrInvalidUploadCacheStaleAfterValueProvided is returned when the stale period provided for
+	// sweeping cached uploads cannot be converted to an integer
+	ErrInvalidUploadCacheStaleAfterValueProvided = errors.New("InvalidUploadCacheStaleAfterValueProvided")
+
+	// ErrUnexpectedGithubApiResponse is returned when the GitHub API responds with an unexpected
+	// status code
+	ErrUnexpectedGithubApiResponse = errors.New("UnexpectedGithubApiResponse")
+
+	// ErrInvalidCommitToProvided is returned when the commitTo provided for a field is not valid
+	// or is used on a field type that does not support it
+	ErrInvalidCommitToProvided = errors.New("InvalidCommitToProvided")
+
+	// ErrRepositoryNotFoundInContext is returned when the repository running the action cannot be
+	// determined from the action context
+	ErrRepositoryNotFoundInContext = errors.New("RepositoryNotFoundInContext")
+
+	// ErrInvalidOutputFormatProvided is returned when the output format or delimiter provided for
+	// a field is not supported
+	ErrInvalidOutputFormatProvided = errors.New("InvalidOutputFormatProvided")
+
+	// ErrInvalidExportEnvNameStyleProvided is returned when the style provided for mangling labels
+	// into environment variable names is not supported
+	ErrInvalidExportEnvNameStyleProvided = errors.New("InvalidExportEnvNameStyleProvided")
+
+	// ErrInvalidExportFileFormatProvided is returned when the format provided for the results file
+	// is not supported
+	ErrInvalidExportFileFormatProvided = errors.New("InvalidExportFileFormatProvided")
+
+	// ErrInvalidExportSensitivePolicyProvided is returned when the policy provided for exporting
+	// sensitive fields is not supported
+	ErrInvalidExportSensitivePolicyProvided = errors.New("InvalidExportSensitivePolicyProvided")
+
+	// ErrInvalidOutputSpillThresholdProvided is returned when the size provided for spilling
+	// oversized values to files is not a positive number
+	ErrInvalidOutputSpillThresholdProvided = errors.New("InvalidOutputSpillThresholdProvided")
+
+	// ErrInvalidEscapedOutputProvided is returned when the escaped output provided for a field
+	// is not supported
+	ErrInvalidEscapedOutputProvided = errors.New("InvalidEscapedOutputProvided")
+
+	// ErrInvalidAllowedCharactersProvided is returned when the allowed characters provided for a
+	// field are not a valid character class, or the field type doesn't support them
+	ErrInvalidAllowedCharactersProvided = errors.New("InvalidAllowedCharactersProvided")
+
+	// ErrInvalidSecretDetectionPolicyProvided is returned when the policy provided for handling
+	// secrets found in submitted values is not supported
+	ErrInvalidSecretDetectionPolicyProvided = errors.New("InvalidSecretDetectionPolicyProvided")
+
+	// ErrInvalidOutputProvided is returned when the output provided for a field has an invalid
+	// name or an unsupported transform
+	ErrInvalidOutputProvided = errors.New("InvalidOutputProvided")
+
+	// ErrOutputNameCollisionDetected is returned when more than one field sets the same output or
+	// exports to the same environment variable
+	ErrOutputNameCollisionDetected = errors.New("OutputNameCollisionDetected")
+
+	// ErrReservedOutputNameProvided is returned when a field would set an output that the action
+	// sets itself, i.e. outcome
+	ErrReservedOutputNameProvided = errors.New("ReservedOutputNameProvided")
+
+	// ErrInvalidNotifierCompletionMessageProvided is returned when the notifier completion message
+	// is not a valid template
+	ErrInvalidNotifierCompletionMessageProvided = errors.New("InvalidNotifierCompletionMessageProvided")
+
+	// ErrInvalidReceiptSigningAlgorithmProvided is returned when the algorithm provided for signing
+	// the submission receipt is not supported
+	ErrInvalidReceiptSigningAlgorithmProvided = errors.New("InvalidReceiptSigningAlgorithmProvided")
+
+	// ErrInvalidReceiptSigningKeyProvided is returned when the key provided for signing or verifying
+	// the submission receipt is not valid for the algorithm
+	ErrInvalidReceiptSigningKeyProvided = errors.New("InvalidReceiptSigningKeyProvided")
+
+	// ErrInvalidReceiptSignature is returned when a submission receipt isn't signed, or its
+	// signature doesn't match its contents
+	ErrInvalidReceiptSignature = errors.New("InvalidReceiptSignature")
+
+	// ErrInvalidReceiptProvided is returned when a submission receipt can't be parsed, or holds
+	// content the receipt format doesn't have
+	ErrInvalidReceiptProvided = errors.New("InvalidReceiptProvided")
+
+	// ErrInvalidAuthModeProvided is returned when the mode provided for signing in to the portal
+	// is not supported
+	ErrInvalidAuthModeProvided = errors.New("InvalidAuthModeProvided")
+
+	// ErrAuthClientNotProvided is returned when signing in to the portal is enabled without the
+	// client ID (and, for the web flow, the client secret) of the GitHub OAuth app
+	ErrAuthClientNotProvided = errors.New("AuthClientNotProvided")
+
+	// ErrAuthRuleNotProvided is returned when signing in to the portal is enabled without any
+	// allowed users, allowed teams or minimum permission to authorise submitters against
+	ErrAuthRuleNotProvided = errors.New("AuthRuleNotProvided")
+
+	// ErrInvalidAuthAllowedTeamProvided is returned when an allowed team is not in the
+	// org/team-slug format
+	ErrInvalidAuthAllowedTeamProvided = errors.New("InvalidAuthAllowedTeamProvided")
+
+	// ErrInvalidAuthMinimumPermissionProvided is returned when the minimum repository
+	// permission provided is not supported
+	ErrInvalidAuthMinimumPermissionProvided = errors.New("InvalidAuthMinimumPermissionProvided")
+
+	// ErrInvalidAuthState is returned when the state returned by the OAuth flow doesn't match
+	// the one the sign in was started with, or the sign in has expired
+	ErrInvalidAuthState = errors.New("InvalidAuthState")
+
+	// ErrAuthorisationPending is returned while the visitor hasn't yet entered their code
+	// during the device flow
+	ErrAuthorisationPending = errors.New("AuthorisationPending")
+
+	// ErrUnexpectedOAuthResponse is returned when GitHub's OAuth endpoints respond with an error
+	ErrUnexpectedOAuthResponse = errors.New("UnexpectedOAuthResponse")
+
+	// ErrSubmitterNotAuthorised is returned when the signed in visitor is not in the allowed
+	// users or teams and doesn't have the minimum repository permission
+	ErrSubmitterNotAuthorised = errors.New("SubmitterNotAuthorised")
+
+	// ErrAccessCodeNotDeliverable is returned when the portal is unlocked with a generated access
+	// code, but there is no notifier enabled to send it to
+	ErrAccessCodeNotDeliverable = errors.New("AccessCodeNotDeliverable")
+
+	// ErrInvalidAccessCodeMaxAttemptsProvided is returned when the number of incorrect access codes
+	// accepted before the portal is locked is not a positive integer
+	ErrInvalidAccessCodeMaxAttemptsProvided = errors.New("InvalidAccessCodeMaxAttemptsProvided")
+
+	// ErrInvalidAccessCode is returned when the access code entered doesn't match the one that
+	// unlocks the portal
+	ErrInvalidAccessCode = errors.New("InvalidAccessCode")
+
+	// ErrAccessCodeLockedOut is returned once too many incorrect access codes have been entered
+	// across every visitor, after which the portal can't be unlocked
+	ErrAccessCodeLockedOut = errors.New("AccessCodeLockedOut")
+
+	// ErrAccessCodeCoolingDown is returned once a visitor has entered too many incorrect access
+	// codes, until their cooldown has passed
+	ErrAccessCodeCoolingDown = errors.New("AccessCodeCoolingDown")
+
+	// ErrInvalidNgrokDomainProvided is returned when the reserved ngrok domain is not a bare
+	// hostname, i.e. it includes a scheme or path
+	ErrInvalidNgrokDomainProvided = errors.New("InvalidNgrokDomainProvided")
+
+	// ErrInvalidNgrokRegionProvided is returned when the ngrok region is not supported
+	ErrInvalidNgrokRegionProvided = errors.New("InvalidNgrokRegionProvided")
+
+	// ErrInvalidNgrokOAuthProviderProvided is returned when the ngrok OAuth provider is not supported
+	ErrInvalidNgrokOAuthProviderProvided = errors.New("InvalidNgrokOAuthProviderProvided")
+
+	// ErrNgrokOAuthProviderNotProvided is returned when allowed emails or domains are provided
+	// without the ngrok OAuth provider to check them with
+	ErrNgrokOAuthProviderNotProvided = errors.New("NgrokOAuthProviderNotProvided")
+
+	// ErrInvalidNgrokBasicAuthProvided is returned when a ngrok basic auth credential is not in
+	// the username:password format or its password is too short or long
+	ErrInvalidNgrokBasicAuthProvided = errors.New("InvalidNgrokBasicAuthProvided")
+
+	// ErrInvalidNgrokCidrProvided is returned when an allowed or denied CIDR can't be parsed
+	ErrInvalidNgrokCidrProvided = errors.New("InvalidNgrokCidrProvided")
+
+	// ErrSignedLinkNotDeliverable is returned when signed links are enabled, but there is no
+	// notifier enabled to send them to
+	ErrSignedLinkNotDeliverable = errors.New("SignedLinkNotDeliverable")
+
+	// ErrInvalidSignedLinkExpiryProvided is returned when how long signed links can be opened
+	// for is not a positive integer
+	ErrInvalidSignedLinkExpiryProvided = errors.New("InvalidSignedLinkExpiryProvided")
+
+	// ErrInvalidSignedLink is returned when a signed link is malformed or its signature
+	// doesn't match
+	ErrInvalidSignedLink = errors.New("InvalidSignedLink")
+
+	// ErrSignedLinkExpired is returned when a signed link is opened after it has expired
+	ErrSignedLinkExpired = errors.New("SignedLinkExpired")
+
+	// ErrSignedLinkAlreadyUsed is returned when a single use signed link is opened again
+	ErrSignedLinkAlreadyUsed = errors.New("SignedLinkAlreadyUsed")
+
+	// ErrInvalidRateLimitProvided is returned when the rate-limit-per-ip or rate-limit-global
+	// input isn't a positive int
+	ErrInvalidRateLimitProvided = errors.New("InvalidRateLimitProvided")
+
+	// ErrInvalidMaxConcurrentUploadsProvided is returned when the max-concurrent-uploads
+	// input isn't a positive int
+	ErrInvalidMaxConcurrentUploadsProvided = errors.New("InvalidMaxConcurrentUploadsProvided")
+
+	// ErrInvalidMaxUploadSizeProvided is returned when the max-upload-size input isn't a
+	// positive int
+	ErrInvalidMaxUploadSizeProvided = errors.New("InvalidMaxUploadSizeProvided")
+
+	// ErrInvalidAuditLogWebhookProvided is returned when the audit-log-webhook input isn't
+	// an http(s) URL
+	ErrInvalidAuditLogWebhookProvided = errors.New("InvalidAuditLogWebhookProvided")
+
+	// ErrUnexpectedAuditLogWebhookStatusCode is returned when the audit log webhook doesn't
+	// accept a forwarded entry
+	ErrUnexpectedAuditLogWebhookStatusCode = errors.New("UnexpectedAuditLogWebhookStatusCode")
+
+	// ErrPortalNotOpen is returned when the portal is submitted or cancelled after it has
+	// already been submitted, cancelled or has expired
+	ErrPortalNotOpen = errors.New("PortalNotOpen")
+
+	// ErrDuplicateRequest is returned when the request that submitted or cancelled the
+	// portal is repeated with the same idempotency key
+	ErrDuplicateRequest = errors.New("DuplicateRequest")
+
+	// ErrInvalidApprovalPolicyProvided is returned when the approval-policy input isn't one
+	// of the valid policies
+	ErrInvalidApprovalPolicyProvided = errors.New("InvalidApprovalPolicyProvided")
+
+	// ErrInvalidApprovalDecision is returned when an approval portal is submitted without
+	// approving or rejecting the request
+	ErrInvalidApprovalDecision = errors.New("InvalidApprovalDecision")
+
+	// ErrApprovalCommentRequired is returned when the request is rejected without a comment
+	ErrApprovalCommentRequired = errors.New("ApprovalCommentRequired")
+
+	// ErrApproverAlreadyDecided is returned when an approver decides on a request they have
+	// already approved or rejected
+	ErrApproverAlreadyDecided = errors.New("ApproverAlreadyDecided")
+
+	// ErrTallyAlreadyDecided is returned when a decision is added to a tally that has already
+	// come to a decision
+	ErrTallyAlreadyDecided = errors.New("TallyAlreadyDecided")
+
+	// ErrApprovalNotEnabled is returned when approval-quorum, approval-required-users or
+	// approval-required-teams is provided without enabling approval
+	ErrApprovalNotEnabled = errors.New("ApprovalNotEnabled")
+
+	// ErrInvalidApprovalQuorumProvided is returned when the approval-quorum input isn't a
+	// positive int
+	ErrInvalidApprovalQuorumProvided = errors.New("InvalidApprovalQuorumProvided")
+
+	// ErrApprovalQuorumNeedsSignIn is returned when a quorum of approvers is asked for
+	// without visitors signing in with their GitHub identity, as approvers couldn't be told
+	// apart
+	ErrApprovalQuorumNeedsSignIn = errors.New("ApprovalQuorumNeedsSignIn")
+
+	// ErrInvalidApprovalRequiredTeamProvided is returned when a team of the
+	// approval-required-teams input isn't in the org/team-slug format
+	ErrInvalidApprovalRequiredTeamProvided = errors.New("InvalidApprovalRequiredTeamProvided")
+
+	// ErrDecryptOverwritesInput is returned when a file would be decrypted over the encrypted
+	// file it is read from
+	ErrDecryptOverwritesInput = errors.New("DecryptOverwritesInput")
 )
@@ -1,7 +1,13 @@
 package fields
 
 import (
+	"fmt"
+	"path"
+	"regexp"
+	"regexp/syntax"
+	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/boasihq/interactive-inputs/internal/errors"
 	"github.com/boasihq/interactive-inputs/internal/toolbox"
@@ -9,6 +15,25 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
+const (
+
+	// OutputNameSuffixSpillFile is the suffix of the output holding the path of the file a
+	// value too large to be set as an output is written to
+	OutputNameSuffixSpillFile = "-file"
+
+	// OutputNameSuffixManifest is the suffix of the output holding the path of the manifest
+	// of the files uploaded to a file or multifile field
+	OutputNameSuffixManifest = "-manifest"
+
+	// OutputNameSuffixCommitSha is the suffix of the output holding the sha of the commit
+	// the uploaded files were committed in
+	OutputNameSuffixCommitSha = "-commit-sha"
+
+	// OutputNameSuffixPullRequestUrl is the suffix of the output holding the url of the pull
+	// request opened for the committed files
+	OutputNameSuffixPullRequestUrl = "-pr-url"
+)
+
 var (
 
 	// ValidFieldTypes  is a list of valid field types supported by the action.
@@ -22,6 +47,85 @@ var (
 		"file",
 		"multifile",
 	}
+
+	// ValidContentSchemaFormats is a list of valid structured file formats that can
+	// be validated against a content schema at upload time.
+	ValidContentSchemaFormats = []string{
+		"json",
+		"yaml",
+		"csv",
+	}
+
+	// ValidContentSchemaColumnTypes is a list of valid column types that can be used
+	// when describing the columns of a CSV content schema.
+	ValidContentSchemaColumnTypes = []string{
+		"string",
+		"integer",
+		"number",
+		"boolean",
+	}
+
+	// SupportedJsonSchemaKeywords is a list of the JSON Schema keywords a content schema's
+	// jsonSchema can use, the annotations (i.e. title) being accepted and ignored.
+	SupportedJsonSchemaKeywords = []string{
+		"$schema",
+		"$id",
+		"$comment",
+		"title",
+		"description",
+		"default",
+		"examples",
+		"type",
+		"enum",
+		"const",
+		"required",
+		"properties",
+		"additionalProperties",
+		"items",
+		"minItems",
+		"maxItems",
+		"minLength",
+		"maxLength",
+		"pattern",
+		"minimum",
+		"maximum",
+	}
+
+	// ValidOutputFormats is a list of valid formats a field's value can be encoded
+	// in when it is set as an output.
+	ValidOutputFormats = []string{
+		"csv",
+		"json",
+		"newline",
+	}
+
+	// ValidEscapedOutputs is a list of valid ways a field's value can be escaped in
+	// its additional outputs.
+	ValidEscapedOutputs = []string{
+		"shell",
+		"json",
+	}
+
+	// ValidOutputTransformTypes is a list of valid transforms that can be applied to
+	// a field's value before it is set as an output.
+	ValidOutputTransformTypes = []string{
+		"trim",
+		"lower",
+		"upper",
+		"replace",
+		"prefix",
+		"suffix",
+	}
+
+	// validOutputEnvName matches valid environment variable names.
+	validOutputEnvName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+	// AllowedCharactersFieldTypes is a list of field types that support an
+	// allowedCharacters policy.
+	AllowedCharactersFieldTypes = []string{
+		"text",
+		"textarea",
+	}
 )
 
 // Fields is a struct that contains a list of Field structs, which represent the fields in a form to display to users.
@@ -45,20 +149,159 @@ type Field struct {
 // Required indicates whether the field must be filled out.
 // MaxLength is the maximum length of the field's value.
 // DisableAutoCopySelection is whether the field should stop automatically coping the selected option to the clipboard (valid fields: select, multiselect).
+// ContentSchema is the schema uploaded structured files are validated against (valid fields: file, multifile).
+// CommitTo is where the uploaded files are committed to in the repository once submitted (valid fields: file, multifile).
+// OutputFormat is the format the field's value is encoded in when it is set as an output, such as "json" or "newline".
+// OutputDelimiter is the delimiter used to separate values when the OutputFormat is "csv".
+// EmptyValue is the value output for the field when nothing is submitted for it.
+// Sensitive indicates whether the field's value is masked in the logs and left out of (or masked in) exports.
+// EscapedOutputs is a list of additional outputs set with the field's value escaped, such as "shell" for a <label>-shell output.
+// AllowedCharacters is the set of characters (in regular expression character class syntax, i.e. "a-z0-9-") the field's value is restricted to (valid fields: text, textarea).
+// Output is how the field's value is named and transformed when it is set as an output.
 type FieldProperties struct {
-	Display                  string   `yaml:"display"`
-	Type                     string   `yaml:"type"`
-	Description              string   `yaml:"description"`
-	Choices                  []string `yaml:"choices"`
-	Required                 bool     `yaml:"required"`
-	MaxLength                int      `yaml:"maxLength"`
-	Placeholder              string   `yaml:"placeholder"`
-	NumberMin                int      `yaml:"minNumber"`
-	NumberMax                int      `yaml:"maxNumber"`
-	DefaultValue             string   `yaml:"defaultValue"`
-	ReadOnly                 bool     `yaml:"readOnly"`
-	DisableAutoCopySelection bool     `yaml:"disableAutoCopySelection"`
-	AcceptedFileTypes        []string `yaml:"acceptedFileTypes"`
+	Display                  string         `yaml:"display"`
+	Type                     string         `yaml:"type"`
+	Description              string         `yaml:"description"`
+	Choices                  []string       `yaml:"choices"`
+	Required                 bool           `yaml:"required"`
+	MaxLength                int            `yaml:"maxLength"`
+	Placeholder              string         `yaml:"placeholder"`
+	NumberMin                int            `yaml:"minNumber"`
+	NumberMax                int            `yaml:"maxNumber"`
+	DefaultValue             string         `yaml:"defaultValue"`
+	ReadOnly                 bool           `yaml:"readOnly"`
+	DisableAutoCopySelection bool           `yaml:"disableAutoCopySelection"`
+	AcceptedFileTypes        []string       `yaml:"acceptedFileTypes"`
+	ContentSchema            *ContentSchema `yaml:"contentSchema"`
+	CommitTo                 *CommitTo      `yaml:"commitTo"`
+	OutputFormat             string         `yaml:"outputFormat"`
+	OutputDelimiter          string         `yaml:"outputDelimiter"`
+	EmptyValue               string         `yaml:"emptyValue"`
+	Sensitive                bool           `yaml:"sensitive"`
+	EscapedOutputs           []string       `yaml:"escapedOutputs"`
+	AllowedCharacters        string         `yaml:"allowedCharacters"`
+	Output                   *Output        `yaml:"output"`
+}
+
+// ContentSchema represents the schema that uploaded structured files (JSON, YAML or CSV)
+// are validated against when they are uploaded to the portal.
+// Format is the format of the uploaded file(s), if not set it is detected from the file extension.
+// JsonSchema is the JSON Schema used to validate JSON and YAML files.
+// Columns is the list of column definitions used to validate CSV files.
+// PreviewRows is the number of rows to display in the portal's preview table.
+type ContentSchema struct {
+	Format      string                      `yaml:"format"`
+	JsonSchema  map[interface{}]interface{} `yaml:"jsonSchema"`
+	Columns     []ContentSchemaColumn       `yaml:"columns"`
+	PreviewRows int                         `yaml:"previewRows"`
+}
+
+// ContentSchemaColumn represents a column expected in an uploaded CSV file.
+// Name is the header name of the column.
+// Type is the type every value in the column must satisfy, such as "string" or "integer".
+// Required indicates whether every row must provide a value for the column.
+// Pattern is a regular expression every value in the column must match.
+// Choices is a list of values the column is restricted to.
+type ContentSchemaColumn struct {
+	Name     string   `yaml:"name"`
+	Type     string   `yaml:"type"`
+	Required bool     `yaml:"required"`
+	Pattern  string   `yaml:"pattern"`
+	Choices  []string `yaml:"choices"`
+}
+
+// CommitTo represents where in the repository the files uploaded to a field are
+// committed to once the portal is submitted.
+// Path is the directory within the repository the files are committed to, defaults to the root.
+// Branch is the branch the files are committed to, it is created from Base if it does not exist.
+// Base is the branch the Branch is created from (and the pull request targets), defaults to the repository's default branch.
+// Message is the commit message.
+// PullRequest is the pull request opened from Branch into Base, if not set no pull request is opened.
+type CommitTo struct {
+	Path        string               `yaml:"path"`
+	Branch      string               `yaml:"branch"`
+	Base        string               `yaml:"base"`
+	Message     string               `yaml:"message"`
+	PullRequest *CommitToPullRequest `yaml:"pullRequest"`
+}
+
+// CommitToPullRequest represents the pull request opened for the committed files.
+// Title is the title of the pull request.
+// Body is the description of the pull request.
+// Draft indicates whether the pull request is opened as a draft.
+type CommitToPullRequest struct {
+	Title string `yaml:"title"`
+	Body  string `yaml:"body"`
+	Draft bool   `yaml:"draft"`
+}
+
+// Output represents how a field's value is named and transformed when it is set as an output.
+// Name is the name of the output used instead of the label, it is used as is (no kebab casing).
+// Env is the name of the environment variable the value is exported to, instead of one derived from the label.
+// Transforms is the list of transforms applied (in order) to the value before it is set as an output.
+type Output struct {
+	Name       string            `yaml:"name"`
+	Env        string            `yaml:"env"`
+	Transforms []OutputTransform `yaml:"transforms"`
+}
+
+// OutputTransform represents a transform applied to a field's value before it is set as an output.
+// Type is the type of the transform, such as "trim" or "replace".
+// Pattern is the regular expression replaced (valid types: replace).
+// Replacement is what the Pattern is replaced with, it can reference capture groups such as "${1}" (valid types: replace).
+// Value is the text added to the value (valid types: prefix, suffix).
+type OutputTransform struct {
+	Type        string `yaml:"type"`
+	Pattern     string `yaml:"pattern"`
+	Replacement string `yaml:"replacement"`
+	Value       string `yaml:"value"`
+}
+
+// OutputName returns the name of the field's output, which is its label unless a
+// custom name is provided.
+func (f *Field) OutputName() string {
+	if f.Properties.Output != nil && f.Properties.Output.Name != "" {
+		return f.Properties.Output.Name
+	}
+
+	return f.Label
+}
+
+// OutputNames returns the name of every output the field can set, i.e. its escaped
+// outputs, the outputs holding the path of spilled values and those of uploaded files.
+func (f *Field) OutputNames() []string {
+	outputName := f.OutputName()
+	isFileField := f.Properties.Type == "file" || f.Properties.Type == "multifile"
+
+	outputNames := []string{outputName}
+	if !isFileField {
+		outputNames = append(outputNames, outputName+OutputNameSuffixSpillFile)
+	}
+
+	for _, escapedOutput := range f.Properties.EscapedOutputs {
+		escapedOutputName := outputName + "-" + escapedOutput
+		outputNames = append(outputNames, escapedOutputName, escapedOutputName+OutputNameSuffixSpillFile)
+	}
+
+	if isFileField {
+		outputNames = append(outputNames, outputName+OutputNameSuffixManifest)
+	}
+
+	if f.Properties.CommitTo != nil {
+		outputNames = append(outputNames, outputName+OutputNameSuffixCommitSha, outputName+OutputNameSuffixPullRequestUrl)
+	}
+
+	return outputNames
+}
+
+// OutputEnv returns the custom name of the environment variable the field's value is
+// exported to, or an empty string if there is none.
+func (f *Field) OutputEnv() string {
+	if f.Properties.Output == nil {
+		return ""
+	}
+
+	return f.Properties.Output.Env
 }
 
 // MarshalStringIntoValidFieldsStruct takes a YAML-formatted string representation of a Fields
@@ -119,7 +362,363 @@ func MarshalStringIntoValidFieldsStruct(fieldsString string, action *githubactio
 
 		// add the field label to the detected field labels
 		detectedFieldLabels = append(detectedFieldLabels, field.Label)
+
+		// make sure the content schema (if provided) is valid
+		if field.Properties.ContentSchema != nil {
+			err = validateContentSchema(fields.Fields[i], action)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// make sure the output format (if provided) is valid
+		fields.Fields[i].Properties.OutputFormat = toolbox.StringStandardisedToLower(field.Properties.OutputFormat)
+		if fields.Fields[i].Properties.OutputFormat != "" && !toolbox.StringInSlice(fields.Fields[i].Properties.OutputFormat, ValidOutputFormats) {
+			action.Errorf(
+				"Invalid output format '%s' provided for field '%s'. Valid output formats are: %s",
+				field.Properties.OutputFormat,
+				field.Label,
+				strings.Join(ValidOutputFormats, ", "),
+			)
+			return nil, errors.ErrInvalidOutputFormatProvided
+		}
+
+		if utf8.RuneCountInString(field.Properties.OutputDelimiter) > 1 {
+			action.Errorf("Invalid output delimiter '%s' provided for field '%s', it must be a single character", field.Properties.OutputDelimiter, field.Label)
+			return nil, errors.ErrInvalidOutputFormatProvided
+		}
+
+		// make sure the escaped outputs (if provided) are valid
+		for j, escapedOutput := range field.Properties.EscapedOutputs {
+			fields.Fields[i].Properties.EscapedOutputs[j] = toolbox.StringStandardisedToLower(escapedOutput)
+			if !toolbox.StringInSlice(fields.Fields[i].Properties.EscapedOutputs[j], ValidEscapedOutputs) {
+				action.Errorf(
+					"Invalid escaped output '%s' provided for field '%s'. Valid escaped outputs are: %s",
+					escapedOutput,
+					field.Label,
+					strings.Join(ValidEscapedOutputs, ", "),
+				)
+				return nil, errors.ErrInvalidEscapedOutputProvided
+			}
+		}
+
+		// make sure the allowed characters (if provided) are valid
+		if field.Properties.AllowedCharacters != "" {
+			if !toolbox.StringInSlice(fields.Fields[i].Properties.Type, AllowedCharactersFieldTypes) {
+				action.Errorf("Allowed characters provided for field '%s', but they are only supported on %s fields", field.Label, strings.Join(AllowedCharactersFieldTypes, " and "))
+				return nil, errors.ErrInvalidAllowedCharactersProvided
+			}
+
+			if _, err := fields.Fields[i].Properties.AllowedCharactersPattern(); err != nil {
+				action.Errorf("Invalid allowed characters '%s' provided for field '%s': %v", field.Properties.AllowedCharacters, field.Label, err)
+				return nil, errors.ErrInvalidAllowedCharactersProvided
+			}
+		}
+
+		// make sure the commit target (if provided) is valid
+		if field.Properties.CommitTo != nil {
+			err = validateCommitTo(fields.Fields[i], action)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// make sure the output (if provided) is valid
+		if field.Properties.Output != nil {
+			err = validateOutput(fields.Fields[i], action)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	err = validateOutputNamesAreUnique(fields.Fields, action)
+	if err != nil {
+		return nil, err
 	}
 
 	return &fields, nil
 }
+
+// validateOutput checks that the custom output names of the given field can be used as
+// output and environment variable names, and that its transforms are supported.
+func validateOutput(field Field, action *githubactions.Action) error {
+	output := field.Properties.Output
+
+	output.Name = strings.TrimSpace(output.Name)
+	if strings.ContainsAny(output.Name, " \t\r\n=") {
+		action.Errorf("Invalid output name '%s' provided for field '%s', it cannot contain whitespace or '='", output.Name, field.Label)
+		return errors.ErrInvalidOutputProvided
+	}
+
+	output.Env = strings.TrimSpace(output.Env)
+	if output.Env != "" && !validOutputEnvName.MatchString(output.Env) {
+		action.Errorf("Invalid output env '%s' provided for field '%s', it can only contain letters, digits and '_', and cannot start with a digit", output.Env, field.Label)
+		return errors.ErrInvalidOutputProvided
+	}
+
+	for i, transform := range output.Transforms {
+		output.Transforms[i].Type = toolbox.StringStandardisedToLower(transform.Type)
+		if !toolbox.StringInSlice(output.Transforms[i].Type, ValidOutputTransformTypes) {
+			action.Errorf(
+				"Invalid output transform '%s' provided for field '%s'. Valid output transforms are: %s",
+				transform.Type,
+				field.Label,
+				strings.Join(ValidOutputTransformTypes, ", "),
+			)
+			return errors.ErrInvalidOutputProvided
+		}
+
+		if output.Transforms[i].Type != "replace" {
+			continue
+		}
+
+		if transform.Pattern == "" {
+			action.Errorf("Output transform %d for field '%s' is missing a pattern to replace", i+1, field.Label)
+			return errors.ErrInvalidOutputProvided
+		}
+
+		if _, err := regexp.Compile(transform.Pattern); err != nil {
+			action.Errorf("Invalid pattern provided for output transform %d of field '%s': %v", i+1, field.Label, err)
+			return errors.ErrInvalidOutputProvided
+		}
+	}
+
+	return nil
+}
+
+// validateOutputNamesAreUnique checks that no two fields set the same output, or export to
+// the same environment variable, taking every output each field can set into account.
+func validateOutputNamesAreUnique(fields []Field, action *githubactions.Action) error {
+	outputNameOwners := map[string]string{}
+	envNameOwners := map[string]string{}
+
+	for _, field := range fields {
+		for _, outputName := range field.OutputNames() {
+			if owner, ok := outputNameOwners[outputName]; ok && owner != field.Label {
+				action.Errorf("Output name collision detected: fields '%s' and '%s' both set the '%s' output", owner, field.Label, outputName)
+				return errors.ErrOutputNameCollisionDetected
+			}
+			outputNameOwners[outputName] = field.Label
+		}
+
+		if envName := field.OutputEnv(); envName != "" {
+			if owner, ok := envNameOwners[envName]; ok {
+				action.Errorf("Output env collision detected: fields '%s' and '%s' are both exported to '%s'", owner, field.Label, envName)
+				return errors.ErrOutputNameCollisionDetected
+			}
+			envNameOwners[envName] = field.Label
+		}
+	}
+
+	return nil
+}
+
+// AllowedCharactersPattern returns the expression a value must match to only contain the
+// allowed characters, or nil if the field doesn't restrict its characters.
+func (p FieldProperties) AllowedCharactersPattern() (*regexp.Regexp, error) {
+	if p.AllowedCharacters == "" {
+		return nil, nil
+	}
+
+	expr := `^[` + p.AllowedCharacters + `]*$`
+
+	// make sure the characters can't break out of the character class, i.e. "a-z]|.*|[a"
+	parsed, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+
+	if parsed.Op != syntax.OpConcat || len(parsed.Sub) != 3 || parsed.Sub[1].Op != syntax.OpStar {
+		return nil, errors.ErrInvalidAllowedCharactersProvided
+	}
+
+	switch parsed.Sub[1].Sub[0].Op {
+	case syntax.OpCharClass, syntax.OpLiteral, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+	default:
+		return nil, errors.ErrInvalidAllowedCharactersProvided
+	}
+
+	return regexp.Compile(expr)
+}
+
+// HasAllowedCharacters returns whether the value only contains the characters allowed
+// by the field, which is always the case when the field doesn't restrict its characters.
+func (p FieldProperties) HasAllowedCharacters(value string) bool {
+	pattern, err := p.AllowedCharactersPattern()
+	if err != nil || pattern == nil {
+		return err == nil
+	}
+
+	return pattern.MatchString(value)
+}
+
+// validateContentSchema checks that the content schema of the given field is only
+// set on file based fields and that its format and column definitions are supported.
+func validateContentSchema(field Field, action *githubactions.Action) error {
+	contentSchema := field.Properties.ContentSchema
+
+	if field.Properties.Type != "file" && field.Properties.Type != "multifile" {
+		action.Errorf("Content schema provided for field '%s', but it is only supported on file and multifile fields", field.Label)
+		return errors.ErrInvalidContentSchemaProvided
+	}
+
+	contentSchema.Format = toolbox.StringStandardisedToLower(contentSchema.Format)
+	if contentSchema.Format != "" && !toolbox.StringInSlice(contentSchema.Format, ValidContentSchemaFormats) {
+		action.Errorf(
+			"Invalid content schema format '%s' provided for field '%s'. Valid formats are: %s",
+			contentSchema.Format,
+			field.Label,
+			strings.Join(ValidContentSchemaFormats, ", "),
+		)
+		return errors.ErrInvalidContentSchemaProvided
+	}
+
+	for i, column := range contentSchema.Columns {
+		if column.Name == "" {
+			action.Errorf("Content schema column %d for field '%s' is missing a name", i+1, field.Label)
+			return errors.ErrInvalidContentSchemaProvided
+		}
+
+		contentSchema.Columns[i].Type = toolbox.StringStandardisedToLower(column.Type)
+		if contentSchema.Columns[i].Type == "" {
+			contentSchema.Columns[i].Type = "string"
+		}
+
+		if !toolbox.StringInSlice(contentSchema.Columns[i].Type, ValidContentSchemaColumnTypes) {
+			action.Errorf(
+				"Invalid content schema column type '%s' provided for column '%s' of field '%s'. Valid column types are: %s",
+				column.Type,
+				column.Name,
+				field.Label,
+				strings.Join(ValidContentSchemaColumnTypes, ", "),
+			)
+			return errors.ErrInvalidContentSchemaProvided
+		}
+
+		if column.Pattern != "" {
+			if _, err := regexp.Compile(column.Pattern); err != nil {
+				action.Errorf("Invalid pattern provided for content schema column '%s' of field '%s': %v", column.Name, field.Label, err)
+				return errors.ErrInvalidContentSchemaProvided
+			}
+		}
+	}
+
+	if contentSchema.PreviewRows < 0 {
+		action.Errorf("Invalid content schema preview rows '%d' provided for field '%s'", contentSchema.PreviewRows, field.Label)
+		return errors.ErrInvalidContentSchemaProvided
+	}
+
+	// a keyword the validator doesn't support would otherwise be ignored, letting through
+	// anything it was meant to reject
+	if contentSchema.JsonSchema != nil {
+		if err := validateJsonSchema(contentSchema.JsonSchema, "jsonSchema"); err != nil {
+			action.Errorf("Invalid content schema provided for field '%s': %v", field.Label, err)
+			return errors.ErrInvalidContentSchemaProvided
+		}
+	}
+
+	return nil
+}
+
+// validateJsonSchema checks that the JSON Schema (found at the given path) only uses the
+// keywords the content schema validator supports, and that its patterns compile
+func validateJsonSchema(schema map[interface{}]interface{}, schemaPath string) error {
+	keywords := map[string]interface{}{}
+	for rawKeyword, value := range schema {
+		keywords[fmt.Sprintf("%v", rawKeyword)] = value
+	}
+
+	// checked in order, so the same keyword is always reported first
+	sortedKeywords := make([]string, 0, len(keywords))
+	for keyword := range keywords {
+		sortedKeywords = append(sortedKeywords, keyword)
+	}
+	sort.Strings(sortedKeywords)
+
+	for _, keyword := range sortedKeywords {
+		value := keywords[keyword]
+		keywordPath := fmt.Sprintf("%s.%s", schemaPath, keyword)
+
+		if !toolbox.StringInSlice(keyword, SupportedJsonSchemaKeywords) {
+			return fmt.Errorf("%s is not a supported JSON Schema keyword, supported keywords are: %s", keywordPath, strings.Join(SupportedJsonSchemaKeywords, ", "))
+		}
+
+		switch keyword {
+		case "pattern":
+			pattern, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("%s must be a string", keywordPath)
+			}
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("%s '%s' is invalid: %v", keywordPath, pattern, err)
+			}
+
+		case "items":
+			itemSchema, ok := value.(map[interface{}]interface{})
+			if !ok {
+				return fmt.Errorf("%s must be a schema", keywordPath)
+			}
+			if err := validateJsonSchema(itemSchema, keywordPath); err != nil {
+				return err
+			}
+
+		case "additionalProperties":
+			if _, ok := value.(bool); ok {
+				continue
+			}
+			additionalSchema, ok := value.(map[interface{}]interface{})
+			if !ok {
+				return fmt.Errorf("%s must be a boolean or a schema", keywordPath)
+			}
+			if err := validateJsonSchema(additionalSchema, keywordPath); err != nil {
+				return err
+			}
+
+		case "properties":
+			properties, ok := value.(map[interface{}]interface{})
+			if !ok {
+				return fmt.Errorf("%s must map property names to schemas", keywordPath)
+			}
+			for name, rawPropertySchema := range properties {
+				propertyPath := fmt.Sprintf("%s.%v", keywordPath, name)
+				propertySchema, ok := rawPropertySchema.(map[interface{}]interface{})
+				if !ok {
+					return fmt.Errorf("%s must be a schema", propertyPath)
+				}
+				if err := validateJsonSchema(propertySchema, propertyPath); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateCommitTo checks that the commit target of the given field is only used on file
+// fields and has a branch to commit to, standardising its path along the way
+func validateCommitTo(field Field, action *githubactions.Action) error {
+	commitTo := field.Properties.CommitTo
+
+	if field.Properties.Type != "file" && field.Properties.Type != "multifile" {
+		action.Errorf("Commit target provided for field '%s', but it is only supported on file and multifile fields", field.Label)
+		return errors.ErrInvalidCommitToProvided
+	}
+
+	commitTo.Branch = strings.TrimSpace(commitTo.Branch)
+	if commitTo.Branch == "" {
+		action.Errorf("Commit target for field '%s' is missing a branch", field.Label)
+		return errors.ErrInvalidCommitToProvided
+	}
+
+	commitTo.Base = strings.TrimSpace(commitTo.Base)
+	if commitTo.PullRequest != nil && commitTo.Base == commitTo.Branch {
+		action.Errorf("Commit target for field '%s' cannot open a pull request from '%s' into itself", field.Label, commitTo.Branch)
+		return errors.ErrInvalidCommitToProvided
+	}
+
+	commitTo.Path = strings.Trim(path.Clean("/"+strings.TrimSpace(commitTo.Path)), "/")
+
+	return nil
+}
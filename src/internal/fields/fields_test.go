@@ -80,6 +80,199 @@ func TestMarshalStringIntoValidFieldsStruct(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:          "success - content schema on file field",
+			fieldsString:  "fields:\n  - label: users\n    properties:\n      type: file\n      contentSchema:\n        format: CSV\n        columns:\n          - name: email\n            required: true\n",
+			expectedError: false,
+			expectedField: &fields.Fields{
+				Fields: []fields.Field{
+					{
+						Label: "users",
+						Properties: fields.FieldProperties{
+							Type: "file",
+							ContentSchema: &fields.ContentSchema{
+								Format: "csv",
+								Columns: []fields.ContentSchemaColumn{
+									{Name: "email", Type: "string", Required: true},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedOutput: "",
+		},
+		{
+			name:           "Content schema on non-file field",
+			fieldsString:   "fields:\n  - label: name\n    properties:\n      type: text\n      contentSchema:\n        format: csv\n",
+			expectedError:  true,
+			expectedOutput: "::error::Content schema provided for field 'name', but it is only supported on file and multifile fields\n",
+		},
+		{
+			name:           "Content schema with invalid column type",
+			fieldsString:   "fields:\n  - label: users\n    properties:\n      type: file\n      contentSchema:\n        columns:\n          - name: age\n            type: date\n",
+			expectedError:  true,
+			expectedOutput: "::error::Invalid content schema column type 'date' provided for column 'age' of field 'users'. Valid column types are: string, integer, number, boolean\n",
+		},
+		{
+			name:           "Content schema with an unsupported JSON Schema keyword",
+			fieldsString:   "fields:\n  - label: config\n    properties:\n      type: file\n      contentSchema:\n        format: json\n        jsonSchema:\n          type: object\n          oneOf:\n            - required: [name]\n",
+			expectedError:  true,
+			expectedOutput: "::error::Invalid content schema provided for field 'config': jsonSchema.oneOf is not a supported JSON Schema keyword, supported keywords are: $schema, $id, $comment, title, description, default, examples, type, enum, const, required, properties, additionalProperties, items, minItems, maxItems, minLength, maxLength, pattern, minimum, maximum\n",
+		},
+		{
+			name:           "Content schema with an unsupported nested JSON Schema keyword",
+			fieldsString:   "fields:\n  - label: config\n    properties:\n      type: file\n      contentSchema:\n        format: json\n        jsonSchema:\n          type: object\n          properties:\n            replicas:\n              type: integer\n              exclusiveMinimum: 0\n",
+			expectedError:  true,
+			expectedOutput: "::error::Invalid content schema provided for field 'config': jsonSchema.properties.replicas.exclusiveMinimum is not a supported JSON Schema keyword, supported keywords are: $schema, $id, $comment, title, description, default, examples, type, enum, const, required, properties, additionalProperties, items, minItems, maxItems, minLength, maxLength, pattern, minimum, maximum\n",
+		},
+		{
+			name:           "Content schema with an invalid JSON Schema pattern",
+			fieldsString:   "fields:\n  - label: config\n    properties:\n      type: file\n      contentSchema:\n        format: json\n        jsonSchema:\n          type: array\n          items:\n            type: string\n            pattern: '^[a-z'\n",
+			expectedError:  true,
+			expectedOutput: "::error::Invalid content schema provided for field 'config': jsonSchema.items.pattern '^[a-z' is invalid: error parsing regexp: missing closing ]: `[a-z`\n",
+		},
+		{
+			name:          "success - commit target on multifile field",
+			fieldsString:  "fields:\n  - label: certificates\n    properties:\n      type: multifile\n      commitTo:\n        path: /certs/live/\n        branch: update-certificates\n        pullRequest:\n          title: Rotate certificates\n",
+			expectedError: false,
+			expectedField: &fields.Fields{
+				Fields: []fields.Field{
+					{
+						Label: "certificates",
+						Properties: fields.FieldProperties{
+							Type: "multifile",
+							CommitTo: &fields.CommitTo{
+								Path:        "certs/live",
+								Branch:      "update-certificates",
+								PullRequest: &fields.CommitToPullRequest{Title: "Rotate certificates"},
+							},
+						},
+					},
+				},
+			},
+			expectedOutput: "",
+		},
+		{
+			name:           "Commit target without branch",
+			fieldsString:   "fields:\n  - label: certificates\n    properties:\n      type: file\n      commitTo:\n        path: certs\n",
+			expectedError:  true,
+			expectedOutput: "::error::Commit target for field 'certificates' is missing a branch\n",
+		},
+		{
+			name:           "Commit target opening pull request into itself",
+			fieldsString:   "fields:\n  - label: certificates\n    properties:\n      type: file\n      commitTo:\n        branch: main\n        base: main\n        pullRequest:\n          draft: true\n",
+			expectedError:  true,
+			expectedOutput: "::error::Commit target for field 'certificates' cannot open a pull request from 'main' into itself\n",
+		},
+		{
+			name:           "Invalid output format",
+			fieldsString:   "fields:\n  - label: regions\n    properties:\n      type: multiselect\n      choices: [eu-west-1, us-east-1]\n      outputFormat: xml\n",
+			expectedError:  true,
+			expectedOutput: "::error::Invalid output format 'xml' provided for field 'regions'. Valid output formats are: csv, json, newline\n",
+		},
+		{
+			name:          "success - escaped outputs and allowed characters",
+			fieldsString:  "fields:\n  - label: branch\n    properties:\n      type: text\n      escapedOutputs: [Shell, json]\n      allowedCharacters: a-z0-9/._-\n",
+			expectedError: false,
+			expectedField: &fields.Fields{
+				Fields: []fields.Field{
+					{
+						Label: "branch",
+						Properties: fields.FieldProperties{
+							Type:              "text",
+							EscapedOutputs:    []string{"shell", "json"},
+							AllowedCharacters: "a-z0-9/._-",
+						},
+					},
+				},
+			},
+			expectedOutput: "",
+		},
+		{
+			name:           "Invalid escaped output",
+			fieldsString:   "fields:\n  - label: branch\n    properties:\n      type: text\n      escapedOutputs: [powershell]\n",
+			expectedError:  true,
+			expectedOutput: "::error::Invalid escaped output 'powershell' provided for field 'branch'. Valid escaped outputs are: shell, json\n",
+		},
+		{
+			name:           "Allowed characters on unsupported field",
+			fieldsString:   "fields:\n  - label: replicas\n    properties:\n      type: number\n      allowedCharacters: 0-9\n",
+			expectedError:  true,
+			expectedOutput: "::error::Allowed characters provided for field 'replicas', but they are only supported on text and textarea fields\n",
+		},
+		{
+			name:           "Allowed characters breaking out of the character class",
+			fieldsString:   "fields:\n  - label: branch\n    properties:\n      type: text\n      allowedCharacters: \"a-z]|.*|[a\"\n",
+			expectedError:  true,
+			expectedOutput: "::error::Invalid allowed characters 'a-z]|.*|[a' provided for field 'branch': InvalidAllowedCharactersProvided\n",
+		},
+		{
+			name:          "success - custom output name, env and transforms",
+			fieldsString:  "fields:\n  - label: environment\n    properties:\n      type: text\n      output:\n        name: \" DEPLOY_ENV \"\n        env: DEPLOY_ENV\n        transforms:\n          - type: Trim\n          - type: replace\n            pattern: \"[^a-z]+\"\n            replacement: \"-\"\n",
+			expectedError: false,
+			expectedField: &fields.Fields{
+				Fields: []fields.Field{
+					{
+						Label: "environment",
+						Properties: fields.FieldProperties{
+							Type: "text",
+							Output: &fields.Output{
+								Name: "DEPLOY_ENV",
+								Env:  "DEPLOY_ENV",
+								Transforms: []fields.OutputTransform{
+									{Type: "trim"},
+									{Type: "replace", Pattern: "[^a-z]+", Replacement: "-"},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedOutput: "",
+		},
+		{
+			name:           "Invalid output transform",
+			fieldsString:   "fields:\n  - label: environment\n    properties:\n      type: text\n      output:\n        transforms:\n          - type: reverse\n",
+			expectedError:  true,
+			expectedOutput: "::error::Invalid output transform 'reverse' provided for field 'environment'. Valid output transforms are: trim, lower, upper, replace, prefix, suffix\n",
+		},
+		{
+			name:           "Invalid output env",
+			fieldsString:   "fields:\n  - label: environment\n    properties:\n      type: text\n      output:\n        env: deploy-env\n",
+			expectedError:  true,
+			expectedOutput: "::error::Invalid output env 'deploy-env' provided for field 'environment', it can only contain letters, digits and '_', and cannot start with a digit\n",
+		},
+		{
+			name:           "Output name collides with another field's label",
+			fieldsString:   "fields:\n  - label: environment\n    properties:\n      type: text\n  - label: region\n    properties:\n      type: text\n      output:\n        name: environment\n",
+			expectedError:  true,
+			expectedOutput: "::error::Output name collision detected: fields 'environment' and 'region' both set the 'environment' output\n",
+		},
+		{
+			name:           "Output name collides with another field's escaped output",
+			fieldsString:   "fields:\n  - label: notes\n    properties:\n      type: textarea\n      escapedOutputs: [shell]\n  - label: summary\n    properties:\n      type: text\n      output:\n        name: notes-shell\n",
+			expectedError:  true,
+			expectedOutput: "::error::Output name collision detected: fields 'notes' and 'summary' both set the 'notes-shell' output\n",
+		},
+		{
+			name:           "Output name collides with another field's spill file output",
+			fieldsString:   "fields:\n  - label: notes\n    properties:\n      type: textarea\n  - label: notes-file\n    properties:\n      type: text\n",
+			expectedError:  true,
+			expectedOutput: "::error::Output name collision detected: fields 'notes' and 'notes-file' both set the 'notes-file' output\n",
+		},
+		{
+			name:           "Output name collides with another field's manifest output",
+			fieldsString:   "fields:\n  - label: report\n    properties:\n      type: file\n  - label: summary\n    properties:\n      type: text\n      output:\n        name: report-manifest\n",
+			expectedError:  true,
+			expectedOutput: "::error::Output name collision detected: fields 'report' and 'summary' both set the 'report-manifest' output\n",
+		},
+		{
+			name:           "Output name collides with another field's commit outputs",
+			fieldsString:   "fields:\n  - label: summary\n    properties:\n      type: text\n      output:\n        name: report-pr-url\n  - label: report\n    properties:\n      type: file\n      commitTo:\n        path: reports\n        branch: reports\n",
+			expectedError:  true,
+			expectedOutput: "::error::Output name collision detected: fields 'summary' and 'report' both set the 'report-pr-url' output\n",
+		},
 		{
 			name:          "Empty string",
 			fieldsString:  "",
@@ -129,3 +322,25 @@ func TestMarshalStringIntoValidFieldsStruct(t *testing.T) {
 		})
 	}
 }
+
+func TestFieldProperties_HasAllowedCharacters(t *testing.T) {
+	tests := []struct {
+		name              string
+		allowedCharacters string
+		value             string
+		expected          bool
+	}{
+		{name: "no policy", value: "$(rm -rf /)", expected: true},
+		{name: "allowed", allowedCharacters: "a-z0-9/._-", value: "feature/add-thing_1.2", expected: true},
+		{name: "command substitution", allowedCharacters: "a-z0-9/._-", value: "main$(id)", expected: false},
+		{name: "newline not allowed", allowedCharacters: "a-z ", value: "one\ntwo", expected: false},
+		{name: "empty value", allowedCharacters: "a-z", value: "", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			properties := fields.FieldProperties{AllowedCharacters: tt.allowedCharacters}
+			assert.Equal(t, tt.expected, properties.HasAllowedCharacters(tt.value))
+		})
+	}
+}
@@ -0,0 +1,373 @@
+package github
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/sethvargo/go-githubactions"
+)
+
+const (
+	// DefaultApiUrl is the default base URL of the GitHub API
+	DefaultApiUrl = "https://api.github.com"
+
+	// blobFileMode is the file mode files are committed with
+	blobFileMode = "100644"
+)
+
+// NewClientRequest is the request object for creating a new instance of a GitHub Client
+type NewClientRequest struct {
+
+	// ApiUrl is the base URL of the GitHub API, i.e. https://api.github.com
+	ApiUrl string
+
+	// Token is the token used to authenticate with the GitHub API
+	Token string
+
+	// ActionPkg represents the githubactions package
+	ActionPkg *githubactions.Action
+}
+
+// NewClient returns a new instance of a GitHub Client
+func NewClient(r *NewClientRequest) *Client {
+
+	var apiUrl string = DefaultApiUrl
+
+	if r.ApiUrl != "" {
+		apiUrl = strings.TrimSuffix(r.ApiUrl, "/")
+	}
+
+	return &Client{
+		apiUrl:     apiUrl,
+		token:      r.Token,
+		action:     r.ActionPkg,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Client is a minimal client for the parts of the GitHub API used to commit
+// files and open pull requests
+type Client struct {
+
+	// apiUrl is the base URL of the GitHub API
+	apiUrl string
+
+	// token is the token used to authenticate with the GitHub API
+	token string
+
+	// action represents the githubactions package
+	action *githubactions.Action
+
+	// httpClient is the client used to make the requests
+	httpClient *http.Client
+}
+
+// ApiError is returned when the GitHub API responds with an unexpected status code
+type ApiError struct {
+
+	// Method is the method of the failed request
+	Method string
+
+	// Path is the path of the failed request
+	Path string
+
+	// StatusCode is the status code the GitHub API responded with
+	StatusCode int
+
+	// Message is the error message the GitHub API responded with
+	Message string
+}
+
+// Error returns the description of the error
+func (e *ApiError) Error() string {
+	return fmt.Sprintf("%s: %s %s responded with %d %s", errors.ErrUnexpectedGithubApiResponse, e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// Unwrap returns the underlying error, so the error can be matched with errors.Is
+func (e *ApiError) Unwrap() error {
+	return errors.ErrUnexpectedGithubApiResponse
+}
+
+// isNotFound returns whether the error is the GitHub API responding with not found
+func isNotFound(err error) bool {
+	var apiErr *ApiError
+	return goerrors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// CommitFile represents a file to commit
+type CommitFile struct {
+
+	// Path is the path of the file within the repository
+	Path string
+
+	// Content is the content of the file
+	Content []byte
+}
+
+// CommitFilesRequest holds everything needed to commit files to a branch
+type CommitFilesRequest struct {
+
+	// Owner is the owner of the repository
+	Owner string
+
+	// Repo is the name of the repository
+	Repo string
+
+	// Branch is the branch the files are committed to, it is created from the
+	// base branch if it does not exist
+	Branch string
+
+	// Base is the branch the branch is created from, defaults to the repository's
+	// default branch
+	Base string
+
+	// Message is the commit message
+	Message string
+
+	// Files is the list of files to commit
+	Files []CommitFile
+}
+
+// CommitFilesResponse holds the result of committing files to a branch
+type CommitFilesResponse struct {
+
+	// CommitSha is the SHA of the created commit
+	CommitSha string
+
+	// Branch is the branch the files were committed to
+	Branch string
+
+	// Base is the branch the branch was (or would have been) created from
+	Base string
+}
+
+// OpenPullRequestRequest holds everything needed to open a pull request
+type OpenPullRequestRequest struct {
+
+	// Owner is the owner of the repository
+	Owner string
+
+	// Repo is the name of the repository
+	Repo string
+
+	// Title is the title of the pull request
+	Title string
+
+	// Body is the description of the pull request
+	Body string
+
+	// Head is the branch the changes are on
+	Head string
+
+	// Base is the branch the changes are pulled into
+	Base string
+
+	// Draft is whether the pull request is opened as a draft
+	Draft bool
+}
+
+// CommitFiles commits the files to the branch in a single commit using the Git Data API,
+// creating the branch from the base branch if it does not exist
+func (c *Client) CommitFiles(r *CommitFilesRequest) (*CommitFilesResponse, error) {
+
+	repoPath := fmt.Sprintf("/repos/%s/%s", url.PathEscape(r.Owner), url.PathEscape(r.Repo))
+	base := r.Base
+
+	if base == "" {
+		var repository RepositoryResponse
+		if err := c.do(http.MethodGet, repoPath, nil, &repository); err != nil {
+			return nil, err
+		}
+		base = repository.DefaultBranch
+	}
+
+	// find the commit the new commit is built on top of, creating the branch
+	// from the base branch if it does not exist
+	var branchRef RefResponse
+	err := c.do(http.MethodGet, fmt.Sprintf("%s/git/ref/heads/%s", repoPath, r.Branch), nil, &branchRef)
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+
+	if err != nil {
+		c.action.Debugf("Branch %s not found, creating it from %s", r.Branch, base)
+
+		var baseRef RefResponse
+		if err := c.do(http.MethodGet, fmt.Sprintf("%s/git/ref/heads/%s", repoPath, base), nil, &baseRef); err != nil {
+			return nil, err
+		}
+
+		err = c.do(http.MethodPost, repoPath+"/git/refs", &CreateRefRequest{
+			Ref: "refs/heads/" + r.Branch,
+			Sha: baseRef.Object.Sha,
+		}, &branchRef)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	parentSha := branchRef.Object.Sha
+
+	var parentCommit CommitResponse
+	if err := c.do(http.MethodGet, fmt.Sprintf("%s/git/commits/%s", repoPath, parentSha), nil, &parentCommit); err != nil {
+		return nil, err
+	}
+
+	// upload the files as blobs and build the tree
+	treeEntries := []TreeEntry{}
+	for _, file := range r.Files {
+		var blob ShaResponse
+		err := c.do(http.MethodPost, repoPath+"/git/blobs", &CreateBlobRequest{
+			Content:  base64.StdEncoding.EncodeToString(file.Content),
+			Encoding: "base64",
+		}, &blob)
+		if err != nil {
+			return nil, err
+		}
+
+		treeEntries = append(treeEntries, TreeEntry{
+			Path: file.Path,
+			Mode: blobFileMode,
+			Type: "blob",
+			Sha:  blob.Sha,
+		})
+	}
+
+	var tree ShaResponse
+	err = c.do(http.MethodPost, repoPath+"/git/trees", &CreateTreeRequest{
+		BaseTree: parentCommit.Tree.Sha,
+		Tree:     treeEntries,
+	}, &tree)
+	if err != nil {
+		return nil, err
+	}
+
+	var commit ShaResponse
+	err = c.do(http.MethodPost, repoPath+"/git/commits", &CreateCommitRequest{
+		Message: r.Message,
+		Tree:    tree.Sha,
+		Parents: []string{parentSha},
+	}, &commit)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.do(http.MethodPatch, fmt.Sprintf("%s/git/refs/heads/%s", repoPath, r.Branch), &UpdateRefRequest{
+		Sha: commit.Sha,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.action.Debugf("Successfully committed %d file(s) to %s (%s)", len(r.Files), r.Branch, commit.Sha)
+
+	return &CommitFilesResponse{
+		CommitSha: commit.Sha,
+		Branch:    r.Branch,
+		Base:      base,
+	}, nil
+}
+
+// OpenPullRequest opens a pull request from the head branch into the base branch and
+// returns its URL. If a pull request is already open for the branches, its URL is returned
+func (c *Client) OpenPullRequest(r *OpenPullRequestRequest) (string, error) {
+
+	repoPath := fmt.Sprintf("/repos/%s/%s", url.PathEscape(r.Owner), url.PathEscape(r.Repo))
+
+	query := url.Values{}
+	query.Set("state", "open")
+	query.Set("head", r.Owner+":"+r.Head)
+	query.Set("base", r.Base)
+
+	var existingPullRequests []PullRequestResponse
+	if err := c.do(http.MethodGet, repoPath+"/pulls?"+query.Encode(), nil, &existingPullRequests); err != nil {
+		return "", err
+	}
+
+	if len(existingPullRequests) > 0 {
+		c.action.Debugf("Pull request #%d is already open for %s", existingPullRequests[0].Number, r.Head)
+		return existingPullRequests[0].HtmlUrl, nil
+	}
+
+	var pullRequest PullRequestResponse
+	err := c.do(http.MethodPost, repoPath+"/pulls", &CreatePullRequestRequest{
+		Title: r.Title,
+		Body:  r.Body,
+		Head:  r.Head,
+		Base:  r.Base,
+		Draft: r.Draft,
+	}, &pullRequest)
+	if err != nil {
+		return "", err
+	}
+
+	c.action.Debugf("Successfully opened pull request #%d", pullRequest.Number)
+
+	return pullRequest.HtmlUrl, nil
+}
+
+// do makes the request to the GitHub API, encoding the body (if any) as JSON and decoding
+// the response into the target (if any)
+func (c *Client) do(method, path string, body interface{}, target interface{}) error {
+
+	var requestBody io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		requestBody = bytes.NewReader(bodyBytes)
+	}
+
+	request, err := http.NewRequest(method, c.apiUrl+path, requestBody)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Add("Accept", "application/vnd.github+json")
+	request.Header.Add("Authorization", "Bearer "+c.token)
+	request.Header.Add("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		request.Header.Add("Content-Type", "application/json")
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		c.action.Errorf("An error occured while making call to the GitHub API. Error: %v", err)
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		var errorResponse ErrorResponse
+		json.NewDecoder(response.Body).Decode(&errorResponse)
+
+		return &ApiError{
+			Method:     method,
+			Path:       path,
+			StatusCode: response.StatusCode,
+			Message:    errorResponse.Message,
+		}
+	}
+
+	if target == nil {
+		return nil
+	}
+
+	err = json.NewDecoder(response.Body).Decode(target)
+	if err != nil {
+		c.action.Errorf("Unexpected error while decoding GitHub API response. Error: %v", err)
+		return err
+	}
+
+	return nil
+}
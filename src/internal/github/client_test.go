@@ -0,0 +1,231 @@
+package github_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/github"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeGithubApi is a minimal in-memory implementation of the GitHub API endpoints
+// used to commit files and open pull requests
+type fakeGithubApi struct {
+	mutex        sync.Mutex
+	refs         map[string]string
+	blobs        map[string]string
+	commits      []github.CreateCommitRequest
+	pullRequests []github.CreatePullRequestRequest
+}
+
+func startFakeGithubApi(t *testing.T) (*fakeGithubApi, string) {
+	api := &fakeGithubApi{
+		refs:  map[string]string{"main": "base-commit-sha"},
+		blobs: map[string]string{},
+	}
+
+	router := http.NewServeMux()
+	router.HandleFunc("GET /repos/boasihq/interactive-inputs", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"default_branch": "main"})
+	})
+	router.HandleFunc("GET /repos/boasihq/interactive-inputs/git/ref/heads/{branch...}", func(w http.ResponseWriter, r *http.Request) {
+		api.mutex.Lock()
+		defer api.mutex.Unlock()
+
+		sha, ok := api.refs[r.PathValue("branch")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"message": "Not Found"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"object": map[string]string{"sha": sha}})
+	})
+	router.HandleFunc("POST /repos/boasihq/interactive-inputs/git/refs", func(w http.ResponseWriter, r *http.Request) {
+		var request github.CreateRefRequest
+		json.NewDecoder(r.Body).Decode(&request)
+
+		api.mutex.Lock()
+		api.refs[request.Ref[len("refs/heads/"):]] = request.Sha
+		api.mutex.Unlock()
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"ref": request.Ref, "object": map[string]string{"sha": request.Sha}})
+	})
+	router.HandleFunc("PATCH /repos/boasihq/interactive-inputs/git/refs/heads/{branch...}", func(w http.ResponseWriter, r *http.Request) {
+		var request github.UpdateRefRequest
+		json.NewDecoder(r.Body).Decode(&request)
+
+		api.mutex.Lock()
+		api.refs[r.PathValue("branch")] = request.Sha
+		api.mutex.Unlock()
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"object": map[string]string{"sha": request.Sha}})
+	})
+	router.HandleFunc("GET /repos/boasihq/interactive-inputs/git/commits/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"sha": r.PathValue("sha"), "tree": map[string]string{"sha": "base-tree-sha"}})
+	})
+	router.HandleFunc("POST /repos/boasihq/interactive-inputs/git/blobs", func(w http.ResponseWriter, r *http.Request) {
+		var request github.CreateBlobRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		content, _ := base64.StdEncoding.DecodeString(request.Content)
+
+		api.mutex.Lock()
+		sha := "blob-sha-" + string(content)
+		api.blobs[sha] = string(content)
+		api.mutex.Unlock()
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"sha": sha})
+	})
+	router.HandleFunc("POST /repos/boasihq/interactive-inputs/git/trees", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"sha": "new-tree-sha"})
+	})
+	router.HandleFunc("POST /repos/boasihq/interactive-inputs/git/commits", func(w http.ResponseWriter, r *http.Request) {
+		var request github.CreateCommitRequest
+		json.NewDecoder(r.Body).Decode(&request)
+
+		api.mutex.Lock()
+		api.commits = append(api.commits, request)
+		api.mutex.Unlock()
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"sha": "new-commit-sha"})
+	})
+	router.HandleFunc("GET /repos/boasihq/interactive-inputs/pulls", func(w http.ResponseWriter, r *http.Request) {
+		api.mutex.Lock()
+		defer api.mutex.Unlock()
+
+		pullRequests := []map[string]interface{}{}
+		for i, pullRequest := range api.pullRequests {
+			if "boasihq:"+pullRequest.Head == r.URL.Query().Get("head") {
+				pullRequests = append(pullRequests, map[string]interface{}{"number": i + 1, "html_url": "https://github.com/boasihq/interactive-inputs/pull/1"})
+			}
+		}
+		json.NewEncoder(w).Encode(pullRequests)
+	})
+	router.HandleFunc("POST /repos/boasihq/interactive-inputs/pulls", func(w http.ResponseWriter, r *http.Request) {
+		var request github.CreatePullRequestRequest
+		json.NewDecoder(r.Body).Decode(&request)
+
+		api.mutex.Lock()
+		api.pullRequests = append(api.pullRequests, request)
+		api.mutex.Unlock()
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"number": 1, "html_url": "https://github.com/boasihq/interactive-inputs/pull/1"})
+	})
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return api, server.URL
+}
+
+func TestClient_CommitFiles(t *testing.T) {
+
+	action := githubactions.New(githubactions.WithWriter(io.Discard))
+
+	tests := []struct {
+		name                 string
+		branch               string
+		base                 string
+		expectedParent       string
+		expectedBase         string
+		expectedCreatedRef   bool
+		expectedErrorMatches error
+	}{
+		{
+			name:               "creates missing branch from default branch",
+			branch:             "update-certificates",
+			expectedParent:     "base-commit-sha",
+			expectedBase:       "main",
+			expectedCreatedRef: true,
+		},
+		{
+			name:           "commits on top of existing branch",
+			branch:         "main",
+			base:           "main",
+			expectedParent: "base-commit-sha",
+			expectedBase:   "main",
+		},
+		{
+			name:                 "fails when base branch does not exist",
+			branch:               "update-certificates",
+			base:                 "does-not-exist",
+			expectedErrorMatches: errors.ErrUnexpectedGithubApiResponse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, apiUrl := startFakeGithubApi(t)
+
+			client := github.NewClient(&github.NewClientRequest{
+				ApiUrl:    apiUrl,
+				Token:     "github-secret-token",
+				ActionPkg: action,
+			})
+
+			response, err := client.CommitFiles(&github.CommitFilesRequest{
+				Owner:   "boasihq",
+				Repo:    "interactive-inputs",
+				Branch:  tt.branch,
+				Base:    tt.base,
+				Message: "Add certificates",
+				Files: []github.CommitFile{
+					{Path: "certs/server.pem", Content: []byte("certificate")},
+				},
+			})
+
+			if tt.expectedErrorMatches != nil {
+				assert.ErrorIs(t, err, tt.expectedErrorMatches)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "new-commit-sha", response.CommitSha)
+			assert.Equal(t, tt.expectedBase, response.Base)
+			assert.Equal(t, "new-commit-sha", api.refs[tt.branch])
+			assert.Equal(t, []string{tt.expectedParent}, api.commits[0].Parents)
+			assert.Equal(t, "certificate", api.blobs["blob-sha-certificate"])
+		})
+	}
+}
+
+func TestClient_OpenPullRequest(t *testing.T) {
+
+	action := githubactions.New(githubactions.WithWriter(io.Discard))
+	api, apiUrl := startFakeGithubApi(t)
+
+	client := github.NewClient(&github.NewClientRequest{
+		ApiUrl:    apiUrl,
+		Token:     "github-secret-token",
+		ActionPkg: action,
+	})
+
+	request := &github.OpenPullRequestRequest{
+		Owner: "boasihq",
+		Repo:  "interactive-inputs",
+		Title: "Add certificates",
+		Head:  "update-certificates",
+		Base:  "main",
+	}
+
+	pullRequestUrl, err := client.OpenPullRequest(request)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://github.com/boasihq/interactive-inputs/pull/1", pullRequestUrl)
+
+	// a second call reuses the pull request that is already open
+	pullRequestUrl, err = client.OpenPullRequest(request)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://github.com/boasihq/interactive-inputs/pull/1", pullRequestUrl)
+	assert.Len(t, api.pullRequests, 1)
+}
@@ -0,0 +1,89 @@
+package github
+
+// CreateRefRequest represents the request for the GitHub API create reference endpoint
+type CreateRefRequest struct {
+
+	// Ref is the fully qualified name of the reference, i.e. refs/heads/main
+	Ref string `json:"ref"`
+
+	// Sha is the SHA the reference will point to
+	Sha string `json:"sha"`
+}
+
+// UpdateRefRequest represents the request for the GitHub API update reference endpoint
+type UpdateRefRequest struct {
+
+	// Sha is the SHA the reference will point to
+	Sha string `json:"sha"`
+
+	// Force is whether the update is forced, even when it is not a fast-forward
+	Force bool `json:"force"`
+}
+
+// CreateBlobRequest represents the request for the GitHub API create blob endpoint
+type CreateBlobRequest struct {
+
+	// Content is the (base64 encoded) content of the blob
+	Content string `json:"content"`
+
+	// Encoding is the encoding of the content, i.e. base64
+	Encoding string `json:"encoding"`
+}
+
+// CreateTreeRequest represents the request for the GitHub API create tree endpoint
+type CreateTreeRequest struct {
+
+	// BaseTree is the SHA of the tree the new tree is built on top of
+	BaseTree string `json:"base_tree"`
+
+	// Tree is the list of entries added to (or replaced in) the base tree
+	Tree []TreeEntry `json:"tree"`
+}
+
+// TreeEntry represents an entry of a git tree
+type TreeEntry struct {
+
+	// Path is the path of the entry within the repository
+	Path string `json:"path"`
+
+	// Mode is the file mode of the entry, i.e. 100644
+	Mode string `json:"mode"`
+
+	// Type is the type of the entry, i.e. blob
+	Type string `json:"type"`
+
+	// Sha is the SHA of the blob
+	Sha string `json:"sha"`
+}
+
+// CreateCommitRequest represents the request for the GitHub API create commit endpoint
+type CreateCommitRequest struct {
+
+	// Message is the commit message
+	Message string `json:"message"`
+
+	// Tree is the SHA of the commit's tree
+	Tree string `json:"tree"`
+
+	// Parents is the list of the commit's parent SHAs
+	Parents []string `json:"parents"`
+}
+
+// CreatePullRequestRequest represents the request for the GitHub API create pull request endpoint
+type CreatePullRequestRequest struct {
+
+	// Title is the title of the pull request
+	Title string `json:"title"`
+
+	// Body is the description of the pull request
+	Body string `json:"body,omitempty"`
+
+	// Head is the branch the changes are on
+	Head string `json:"head"`
+
+	// Base is the branch the changes are pulled into
+	Base string `json:"base"`
+
+	// Draft is whether the pull request is opened as a draft
+	Draft bool `json:"draft"`
+}
@@ -0,0 +1,60 @@
+package github
+
+// RepositoryResponse represents the (relevant parts of the) response of the GitHub API
+// get repository endpoint
+type RepositoryResponse struct {
+
+	// DefaultBranch is the name of the repository's default branch
+	DefaultBranch string `json:"default_branch"`
+}
+
+// RefResponse represents the response of the GitHub API reference endpoints
+type RefResponse struct {
+
+	// Ref is the fully qualified name of the reference
+	Ref string `json:"ref"`
+
+	// Object is the object the reference points to
+	Object struct {
+		Sha string `json:"sha"`
+	} `json:"object"`
+}
+
+// ShaResponse represents the response of the GitHub API endpoints that create git
+// objects (blobs, trees and commits)
+type ShaResponse struct {
+
+	// Sha is the SHA of the created object
+	Sha string `json:"sha"`
+}
+
+// CommitResponse represents the (relevant parts of the) response of the GitHub API
+// get commit endpoint
+type CommitResponse struct {
+
+	// Sha is the SHA of the commit
+	Sha string `json:"sha"`
+
+	// Tree is the tree of the commit
+	Tree struct {
+		Sha string `json:"sha"`
+	} `json:"tree"`
+}
+
+// PullRequestResponse represents the (relevant parts of the) response of the GitHub API
+// pull request endpoints
+type PullRequestResponse struct {
+
+	// Number is the number of the pull request
+	Number int `json:"number"`
+
+	// HtmlUrl is the URL of the pull request
+	HtmlUrl string `json:"html_url"`
+}
+
+// ErrorResponse represents the error response of the GitHub API
+type ErrorResponse struct {
+
+	// Message is the error message
+	Message string `json:"message"`
+}
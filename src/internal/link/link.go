@@ -0,0 +1,207 @@
+package link
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+)
+
+const (
+	// QueryParameterName is the name of the query parameter holding the signed link's token
+	QueryParameterName = "link"
+
+	// SessionCookieName is the name of the cookie holding the recipient of the signed link
+	// the visitor opened the portal with
+	SessionCookieName = "iaip_link"
+
+	// RecipientSlack is the recipient of the link sent to the Slack notifier
+	RecipientSlack = "slack"
+
+	// RecipientDiscord is the recipient of the link sent to the Discord notifier
+	RecipientDiscord = "discord"
+)
+
+// recipientContextKey is the key the recipient of the signed link is stored under in the
+// request context
+type recipientContextKey struct{}
+
+// NewSignerRequest is the request object for creating a new instance of Signer
+type NewSignerRequest struct {
+
+	// Expiry is how long signed links can be opened for after they are created
+	Expiry time.Duration
+
+	// SingleUse is whether each signed link can only be opened once
+	SingleUse bool
+}
+
+// NewSigner returns a new instance of Signer
+func NewSigner(r *NewSignerRequest) *Signer {
+
+	// the key links are signed with only lives as long as the portal, so links can't be
+	// used for any other run
+	key := make([]byte, 32)
+	rand.Read(key)
+
+	return &Signer{
+		expiry:         r.Expiry,
+		singleUse:      r.SingleUse,
+		key:            key,
+		redeemedNonces: make(map[string]bool),
+	}
+}
+
+// Signer creates signed, expiring links to the portal and checks them when they are opened
+type Signer struct {
+
+	// expiry is how long signed links can be opened for after they are created
+	expiry time.Duration
+
+	// singleUse is whether each signed link can only be opened once
+	singleUse bool
+
+	// key is the key links and sessions are signed with
+	key []byte
+
+	// mutex guards the redeemed nonces
+	mutex sync.Mutex
+
+	// redeemedNonces is the set of nonces of the links that have been opened
+	redeemedNonces map[string]bool
+}
+
+// Sign returns a link to the portal's URL for the recipient, which can be opened until
+// it expires
+func (s *Signer) Sign(portalUrl, recipient string) (string, error) {
+	parsedPortalUrl, err := url.Parse(portalUrl)
+	if err != nil {
+		return "", err
+	}
+
+	nonce, err := randomValue()
+	if err != nil {
+		return "", err
+	}
+
+	payload := strings.Join([]string{
+		base64.RawURLEncoding.EncodeToString([]byte(recipient)),
+		strconv.FormatInt(time.Now().Add(s.expiry).Unix(), 10),
+		nonce,
+	}, ".")
+
+	query := parsedPortalUrl.Query()
+	query.Set(QueryParameterName, payload+"."+s.sign("link", payload))
+	parsedPortalUrl.RawQuery = query.Encode()
+
+	return parsedPortalUrl.String(), nil
+}
+
+// Redeem checks the token of a signed link, returning the recipient it was created for.
+// The recipient is also returned for links that have expired or already been opened, so
+// refusing them can be recorded
+func (s *Signer) Redeem(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return "", errors.ErrInvalidSignedLink
+	}
+
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(s.sign("link", payload))) {
+		return "", errors.ErrInvalidSignedLink
+	}
+
+	recipient, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", errors.ErrInvalidSignedLink
+	}
+
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", errors.ErrInvalidSignedLink
+	}
+
+	if time.Now().Unix() > expiresAt {
+		return string(recipient), errors.ErrSignedLinkExpired
+	}
+
+	if s.singleUse {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		if s.redeemedNonces[parts[2]] {
+			return string(recipient), errors.ErrSignedLinkAlreadyUsed
+		}
+		s.redeemedNonces[parts[2]] = true
+	}
+
+	return string(recipient), nil
+}
+
+// NewSession returns the signed value of the cookie that lets the browser that opened
+// the recipient's link keep using the portal
+func (s *Signer) NewSession(recipient string) string {
+	encodedRecipient := base64.RawURLEncoding.EncodeToString([]byte(recipient))
+	return encodedRecipient + "." + s.sign("session", encodedRecipient)
+}
+
+// Recipient returns the recipient of the link the request's browser opened the portal
+// with, or false if the session cookie is missing or its signature doesn't match
+func (s *Signer) Recipient(r *http.Request) (string, bool) {
+	cookie, err := r.Cookie(SessionCookieName)
+	if err != nil {
+		return "", false
+	}
+
+	encodedRecipient, signature, ok := strings.Cut(cookie.Value, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.sign("session", encodedRecipient))) {
+		return "", false
+	}
+
+	recipient, err := base64.RawURLEncoding.DecodeString(encodedRecipient)
+	if err != nil {
+		return "", false
+	}
+
+	return string(recipient), true
+}
+
+// sign returns the signature of the value, the purpose stops a link's signature from
+// being used as a session's and the other way around
+func (s *Signer) sign(purpose, value string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(purpose + ":" + value))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// WithRecipient returns a copy of the context holding the recipient of the signed link
+func WithRecipient(ctx context.Context, recipient string) context.Context {
+	return context.WithValue(ctx, recipientContextKey{}, recipient)
+}
+
+// RecipientFromContext returns the recipient of the signed link held by the context, or
+// an empty string
+func RecipientFromContext(ctx context.Context) string {
+	recipient, _ := ctx.Value(recipientContextKey{}).(string)
+	return recipient
+}
+
+// randomValue returns a random, URL safe value
+func randomValue() (string, error) {
+	randomBytes := make([]byte, 16)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(randomBytes), nil
+}
@@ -0,0 +1,123 @@
+package link_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/link"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigner_Redeem(t *testing.T) {
+
+	tests := []struct {
+		name              string
+		request           link.NewSignerRequest
+		tamper            func(token string) string
+		expectedRecipient string
+		expectedErrors    []error
+	}{
+		{
+			name:              "reusable link",
+			request:           link.NewSignerRequest{Expiry: time.Minute},
+			expectedRecipient: link.RecipientSlack,
+			expectedErrors:    []error{nil, nil},
+		},
+		{
+			name:              "single use link",
+			request:           link.NewSignerRequest{Expiry: time.Minute, SingleUse: true},
+			expectedRecipient: link.RecipientSlack,
+			expectedErrors:    []error{nil, errors.ErrSignedLinkAlreadyUsed},
+		},
+		{
+			name:              "expired link",
+			request:           link.NewSignerRequest{Expiry: -time.Minute},
+			expectedRecipient: link.RecipientSlack,
+			expectedErrors:    []error{errors.ErrSignedLinkExpired},
+		},
+		{
+			name:    "tampered link",
+			request: link.NewSignerRequest{Expiry: time.Minute},
+			tamper: func(token string) string {
+				return "ZGlzY29yZA" + token[len("c2xhY2s"):]
+			},
+			expectedErrors: []error{errors.ErrInvalidSignedLink},
+		},
+		{
+			name:    "malformed link",
+			request: link.NewSignerRequest{Expiry: time.Minute},
+			tamper: func(token string) string {
+				return "not-a-link"
+			},
+			expectedErrors: []error{errors.ErrInvalidSignedLink},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := link.NewSigner(&tt.request)
+
+			signedUrl, err := signer.Sign("https://example.ngrok.app/?theme=dark", link.RecipientSlack)
+			assert.NoError(t, err)
+
+			parsedSignedUrl, err := url.Parse(signedUrl)
+			assert.NoError(t, err)
+			assert.Equal(t, "example.ngrok.app", parsedSignedUrl.Host)
+			assert.Equal(t, "dark", parsedSignedUrl.Query().Get("theme"))
+
+			token := parsedSignedUrl.Query().Get(link.QueryParameterName)
+			if tt.tamper != nil {
+				token = tt.tamper(token)
+			}
+
+			for _, expectedError := range tt.expectedErrors {
+				recipient, err := signer.Redeem(token)
+				assert.ErrorIs(t, err, expectedError)
+				assert.Equal(t, tt.expectedRecipient, recipient)
+			}
+		})
+	}
+}
+
+func TestSigner_Recipient(t *testing.T) {
+
+	signer := link.NewSigner(&link.NewSignerRequest{Expiry: time.Minute})
+	otherSigner := link.NewSigner(&link.NewSignerRequest{Expiry: time.Minute})
+
+	tests := []struct {
+		name              string
+		cookieValue       string
+		expectedRecipient string
+		expectedOk        bool
+	}{
+		{
+			name:              "signed session",
+			cookieValue:       signer.NewSession(link.RecipientDiscord),
+			expectedRecipient: link.RecipientDiscord,
+			expectedOk:        true,
+		},
+		{
+			name:        "session signed for another run",
+			cookieValue: otherSigner.NewSession(link.RecipientDiscord),
+		},
+		{
+			name:        "unsigned session",
+			cookieValue: "ZGlzY29yZA",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.AddCookie(&http.Cookie{Name: link.SessionCookieName, Value: tt.cookieValue})
+
+			recipient, ok := signer.Recipient(r)
+			assert.Equal(t, tt.expectedOk, ok)
+			assert.Equal(t, tt.expectedRecipient, recipient)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const (
+	// EscapeShell quotes the value so that it is a single POSIX shell word
+	EscapeShell = "shell"
+
+	// EscapeJson encodes the value as a JSON string
+	EscapeJson = "json"
+)
+
+// EscapedKey returns the key of the output holding the value escaped in the given way,
+// i.e. <label>-shell
+func EscapedKey(key, escape string) string {
+	return key + "-" + escape
+}
+
+// Escape returns the value escaped in the given way, so that it can be interpolated into
+// a script without being interpreted
+func Escape(escape, value string) string {
+	switch escape {
+	case EscapeShell:
+		return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+	case EscapeJson:
+		// marshalling a string can't fail
+		encodedValue, _ := json.Marshal(value)
+		return string(encodedValue)
+	}
+
+	return value
+}
@@ -0,0 +1,309 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/sethvargo/go-githubactions"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// EnvNameStyleUpperSnake mangles labels into upper snake case, i.e. deploy-env becomes DEPLOY_ENV
+	EnvNameStyleUpperSnake = "upper-snake"
+
+	// EnvNameStyleLowerSnake mangles labels into lower snake case, i.e. deploy-env becomes deploy_env
+	EnvNameStyleLowerSnake = "lower-snake"
+
+	// EnvNameStylePreserve keeps labels as they are
+	EnvNameStylePreserve = "preserve"
+
+	// FileFormatJson writes the typed values as a JSON object
+	FileFormatJson = "json"
+
+	// FileFormatYaml writes the typed values as a YAML mapping
+	FileFormatYaml = "yaml"
+
+	// FileFormatDotenv writes the encoded values as KEY="value" lines
+	FileFormatDotenv = "dotenv"
+
+	// SensitivePolicyExclude leaves sensitive fields out of the exports
+	SensitivePolicyExclude = "exclude"
+
+	// SensitivePolicyMask exports sensitive fields, relying on their values being
+	// masked in the logs
+	SensitivePolicyMask = "mask"
+)
+
+var (
+	// envNameInvalidCharacters matches the characters that are replaced with an underscore
+	// when mangling labels into environment variable names
+	envNameInvalidCharacters = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+	// ValidEnvNameStyles is the list of styles labels can be mangled into environment variable names with
+	ValidEnvNameStyles = []string{EnvNameStyleUpperSnake, EnvNameStyleLowerSnake, EnvNameStylePreserve}
+
+	// ValidFileFormats is the list of formats the results file can be written in
+	ValidFileFormats = []string{FileFormatJson, FileFormatYaml, FileFormatDotenv}
+
+	// ValidSensitivePolicies is the list of ways sensitive fields can be exported
+	ValidSensitivePolicies = []string{SensitivePolicyExclude, SensitivePolicyMask}
+)
+
+// NewExporterRequest is the request object for creating a new instance of Exporter
+type NewExporterRequest struct {
+
+	// Env whether the values are exported to GITHUB_ENV
+	Env bool
+
+	// EnvPrefix is the prefix added to the environment variable names
+	EnvPrefix string
+
+	// EnvNameStyle is how labels are mangled into environment variable names
+	EnvNameStyle string
+
+	// FilePath is the path the results file is written to, if empty no file is written
+	FilePath string
+
+	// FileFormat is the format of the results file, detected from the file extension if empty
+	FileFormat string
+
+	// SensitivePolicy is how sensitive fields are exported
+	SensitivePolicy string
+
+	// ActionPkg represents the githubactions package
+	ActionPkg *githubactions.Action
+}
+
+// NewExporter returns a new instance of Exporter
+func NewExporter(r *NewExporterRequest) *Exporter {
+
+	var envNameStyle string = EnvNameStyleUpperSnake
+	var sensitivePolicy string = SensitivePolicyExclude
+	var fileFormat string = r.FileFormat
+
+	if r.EnvNameStyle != "" {
+		envNameStyle = r.EnvNameStyle
+	}
+
+	if r.SensitivePolicy != "" {
+		sensitivePolicy = r.SensitivePolicy
+	}
+
+	if fileFormat == "" {
+		fileFormat = DetectFileFormat(r.FilePath)
+	}
+
+	return &Exporter{
+		env:             r.Env,
+		envPrefix:       r.EnvPrefix,
+		envNameStyle:    envNameStyle,
+		filePath:        r.FilePath,
+		fileFormat:      fileFormat,
+		sensitivePolicy: sensitivePolicy,
+		action:          r.ActionPkg,
+	}
+}
+
+// Exporter exports the submitted values to GITHUB_ENV and/or a results file
+type Exporter struct {
+
+	// env whether the values are exported to GITHUB_ENV
+	env bool
+
+	// envPrefix is the prefix added to the environment variable names
+	envPrefix string
+
+	// envNameStyle is how labels are mangled into environment variable names
+	envNameStyle string
+
+	// filePath is the path the results file is written to
+	filePath string
+
+	// fileFormat is the format of the results file
+	fileFormat string
+
+	// sensitivePolicy is how sensitive fields are exported
+	sensitivePolicy string
+
+	// action represents the githubactions package
+	action *githubactions.Action
+}
+
+// Enabled returns whether the values are exported anywhere
+func (e *Exporter) Enabled() bool {
+	return e.env || e.filePath != ""
+}
+
+// Export exports the submitted values of the declared fields (keyed by output name), using the
+// encoded values for GITHUB_ENV and dotenv files, and the typed values for JSON and YAML files
+func (e *Exporter) Export(declaredFields []fields.Field, encodedValues map[string]string, typedValues map[string]interface{}) error {
+
+	exportedEncodedValues := map[string]string{}
+	exportedTypedValues := map[string]interface{}{}
+
+	for _, field := range declaredFields {
+		outputName := field.OutputName()
+		if _, ok := encodedValues[outputName]; !ok {
+			continue
+		}
+
+		if field.Properties.Sensitive && e.sensitivePolicy == SensitivePolicyExclude {
+			e.action.Debugf("Not exporting %s as it is sensitive", field.Label)
+			continue
+		}
+
+		// a custom env name is used as is, without the prefix
+		envName := field.OutputEnv()
+		if envName == "" {
+			envName = EnvName(outputName, e.envPrefix, e.envNameStyle)
+		}
+
+		exportedEncodedValues[envName] = encodedValues[outputName]
+		exportedTypedValues[outputName] = typedValues[outputName]
+	}
+
+	if e.env {
+		for _, name := range sortedKeys(exportedEncodedValues) {
+			e.action.SetEnv(name, exportedEncodedValues[name])
+		}
+		e.action.Infof("Exported %d value(s) to GITHUB_ENV", len(exportedEncodedValues))
+	}
+
+	if e.filePath == "" {
+		return nil
+	}
+
+	var content []byte
+	var err error
+
+	switch e.fileFormat {
+	case FileFormatYaml:
+		content, err = yaml.Marshal(exportedTypedValues)
+	case FileFormatDotenv:
+		content = []byte(encodeDotenv(exportedEncodedValues))
+	default:
+		content, err = json.MarshalIndent(exportedTypedValues, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(e.filePath); dir != "" {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return err
+		}
+	}
+
+	err = os.WriteFile(e.filePath, content, 0600)
+	if err != nil {
+		return err
+	}
+
+	e.action.Infof("Exported %d value(s) to %s (%s)", len(exportedTypedValues), e.filePath, e.fileFormat)
+
+	return nil
+}
+
+// EnvNameCollision returns the labels of the first two fields that would be exported to the
+// same environment variable (or dotenv key), and that name, once their output names are
+// mangled in the given style and prefixed with the prefix. Empty strings are returned when
+// every field is exported under a name of its own
+func EnvNameCollision(declaredFields []fields.Field, prefix, style string) (string, string, string) {
+
+	envNameOwners := map[string]string{}
+	for _, field := range declaredFields {
+
+		// a custom env name is used as is, without the prefix
+		envName := field.OutputEnv()
+		if envName == "" {
+			envName = EnvName(field.OutputName(), prefix, style)
+		}
+
+		if owner, ok := envNameOwners[envName]; ok {
+			return owner, field.Label, envName
+		}
+		envNameOwners[envName] = field.Label
+	}
+
+	return "", "", ""
+}
+
+// EnvName returns the environment variable name for the label, mangled in the given style
+// and prefixed with the prefix
+func EnvName(label, prefix, style string) string {
+
+	var name string
+
+	switch style {
+	case EnvNameStylePreserve:
+		name = label
+	case EnvNameStyleLowerSnake:
+		name = strings.ToLower(envNameInvalidCharacters.ReplaceAllString(label, "_"))
+	default:
+		name = strings.ToUpper(envNameInvalidCharacters.ReplaceAllString(label, "_"))
+	}
+
+	return prefix + name
+}
+
+// DetectFileFormat returns the file format matching the extension of the path,
+// defaulting to JSON
+func DetectFileFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FileFormatYaml
+	case ".env":
+		return FileFormatDotenv
+	}
+
+	if strings.HasPrefix(filepath.Base(path), ".env") {
+		return FileFormatDotenv
+	}
+
+	return FileFormatJson
+}
+
+// masker registers values that should be masked in the logs
+type masker interface {
+	AddMask(p string)
+}
+
+// Mask registers every line of the value as a secret, so that it is masked in the logs
+func Mask(action masker, value string) {
+	for _, line := range strings.Split(value, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			action.AddMask(line)
+		}
+	}
+}
+
+// encodeDotenv encodes the values as KEY="value" lines, escaping characters that
+// would otherwise break the quoting
+func encodeDotenv(values map[string]string) string {
+	var content strings.Builder
+
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "$", `\$`)
+	for _, name := range sortedKeys(values) {
+		fmt.Fprintf(&content, "%s=\"%s\"\n", name, escaper.Replace(values[name]))
+	}
+
+	return content.String()
+}
+
+// sortedKeys returns the keys of the map in a stable order
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,159 @@
+package output_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/boasihq/interactive-inputs/internal/output"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvName(t *testing.T) {
+
+	tests := []struct {
+		label        string
+		prefix       string
+		style        string
+		expectedName string
+	}{
+		{label: "deploy-env", expectedName: "DEPLOY_ENV"},
+		{label: "deploy-env", prefix: "II_", style: output.EnvNameStyleUpperSnake, expectedName: "II_DEPLOY_ENV"},
+		{label: "deploy-env", style: output.EnvNameStyleLowerSnake, expectedName: "deploy_env"},
+		{label: "deploy-env", prefix: "x_", style: output.EnvNameStylePreserve, expectedName: "x_deploy-env"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expectedName, func(t *testing.T) {
+			assert.Equal(t, tt.expectedName, output.EnvName(tt.label, tt.prefix, tt.style))
+		})
+	}
+}
+
+func TestEnvNameCollision(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		fields         []fields.Field
+		prefix         string
+		style          string
+		expectedFirst  string
+		expectedSecond string
+		expectedName   string
+	}{
+		{
+			name:   "distinct names",
+			fields: []fields.Field{{Label: "deploy-env"}, {Label: "region"}},
+		},
+		{
+			name:           "output names mangled into the same name",
+			fields:         []fields.Field{{Label: "deploy-env"}, {Label: "region"}, {Label: "target", Properties: fields.FieldProperties{Output: &fields.Output{Name: "deploy_env"}}}},
+			prefix:         "II_",
+			expectedFirst:  "deploy-env",
+			expectedSecond: "target",
+			expectedName:   "II_DEPLOY_ENV",
+		},
+		{
+			name:   "output names kept apart when preserved",
+			fields: []fields.Field{{Label: "deploy-env"}, {Label: "target", Properties: fields.FieldProperties{Output: &fields.Output{Name: "deploy_env"}}}},
+			style:  output.EnvNameStylePreserve,
+		},
+		{
+			name:           "label mangled into a custom env name",
+			fields:         []fields.Field{{Label: "target", Properties: fields.FieldProperties{Output: &fields.Output{Env: "DEPLOY_ENV"}}}, {Label: "deploy-env"}},
+			expectedFirst:  "target",
+			expectedSecond: "deploy-env",
+			expectedName:   "DEPLOY_ENV",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, second, envName := output.EnvNameCollision(tt.fields, tt.prefix, tt.style)
+
+			assert.Equal(t, tt.expectedFirst, first)
+			assert.Equal(t, tt.expectedSecond, second)
+			assert.Equal(t, tt.expectedName, envName)
+		})
+	}
+}
+
+func TestExporter_Export(t *testing.T) {
+
+	declaredFields := []fields.Field{
+		{Label: "replicas", Properties: fields.FieldProperties{Type: "number"}},
+		{Label: "notes", Properties: fields.FieldProperties{Type: "textarea"}},
+		{Label: "api-key", Properties: fields.FieldProperties{Type: "text", Sensitive: true}},
+		{Label: "environment", Properties: fields.FieldProperties{Type: "text", Output: &fields.Output{Name: "DEPLOY_ENV", Env: "TARGET"}}},
+	}
+	encodedValues := map[string]string{"replicas": "3", "notes": "line \"one\"\nline two", "api-key": "s3cr3t", "DEPLOY_ENV": "prod"}
+	typedValues := map[string]interface{}{"replicas": 3, "notes": "line \"one\"\nline two", "api-key": "s3cr3t", "DEPLOY_ENV": "prod"}
+
+	tests := []struct {
+		name            string
+		fileName        string
+		fileFormat      string
+		sensitivePolicy string
+		expectedContent string
+	}{
+		{
+			name:            "json excluding sensitive fields",
+			fileName:        "results.json",
+			expectedContent: "{\n  \"DEPLOY_ENV\": \"prod\",\n  \"notes\": \"line \\\"one\\\"\\nline two\",\n  \"replicas\": 3\n}",
+		},
+		{
+			name:            "yaml detected from extension",
+			fileName:        "results.yml",
+			expectedContent: "DEPLOY_ENV: prod\nnotes: |-\n  line \"one\"\n  line two\nreplicas: 3\n",
+		},
+		{
+			name:            "dotenv including masked sensitive fields",
+			fileName:        "results",
+			fileFormat:      output.FileFormatDotenv,
+			sensitivePolicy: output.SensitivePolicyMask,
+			expectedContent: "II_API_KEY=\"s3cr3t\"\nII_NOTES=\"line \\\"one\\\"\\nline two\"\nII_REPLICAS=\"3\"\nTARGET=\"prod\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envFile := filepath.Join(t.TempDir(), "github_env")
+			assert.NoError(t, os.WriteFile(envFile, nil, 0600))
+
+			action := githubactions.New(
+				githubactions.WithWriter(bytes.NewBuffer(nil)),
+				githubactions.WithGetenv(func(key string) string {
+					if key == "GITHUB_ENV" {
+						return envFile
+					}
+					return ""
+				}),
+			)
+
+			filePath := filepath.Join(t.TempDir(), "nested", tt.fileName)
+			exporter := output.NewExporter(&output.NewExporterRequest{
+				Env:             true,
+				EnvPrefix:       "II_",
+				FilePath:        filePath,
+				FileFormat:      tt.fileFormat,
+				SensitivePolicy: tt.sensitivePolicy,
+				ActionPkg:       action,
+			})
+
+			assert.NoError(t, exporter.Export(declaredFields, encodedValues, typedValues))
+
+			content, err := os.ReadFile(filePath)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedContent, string(content))
+
+			envContent, err := os.ReadFile(envFile)
+			assert.NoError(t, err)
+			assert.Contains(t, string(envContent), "II_NOTES<<")
+			assert.Contains(t, string(envContent), "TARGET<<")
+			assert.Equal(t, tt.sensitivePolicy == output.SensitivePolicyMask, bytes.Contains(envContent, []byte("II_API_KEY<<")))
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/boasihq/interactive-inputs/internal/fields"
+)
+
+const (
+	// AggregateKey is the key of the output holding the typed values of every field as JSON
+	AggregateKey = "interactive-inputs"
+
+	// FormatCsv encodes the values as a single CSV record, quoting values that contain the delimiter
+	FormatCsv = "csv"
+
+	// FormatJson encodes the typed value as JSON, i.e. an array for multiselect fields
+	FormatJson = "json"
+
+	// FormatNewline encodes the values separated by newlines
+	FormatNewline = "newline"
+
+	// DefaultDelimiter is the delimiter used to separate values when one isn't provided
+	DefaultDelimiter = ","
+)
+
+// Normalise returns the submitted values of the field with empty values removed, booleans
+// normalised to true/false and the field's empty value used when nothing was submitted
+func Normalise(field *fields.Field, values []string) []string {
+
+	normalisedValues := []string{}
+	for _, value := range values {
+		if value != "" {
+			normalisedValues = append(normalisedValues, value)
+		}
+	}
+
+	if len(normalisedValues) == 0 && field.Properties.EmptyValue != "" {
+		normalisedValues = []string{field.Properties.EmptyValue}
+	}
+
+	if field.Properties.Type == "boolean" {
+		switch strings.ToLower(strings.TrimSpace(firstValue(normalisedValues))) {
+		case "true", "on", "yes", "1":
+			normalisedValues = []string{"true"}
+		default:
+			normalisedValues = []string{"false"}
+		}
+	}
+
+	return normalisedValues
+}
+
+// Transform returns the values of the field with the field's output transforms applied
+// to each of them, in order
+func Transform(field *fields.Field, values []string) []string {
+
+	if field.Properties.Output == nil || len(field.Properties.Output.Transforms) == 0 {
+		return values
+	}
+
+	transformedValues := make([]string, 0, len(values))
+	for _, value := range values {
+		for _, transform := range field.Properties.Output.Transforms {
+			switch transform.Type {
+			case "trim":
+				value = strings.TrimSpace(value)
+			case "lower":
+				value = strings.ToLower(value)
+			case "upper":
+				value = strings.ToUpper(value)
+			case "replace":
+				// patterns are validated when the fields are parsed
+				if pattern, err := regexp.Compile(transform.Pattern); err == nil {
+					value = pattern.ReplaceAllString(value, transform.Replacement)
+				}
+			case "prefix":
+				value = transform.Value + value
+			case "suffix":
+				value = value + transform.Value
+			}
+		}
+
+		transformedValues = append(transformedValues, value)
+	}
+
+	return transformedValues
+}
+
+// TypedValue returns the submitted values of the field converted to the field's type,
+// numbers as numbers, booleans as booleans and multiselect values as an array.
+// Values that cannot be converted are returned as strings.
+func TypedValue(field *fields.Field, values []string) interface{} {
+
+	switch field.Properties.Type {
+	case "multiselect":
+		typedValues := []string{}
+		for _, value := range values {
+			if value != "" {
+				typedValues = append(typedValues, value)
+			}
+		}
+		return typedValues
+
+	case "number":
+		value := firstValue(values)
+		if value == "" {
+			return nil
+		}
+
+		return typedNumber(value)
+
+	case "boolean":
+		value := firstValue(values)
+		if value == "" {
+			return nil
+		}
+
+		if typedValue, err := strconv.ParseBool(value); err == nil {
+			return typedValue
+		}
+		return value
+	}
+
+	return firstValue(values)
+}
+
+// Encode returns the submitted values of the field encoded in the field's output format.
+// If no output format is set, the values are joined with the delimiter
+func Encode(field *fields.Field, values []string) (string, error) {
+
+	delimiter := DefaultDelimiter
+	if field.Properties.OutputDelimiter != "" {
+		delimiter = field.Properties.OutputDelimiter
+	}
+
+	switch field.Properties.OutputFormat {
+	case FormatJson:
+		encodedValue, err := json.Marshal(TypedValue(field, values))
+		if err != nil {
+			return "", err
+		}
+		return string(encodedValue), nil
+
+	case FormatNewline:
+		return strings.Join(values, "\n"), nil
+
+	case FormatCsv:
+		var encodedValue bytes.Buffer
+		delimiterRune, _ := utf8.DecodeRuneInString(delimiter)
+
+		writer := csv.NewWriter(&encodedValue)
+		writer.Comma = delimiterRune
+		if err := writer.Write(values); err != nil {
+			return "", err
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(encodedValue.String(), "\n"), nil
+	}
+
+	return strings.Join(values, delimiter), nil
+}
+
+// Aggregate returns the typed values as a JSON object, keyed by output name
+func Aggregate(typedValues map[string]interface{}) (string, error) {
+	encodedValues, err := json.Marshal(typedValues)
+	if err != nil {
+		return "", err
+	}
+
+	return string(encodedValues), nil
+}
+
+// firstValue returns the first submitted value, or an empty string if there are none
+func firstValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// typedNumber returns the value as a JSON number when it is a decimal number, re-formatted
+// when it isn't already valid JSON (i.e. ".5" or "+5", which strconv accepts) and as a string
+// otherwise (i.e. "NaN", "1_0" or "0x1p4"), so it can always be encoded
+func typedNumber(value string) interface{} {
+
+	if strings.Trim(value, "0123456789.eE+-") != "" {
+		return value
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+
+	if !json.Valid([]byte(value)) {
+		return json.Number(strconv.FormatFloat(number, 'g', -1, 64))
+	}
+
+	return json.Number(value)
+}
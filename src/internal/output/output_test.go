@@ -0,0 +1,275 @@
+package output_test
+
+import (
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/boasihq/interactive-inputs/internal/output"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncode(t *testing.T) {
+
+	tests := []struct {
+		name            string
+		fieldType       string
+		outputFormat    string
+		outputDelimiter string
+		values          []string
+		expectedOutput  string
+	}{
+		{
+			name:           "default joins multiselect values with a comma",
+			fieldType:      "multiselect",
+			values:         []string{"eu-west-1", "us-east-1"},
+			expectedOutput: "eu-west-1,us-east-1",
+		},
+		{
+			name:           "csv quotes values containing the delimiter",
+			fieldType:      "multiselect",
+			outputFormat:   output.FormatCsv,
+			values:         []string{"London, UK", "Paris"},
+			expectedOutput: `"London, UK",Paris`,
+		},
+		{
+			name:            "csv with custom delimiter",
+			fieldType:       "multiselect",
+			outputFormat:    output.FormatCsv,
+			outputDelimiter: ";",
+			values:          []string{"London, UK", "Paris"},
+			expectedOutput:  "London, UK;Paris",
+		},
+		{
+			name:           "json multiselect is an array",
+			fieldType:      "multiselect",
+			outputFormat:   output.FormatJson,
+			values:         []string{"London, UK", "Paris"},
+			expectedOutput: `["London, UK","Paris"]`,
+		},
+		{
+			name:           "json number is a number",
+			fieldType:      "number",
+			outputFormat:   output.FormatJson,
+			values:         []string{"42"},
+			expectedOutput: "42",
+		},
+		{
+			name:           "json number without a leading zero is re-formatted",
+			fieldType:      "number",
+			outputFormat:   output.FormatJson,
+			values:         []string{".5"},
+			expectedOutput: "0.5",
+		},
+		{
+			name:           "json number with a plus sign is re-formatted",
+			fieldType:      "number",
+			outputFormat:   output.FormatJson,
+			values:         []string{"+5"},
+			expectedOutput: "5",
+		},
+		{
+			name:           "json number with underscores is a string",
+			fieldType:      "number",
+			outputFormat:   output.FormatJson,
+			values:         []string{"1_0"},
+			expectedOutput: `"1_0"`,
+		},
+		{
+			name:           "json NaN is a string",
+			fieldType:      "number",
+			outputFormat:   output.FormatJson,
+			values:         []string{"NaN"},
+			expectedOutput: `"NaN"`,
+		},
+		{
+			name:           "json boolean is a boolean",
+			fieldType:      "boolean",
+			outputFormat:   output.FormatJson,
+			values:         []string{"true"},
+			expectedOutput: "true",
+		},
+		{
+			name:           "newline separated",
+			fieldType:      "multiselect",
+			outputFormat:   output.FormatNewline,
+			values:         []string{"a", "b"},
+			expectedOutput: "a\nb",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field := &fields.Field{
+				Label: "field",
+				Properties: fields.FieldProperties{
+					Type:            tt.fieldType,
+					OutputFormat:    tt.outputFormat,
+					OutputDelimiter: tt.outputDelimiter,
+				},
+			}
+
+			encodedValue, err := output.Encode(field, tt.values)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedOutput, encodedValue)
+		})
+	}
+}
+
+func TestNormalise(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		fieldType      string
+		emptyValue     string
+		values         []string
+		expectedValues []string
+	}{
+		{
+			name:           "unchecked boolean is false",
+			fieldType:      "boolean",
+			values:         nil,
+			expectedValues: []string{"false"},
+		},
+		{
+			name:           "checked boolean is true",
+			fieldType:      "boolean",
+			values:         []string{"on"},
+			expectedValues: []string{"true"},
+		},
+		{
+			name:           "unchecked boolean uses empty value",
+			fieldType:      "boolean",
+			emptyValue:     "true",
+			values:         nil,
+			expectedValues: []string{"true"},
+		},
+		{
+			name:           "empty multiselect",
+			fieldType:      "multiselect",
+			values:         nil,
+			expectedValues: []string{},
+		},
+		{
+			name:           "empty text uses empty value",
+			fieldType:      "text",
+			emptyValue:     "n/a",
+			values:         []string{""},
+			expectedValues: []string{"n/a"},
+		},
+		{
+			name:           "submitted text keeps its value",
+			fieldType:      "text",
+			emptyValue:     "n/a",
+			values:         []string{"Ada"},
+			expectedValues: []string{"Ada"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field := &fields.Field{
+				Label: "field",
+				Properties: fields.FieldProperties{
+					Type:       tt.fieldType,
+					EmptyValue: tt.emptyValue,
+				},
+			}
+
+			assert.Equal(t, tt.expectedValues, output.Normalise(field, tt.values))
+		})
+	}
+}
+
+func TestTransform(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		transforms     []fields.OutputTransform
+		values         []string
+		expectedValues []string
+	}{
+		{
+			name:           "no transforms",
+			values:         []string{" Production "},
+			expectedValues: []string{" Production "},
+		},
+		{
+			name:           "trim and upper",
+			transforms:     []fields.OutputTransform{{Type: "trim"}, {Type: "upper"}},
+			values:         []string{" Production "},
+			expectedValues: []string{"PRODUCTION"},
+		},
+		{
+			name: "replace with capture group then prefix and suffix",
+			transforms: []fields.OutputTransform{
+				{Type: "lower"},
+				{Type: "replace", Pattern: `^release/(\d+\.\d+)$`, Replacement: "${1}"},
+				{Type: "prefix", Value: "v"},
+				{Type: "suffix", Value: "-rc"},
+			},
+			values:         []string{"Release/1.2", "release/2.0"},
+			expectedValues: []string{"v1.2-rc", "v2.0-rc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field := &fields.Field{
+				Label: "field",
+				Properties: fields.FieldProperties{
+					Type:   "multiselect",
+					Output: &fields.Output{Transforms: tt.transforms},
+				},
+			}
+
+			assert.Equal(t, tt.expectedValues, output.Transform(field, tt.values))
+		})
+	}
+}
+
+func TestAggregate(t *testing.T) {
+
+	typedValues := map[string]interface{}{}
+	for label, submitted := range map[string]struct {
+		fieldType string
+		values    []string
+	}{
+		"name":      {fieldType: "text", values: []string{"Ada"}},
+		"replicas":  {fieldType: "number", values: []string{"3"}},
+		"dry-run":   {fieldType: "boolean", values: []string{"false"}},
+		"regions":   {fieldType: "multiselect", values: []string{"eu-west-1"}},
+		"threshold": {fieldType: "number", values: []string{""}},
+		"ratio":     {fieldType: "number", values: []string{".5"}},
+		"step":      {fieldType: "number", values: []string{"+5"}},
+		"batch":     {fieldType: "number", values: []string{"1_0"}},
+		"limit":     {fieldType: "number", values: []string{"NaN"}},
+	} {
+		field := &fields.Field{Label: label, Properties: fields.FieldProperties{Type: submitted.fieldType}}
+		typedValues[label] = output.TypedValue(field, submitted.values)
+	}
+
+	aggregatedValues, err := output.Aggregate(typedValues)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"name":"Ada","replicas":3,"dry-run":false,"regions":["eu-west-1"],"threshold":null,"ratio":0.5,"step":5,"batch":"1_0","limit":"NaN"}`, aggregatedValues)
+}
+
+func TestEscape(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		escape        string
+		value         string
+		expectedValue string
+	}{
+		{name: "shell", escape: output.EscapeShell, value: "hello world", expectedValue: "'hello world'"},
+		{name: "shell with quotes and substitution", escape: output.EscapeShell, value: "it's $(id)", expectedValue: `'it'\''s $(id)'`},
+		{name: "json", escape: output.EscapeJson, value: "line \"one\"\n$HOME", expectedValue: `"line \"one\"\n$HOME"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedValue, output.Escape(tt.escape, tt.value))
+			assert.Equal(t, "field-"+tt.escape, output.EscapedKey("field", tt.escape))
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/boasihq/interactive-inputs/internal/fields"
+)
+
+const (
+	// SpillDirName is the name of the directory oversized values are written to
+	SpillDirName = "interactive-inputs-outputs"
+
+	// SpillFileKeySuffix is the suffix added to the key of the output holding the path of a
+	// spilled value
+	SpillFileKeySuffix = fields.OutputNameSuffixSpillFile
+
+	// DefaultSpillThreshold is the size (in bytes) above which values are spilled to a file,
+	// GitHub limits each output to 1 MiB
+	DefaultSpillThreshold = 1024 * 1024
+)
+
+// NewSpillerRequest is the request object for creating a new instance of Spiller
+type NewSpillerRequest struct {
+
+	// RootDir is the directory the spill directory is created in, i.e. RUNNER_TEMP
+	RootDir string
+
+	// Threshold is the size (in bytes) above which values are spilled, 0 disables spilling
+	Threshold int
+}
+
+// NewSpiller returns a new instance of Spiller
+func NewSpiller(r *NewSpillerRequest) *Spiller {
+
+	var rootDir string = r.RootDir
+
+	if rootDir == "" {
+		rootDir = os.TempDir()
+	}
+
+	return &Spiller{
+		dir:       filepath.Join(rootDir, SpillDirName),
+		threshold: r.Threshold,
+	}
+}
+
+// Spiller writes values too large to be set as outputs to files
+type Spiller struct {
+
+	// dir is the directory spilled values are written to
+	dir string
+
+	// threshold is the size (in bytes) above which values are spilled
+	threshold int
+}
+
+// ShouldSpill returns whether the value is too large to be set as an output
+func (s *Spiller) ShouldSpill(value string) bool {
+	return s.threshold > 0 && len(value) > s.threshold
+}
+
+// Spill writes the value of the output with the given key to a file, returning its path
+func (s *Spiller) Spill(key, value string) (string, error) {
+
+	err := os.MkdirAll(s.dir, 0700)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.CreateTemp(s.dir, fmt.Sprintf("%s-*.txt", envNameInvalidCharacters.ReplaceAllString(key, "_")))
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(value)
+	if err != nil {
+		return "", err
+	}
+
+	return file.Name(), nil
+}
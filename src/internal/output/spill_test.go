@@ -0,0 +1,61 @@
+package output_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/output"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpiller_Spill(t *testing.T) {
+
+	tests := []struct {
+		name                string
+		threshold           int
+		value               string
+		expectedShouldSpill bool
+	}{
+		{
+			name:                "value within threshold",
+			threshold:           10,
+			value:               "SELECT 1;",
+			expectedShouldSpill: false,
+		},
+		{
+			name:                "value above threshold",
+			threshold:           10,
+			value:               "SELECT * FROM users;",
+			expectedShouldSpill: true,
+		},
+		{
+			name:                "disabled threshold",
+			threshold:           0,
+			value:               strings.Repeat("a", output.DefaultSpillThreshold+1),
+			expectedShouldSpill: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rootDir := t.TempDir()
+			spiller := output.NewSpiller(&output.NewSpillerRequest{
+				RootDir:   rootDir,
+				Threshold: tt.threshold,
+			})
+
+			assert.Equal(t, tt.expectedShouldSpill, spiller.ShouldSpill(tt.value))
+
+			spillPath, err := spiller.Spill("sql/script", tt.value)
+			assert.NoError(t, err)
+			assert.Equal(t, filepath.Join(rootDir, output.SpillDirName), filepath.Dir(spillPath))
+			assert.True(t, strings.HasPrefix(filepath.Base(spillPath), "sql_script-"))
+
+			content, err := os.ReadFile(spillPath)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.value, string(content))
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package portal
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"github.com/boasihq/interactive-inputs/internal/approval"
+	"github.com/boasihq/interactive-inputs/internal/audit"
+	"github.com/boasihq/interactive-inputs/internal/auth"
+	"go.uber.org/zap"
+)
+
+// GetTally returns the live tally of a quorum portal's decisions, which the portal's page
+// polls while it is open
+func (h *Handler) GetTally(w http.ResponseWriter, r *http.Request) {
+
+	if h.tally == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	// html/template escapes the comments approvers left with their decisions
+	parsedTemplates, err := template.ParseFS(h.embeddedContent, fmt.Sprintf("%sweb/ui/html/partials/responses/tally.tmpl.html", h.embeddedContentFilePathPrefix))
+	if err != nil {
+		h.actionPkg.Errorf("Unable to parse referenced template: %v", zap.Error(err))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	err = parsedTemplates.Execute(w, h.tally.Status())
+	if err != nil {
+		h.actionPkg.Errorf("Unable to execute parsed template: %v", zap.Error(err))
+	}
+}
+
+// getApproverTeams returns the required teams of the quorum the signed in visitor is a
+// member of, warning (and counting them as a member of none) when it can't be checked
+func (h *Handler) getApproverTeams(r *http.Request) []string {
+
+	identity := auth.IdentityFromContext(r.Context())
+	if h.tally == nil || h.authenticator == nil || identity == nil || len(h.tally.RequiredTeams()) == 0 {
+		return nil
+	}
+
+	teams, err := h.authenticator.MemberTeams(identity, h.tally.RequiredTeams())
+	if err != nil {
+		h.actionPkg.Warningf("Unable to check which of the required teams @%s is a member of: %v", identity.Login, err)
+		return nil
+	}
+
+	return teams
+}
+
+// renderDecisionRecorded responds to a decision that was counted towards the quorum
+// without closing the portal, telling the approver how far off the quorum is
+func (h *Handler) renderDecisionRecorded(w http.ResponseWriter, r *http.Request, decision *approval.Decision) {
+
+	status := h.tally.Status()
+
+	h.actionPkg.Infof("%s (%d of %d approvals)", decision.Message(), status.Approvals, status.Quorum)
+	h.session.Record(fmt.Sprintf("%s (%d of %d approvals)", decision.Message(), status.Approvals, status.Quorum))
+
+	decisionAuditEntry := newAuditEntry(r, audit.EventDecision)
+	decisionAuditEntry.Detail = fmt.Sprintf("%s, %d of %d approvals", decision.Decision, status.Approvals, status.Quorum)
+	h.recordAudit(decisionAuditEntry)
+
+	message := fmt.Sprintf("%d of %d approvals so far, the portal stays open until the request is approved", status.Approvals, status.Quorum)
+	if status.Approvals >= status.Quorum {
+		message = fmt.Sprintf("%d of %d approvals so far, the portal stays open until every required approver has approved the request", status.Approvals, status.Quorum)
+	} else if len(status.PendingUsers) > 0 || len(status.PendingTeams) > 0 {
+		message += " by every required approver"
+	}
+
+	h.renderMessage(w, "recorded.tmpl.html", "Approval Recorded", message)
+}
+
+// recordDecision logs the decision the portal came to, sets it (and every decision made
+// on the way) as outputs and adds it to the session. Comments are left out of the logs and
+// the session when submitted values are redacted
+func (h *Handler) recordDecision(decision *approval.Decision, decisions []approval.Decision) {
+
+	sessionDecision := *decision
+	if h.redactLogValues {
+		sessionDecision.Comment = ""
+	}
+
+	decisionOutputs := decision.Outputs()
+	for _, key := range approval.OutputKeys {
+		if key == approval.OutputKeyComment && h.redactLogValues {
+			h.actionPkg.Infof("%s: <redacted>", key)
+		} else {
+			h.actionPkg.Infof("%s: %s", key, decisionOutputs[key])
+		}
+
+		if !h.isRunningLocal {
+			// Can't use when running locally
+			h.setOutput(key, decisionOutputs[key])
+		}
+	}
+
+	encodedDecisions, err := json.Marshal(decisions)
+	if err != nil {
+		h.actionPkg.Errorf("Unable to encode the %s output: %v", approval.OutputKeyApprovers, err)
+	}
+
+	if err == nil {
+		if h.redactLogValues {
+			h.actionPkg.Infof("%s: <redacted>", approval.OutputKeyApprovers)
+		} else {
+			h.actionPkg.Infof("%s: %s", approval.OutputKeyApprovers, encodedDecisions)
+		}
+
+		if !h.isRunningLocal {
+			h.setOutput(approval.OutputKeyApprovers, string(encodedDecisions))
+		}
+	}
+
+	h.session.SetDecision(&sessionDecision)
+	h.session.Record(sessionDecision.Message())
+}
@@ -0,0 +1,127 @@
+package portal
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/boasihq/interactive-inputs/internal/audit"
+)
+
+// AuditHandler manages tagging every request with an ID and recording views of the portal
+// in the audit log
+type AuditHandler struct {
+
+	// actionPkg represents the githubactions package
+	actionPkg actionPkg
+
+	// auditLogger appends the portal's interactions to the audit log
+	auditLogger *audit.Logger
+}
+
+// NewAuditHandlerRequest holds everything needed to create an audit handler
+type NewAuditHandlerRequest struct {
+
+	// ActionPkg represents the githubactions package
+	ActionPkg actionPkg
+
+	// AuditLogger appends the portal's interactions to the audit log
+	AuditLogger *audit.Logger
+}
+
+// NewAuditHandler returns audit handler
+func NewAuditHandler(r *NewAuditHandlerRequest) *AuditHandler {
+	return &AuditHandler{
+		actionPkg:   r.ActionPkg,
+		auditLogger: r.AuditLogger,
+	}
+}
+
+// Track is the middleware that gives every request an ID, adding it to the request's
+// context and the response's headers, and records views of the portal's page
+func (h *AuditHandler) Track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		requestId := audit.NewRequestId()
+		w.Header().Set(audit.RequestIdHeaderName, requestId)
+		r = r.WithContext(audit.WithRequestId(r.Context(), requestId))
+
+		if r.Method == http.MethodGet && r.URL.Path == "/" {
+			recordAudit(h.actionPkg, h.auditLogger, newAuditEntry(r, audit.EventPageView))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// recordAudit appends the entry to the audit log of the handler (if any)
+func (h *Handler) recordAudit(entry audit.Entry) {
+	recordAudit(h.actionPkg, h.auditLogger, entry)
+}
+
+// closeAuditLog closes the audit log of the handler (if any), so the entries still waiting
+// to be forwarded are sent before the action exits
+func (h *Handler) closeAuditLog() {
+	if h.auditLogger == nil {
+		return
+	}
+
+	if err := h.auditLogger.Close(); err != nil {
+		h.actionPkg.Warningf("Unable to close the audit log: %v", err)
+	}
+}
+
+// recordAudit appends the entry to the audit log, warning (rather than failing the request)
+// when it can't be
+func recordAudit(actionPkg actionPkg, auditLogger *audit.Logger, entry audit.Entry) {
+	if auditLogger == nil {
+		return
+	}
+
+	if err := auditLogger.Record(entry); err != nil {
+		actionPkg.Warningf("Unable to record the %s in the audit log: %v", entry.Event, err)
+	}
+}
+
+// newAuditEntry returns an audit log entry for the event, identifying who made the request
+func newAuditEntry(r *http.Request, event string) audit.Entry {
+	submitter := getSubmitter(r)
+
+	return audit.Entry{
+		Event:     event,
+		RequestId: audit.RequestIdFromContext(r.Context()),
+		Ip:        submitter.Ip,
+		UserAgent: submitter.UserAgent,
+		Login:     submitter.Login,
+		Link:      submitter.Link,
+	}
+}
+
+// newAuditValidationFailure returns an audit log entry for a rejected submission or upload
+func newAuditValidationFailure(r *http.Request, inputFieldLabel, detail string) audit.Entry {
+	entry := newAuditEntry(r, audit.EventValidationFailure)
+	entry.Field = inputFieldLabel
+	entry.Detail = detail
+
+	return entry
+}
+
+// newAuditUpload returns an audit log entry for the files uploaded to a file field, naming
+// the ones that were accepted
+func newAuditUpload(r *http.Request, manifest *UploadManifest) audit.Entry {
+	acceptedFiles := []string{}
+	for _, file := range manifest.Files {
+		if file.Status == ManifestFileStatusAccepted {
+			acceptedFiles = append(acceptedFiles, file.Name)
+		}
+	}
+
+	entry := newAuditEntry(r, audit.EventUpload)
+	entry.Field = manifest.Label
+	entry.Detail = fmt.Sprintf("%d of %d file(s) uploaded", len(acceptedFiles), len(manifest.Files))
+	if len(acceptedFiles) > 0 {
+		entry.Detail += ": " + strings.Join(acceptedFiles, ", ")
+	}
+
+	return entry
+}
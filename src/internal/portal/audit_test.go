@@ -0,0 +1,77 @@
+package portal_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/audit"
+	"github.com/boasihq/interactive-inputs/internal/portal"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditHandler_Track(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		expectedIp   string
+	}{
+		{
+			name:       "direct request",
+			remoteAddr: "192.0.2.1:52000",
+			expectedIp: "192.0.2.1",
+		},
+		{
+			name:         "forwarded by the ngrok edge",
+			remoteAddr:   "127.0.0.1:52000",
+			forwardedFor: "203.0.113.7",
+			expectedIp:   "203.0.113.7",
+		},
+		{
+			name:         "forged forwarded address is not trusted",
+			remoteAddr:   "127.0.0.1:52000",
+			forwardedFor: "198.51.100.66, 203.0.113.7",
+			expectedIp:   "203.0.113.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action := githubactions.New()
+
+			auditLogger, err := audit.NewLogger(&audit.NewLoggerRequest{
+				ActionPkg: action,
+				Path:      filepath.Join(t.TempDir(), "audit.jsonl"),
+			})
+			assert.NoError(t, err)
+
+			auditHandler := portal.NewAuditHandler(&portal.NewAuditHandlerRequest{
+				ActionPkg:   action,
+				AuditLogger: auditLogger,
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwardedFor != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+
+			auditHandler.Track(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(httptest.NewRecorder(), r)
+			assert.NoError(t, auditLogger.Close())
+
+			content, err := os.ReadFile(auditLogger.Path())
+			assert.NoError(t, err)
+
+			var entry audit.Entry
+			assert.NoError(t, json.Unmarshal(content, &entry))
+			assert.Equal(t, audit.EventPageView, entry.Event)
+			assert.Equal(t, tt.expectedIp, entry.Ip)
+		})
+	}
+}
@@ -0,0 +1,408 @@
+package portal
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/boasihq/interactive-inputs/internal/auth"
+	iaiperrors "github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/security"
+	"github.com/boasihq/interactive-inputs/internal/session"
+	"go.uber.org/zap"
+)
+
+// AuthHandler manages signing visitors in to the portal with their GitHub identity, or
+// unlocking it with an access code
+type AuthHandler struct {
+
+	// actionPkg represents the githubactions package
+	actionPkg actionPkg
+
+	// authenticator signs visitors in and checks whether they are allowed to use the portal
+	authenticator *auth.Authenticator
+
+	// embeddedContent embedded content of the web app
+	embeddedContent fs.FS
+
+	// embeddedContentFilePathPrefix path prefix of the embedded content
+	embeddedContentFilePathPrefix string
+
+	// title is the title of the portal
+	title string
+
+	// session tracks what happens to the portal while it is open
+	session *session.Session
+}
+
+// NewAuthHandlerRequest holds everything needed to create an auth handler
+type NewAuthHandlerRequest struct {
+
+	// ActionPkg represents the githubactions package
+	ActionPkg actionPkg
+
+	// Authenticator signs visitors in and checks whether they are allowed to use the portal
+	Authenticator *auth.Authenticator
+
+	// EmbeddedContent embedded content of the web app
+	EmbeddedContent fs.FS
+
+	// EmbeddedContentFilePathPrefix path prefix of the embedded content
+	EmbeddedContentFilePathPrefix string
+
+	// Title is the title of the portal
+	Title string
+
+	// Session tracks what happens to the portal while it is open
+	Session *session.Session
+}
+
+// NewAuthHandler returns auth handler
+func NewAuthHandler(r *NewAuthHandlerRequest) *AuthHandler {
+	return &AuthHandler{
+		actionPkg:                     r.ActionPkg,
+		authenticator:                 r.Authenticator,
+		embeddedContent:               r.EmbeddedContent,
+		embeddedContentFilePathPrefix: r.EmbeddedContentFilePathPrefix,
+		title:                         r.Title,
+		session:                       r.Session,
+	}
+}
+
+// loginPage is the data the sign in page is rendered with
+type loginPage struct {
+
+	// RepoOwner is the name that will feature in the page's title
+	RepoOwner string
+
+	// Title is the title of the portal
+	Title string
+
+	// UserCode is the code the visitor enters on GitHub (device flow only)
+	UserCode string
+
+	// VerificationUri is where the visitor enters the code (device flow only)
+	VerificationUri string
+
+	// Interval is how often (in seconds) the page checks whether the code has been entered
+	Interval int
+
+	// AccessCode is whether the page asks for the access code (access-code mode only)
+	AccessCode bool
+
+	// RemainingAttempts is how many more incorrect access codes are accepted
+	RemainingAttempts int
+
+	// Error is why the visitor couldn't be signed in
+	Error string
+
+	// CsrfToken is the token the access code form is submitted with
+	CsrfToken string
+
+	// CspNonce is the nonce the page's inline scripts carry
+	CspNonce string
+}
+
+// Login returns response for request to sign in to the portal, sending the visitor to
+// GitHub for the web flow, showing the code to enter for the device flow or asking for
+// the access code
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+
+	if h.authenticator.Identity(r) != nil {
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+
+	if h.authenticator.Mode() == auth.ModeAccessCode {
+		h.renderAccessCodePage(w, r, http.StatusOK, "")
+		return
+	}
+
+	if h.authenticator.Mode() == auth.ModeWeb {
+		loginId, authorizeUrl, err := h.authenticator.StartWebLogin(h.getCallbackUrl(r))
+		if err != nil {
+			h.actionPkg.Errorf("Unable to start signing in with GitHub: %v", err)
+			h.renderLoginPage(w, r, http.StatusInternalServerError, loginPage{Error: "Unable to start signing in with GitHub."})
+			return
+		}
+
+		setCookie(w, r, auth.LoginCookieName, loginId)
+		http.Redirect(w, r, authorizeUrl, http.StatusFound)
+		return
+	}
+
+	loginId, deviceCode, err := h.authenticator.StartDeviceLogin()
+	if err != nil {
+		h.actionPkg.Errorf("Unable to start signing in with GitHub: %v", err)
+		h.renderLoginPage(w, r, http.StatusBadGateway, loginPage{Error: "Unable to start signing in with GitHub."})
+		return
+	}
+
+	setCookie(w, r, auth.LoginCookieName, loginId)
+	h.renderLoginPage(w, r, http.StatusOK, loginPage{
+		UserCode:        deviceCode.UserCode,
+		VerificationUri: deviceCode.VerificationUri,
+		Interval:        deviceCode.Interval,
+	})
+}
+
+// Callback returns response for request GitHub redirects the visitor back to at the end
+// of the web flow
+func (h *AuthHandler) Callback(w http.ResponseWriter, r *http.Request) {
+
+	if oauthError := r.URL.Query().Get("error"); oauthError != "" {
+		h.actionPkg.Warningf("Signing in with GitHub failed: %s", oauthError)
+		h.renderLoginPage(w, r, http.StatusUnauthorized, loginPage{Error: "Signing in with GitHub was cancelled or denied."})
+		return
+	}
+
+	var loginId string
+	if cookie, err := r.Cookie(auth.LoginCookieName); err == nil {
+		loginId = cookie.Value
+	}
+
+	identity, err := h.authenticator.FinishWebLogin(loginId, r.URL.Query().Get("state"), r.URL.Query().Get("code"), h.getCallbackUrl(r))
+	if err != nil {
+		status, message := h.handleLoginError(identity, err)
+		h.renderLoginPage(w, r, status, loginPage{Error: message})
+		return
+	}
+
+	err = h.startSession(w, r, identity)
+	if err != nil {
+		h.renderLoginPage(w, r, http.StatusInternalServerError, loginPage{Error: "Unable to start your session."})
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// Unlock returns response for request to unlock the portal with the access code
+func (h *AuthHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+
+	if h.authenticator.Mode() != auth.ModeAccessCode {
+		http.NotFound(w, r)
+		return
+	}
+
+	// incorrect codes are counted per visitor, by the address the ngrok edge saw
+	clientIp := getClientIp(r)
+
+	r.ParseForm()
+	identity, err := h.authenticator.Unlock(clientIp, r.PostForm.Get("access-code"))
+	switch {
+	case errors.Is(err, iaiperrors.ErrAccessCodeLockedOut):
+		h.actionPkg.Warningf("The portal has been locked after too many incorrect access codes")
+		h.session.Record("Portal locked after too many incorrect access codes")
+		h.renderLoginPage(w, r, http.StatusForbidden, loginPage{Error: "The portal has been locked after too many incorrect access codes."})
+		return
+	case errors.Is(err, iaiperrors.ErrAccessCodeCoolingDown):
+		h.actionPkg.Warningf("Too many incorrect access codes entered from %s, %v", clientIp, err)
+		h.session.Record(fmt.Sprintf("Too many incorrect access codes entered from %s", clientIp))
+		h.renderAccessCodePage(w, r, http.StatusTooManyRequests, "")
+		return
+	case err != nil:
+		h.actionPkg.Warningf("Incorrect access code entered from %s", clientIp)
+		h.session.Record(fmt.Sprintf("Incorrect access code entered from %s", clientIp))
+		h.renderAccessCodePage(w, r, http.StatusUnauthorized, "Incorrect access code.")
+		return
+	}
+
+	err = h.startSession(w, r, identity)
+	if err != nil {
+		h.renderLoginPage(w, r, http.StatusInternalServerError, loginPage{Error: "Unable to start your session."})
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// PollDevice returns response for request (made by the sign in page) to check whether the
+// visitor has entered their code during the device flow
+func (h *AuthHandler) PollDevice(w http.ResponseWriter, r *http.Request) {
+
+	var loginId string
+	if cookie, err := r.Cookie(auth.LoginCookieName); err == nil {
+		loginId = cookie.Value
+	}
+
+	identity, err := h.authenticator.PollDeviceLogin(loginId)
+	if errors.Is(err, iaiperrors.ErrAuthorisationPending) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if err == nil {
+		err = h.startSession(w, r, identity)
+		if err == nil {
+			w.Header().Set("HX-Redirect", "/")
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+
+	_, message := h.handleLoginError(identity, err)
+
+	// replace the polling element, so the page stops polling
+	w.Header().Set("Content-Type", "text/html")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, `<div class="mt-6 flex flex-col items-center"><p class="text-error">%s</p><a href="/auth/login" class="btn btn-wide btn-md mt-6">Try again</a></div>`, template.HTMLEscapeString(message))
+}
+
+// RequireLogin is the middleware that stops visitors who haven't signed in from using the
+// portal. Signed in visitors have their identity added to the request's context
+func (h *AuthHandler) RequireLogin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if strings.HasPrefix(r.URL.Path, "/static/") || strings.HasPrefix(r.URL.Path, "/auth/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		identity := h.authenticator.Identity(r)
+		if identity == nil {
+			if r.Method == http.MethodGet && r.URL.Path == "/" {
+				http.Redirect(w, r, "/auth/login", http.StatusFound)
+				return
+			}
+
+			w.Header().Set("HX-Redirect", "/auth/login")
+			http.Error(w, "Please sign in to use this portal", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithIdentity(r.Context(), identity)))
+	})
+}
+
+// startSession starts a session for the signed in identity and sets its cookie
+func (h *AuthHandler) startSession(w http.ResponseWriter, r *http.Request, identity *auth.Identity) error {
+	sessionId, err := h.authenticator.NewSession(identity)
+	if err != nil {
+		h.actionPkg.Errorf("Unable to start a session for @%s: %v", identity.Login, err)
+		return err
+	}
+
+	setCookie(w, r, auth.SessionCookieName, sessionId)
+	setCookie(w, r, auth.LoginCookieName, "")
+
+	if identity.Login == "" {
+		h.actionPkg.Infof("The portal was unlocked with the access code from %s", getSubmitter(r).Ip)
+		h.session.Record(fmt.Sprintf("Unlocked with the access code from %s", getSubmitter(r).Ip))
+		return nil
+	}
+
+	h.actionPkg.Infof("@%s signed in to the portal", identity.Login)
+	h.session.Record(fmt.Sprintf("@%s signed in", identity.Login))
+
+	return nil
+}
+
+// handleLoginError logs why the visitor couldn't be signed in, returning the status code
+// and message to respond with
+func (h *AuthHandler) handleLoginError(identity *auth.Identity, err error) (int, string) {
+	switch {
+	case errors.Is(err, iaiperrors.ErrSubmitterNotAuthorised) && identity != nil:
+		h.actionPkg.Warningf("@%s signed in but isn't allowed to use the portal", identity.Login)
+		h.session.Record(fmt.Sprintf("@%s was refused access", identity.Login))
+		return http.StatusForbidden, fmt.Sprintf("@%s isn't allowed to use this portal.", identity.Login)
+	case errors.Is(err, iaiperrors.ErrInvalidAuthState):
+		h.actionPkg.Warningf("Signing in with GitHub failed: %v", err)
+		return http.StatusBadRequest, "Your sign in has expired, please try again."
+	default:
+		h.actionPkg.Errorf("Signing in with GitHub failed: %v", err)
+		return http.StatusBadGateway, "Unable to sign in with GitHub."
+	}
+}
+
+// getCallbackUrl returns the URL GitHub redirects the visitor back to during the web flow,
+// preferring the portal's known URL over the request's (spoofable) host
+func (h *AuthHandler) getCallbackUrl(r *http.Request) string {
+	if portalUrl := h.session.PortalUrl(); portalUrl != "" {
+		return strings.TrimSuffix(portalUrl, "/") + "/auth/callback"
+	}
+
+	scheme := "http"
+	if security.IsSecureRequest(r) {
+		scheme = "https"
+	}
+
+	return fmt.Sprintf("%s://%s/auth/callback", scheme, r.Host)
+}
+
+// renderAccessCodePage renders the sign in page asking for the access code, or explaining
+// that the visitor (or the whole portal) is locked once too many incorrect codes have been
+// entered
+func (h *AuthHandler) renderAccessCodePage(w http.ResponseWriter, r *http.Request, status int, message string) {
+	if h.authenticator.AccessCodeLocked() {
+		h.renderLoginPage(w, r, http.StatusForbidden, loginPage{Error: "The portal has been locked after too many incorrect access codes."})
+		return
+	}
+
+	remainingAttempts := h.authenticator.RemainingAccessCodeAttempts(getClientIp(r))
+	if remainingAttempts == 0 {
+		h.renderLoginPage(w, r, http.StatusTooManyRequests, loginPage{Error: "Too many incorrect access codes have been entered, please try again later."})
+		return
+	}
+
+	h.renderLoginPage(w, r, status, loginPage{
+		AccessCode:        true,
+		RemainingAttempts: remainingAttempts,
+		Error:             message,
+	})
+}
+
+// renderLoginPage renders the sign in page with the given status code
+func (h *AuthHandler) renderLoginPage(w http.ResponseWriter, r *http.Request, status int, page loginPage) {
+
+	page.Title = h.title
+	page.CsrfToken = security.CsrfToken(r.Context())
+	page.CspNonce = security.CspNonce(r.Context())
+	if actionContext, err := h.actionPkg.Context(); err == nil {
+		page.RepoOwner, _ = actionContext.Repo()
+	}
+
+	// list of template files to parse, must be in order of inheritence
+	templateFilesToParse := []string{
+		fmt.Sprintf("%sweb/ui/html/index.tmpl.html", h.embeddedContentFilePathPrefix),
+		fmt.Sprintf("%sweb/ui/html/partials/shared/head-meta.tmpl.html", h.embeddedContentFilePathPrefix),
+		fmt.Sprintf("%sweb/ui/html/pages/@login.tmpl.html", h.embeddedContentFilePathPrefix),
+		fmt.Sprintf("%sweb/ui/html/partials/shared/tailwind-dash-script.tmpl.html", h.embeddedContentFilePathPrefix),
+	}
+
+	parsedTemplates, err := template.ParseFS(h.embeddedContent, templateFilesToParse...)
+	if err != nil {
+		h.actionPkg.Errorf("Unable to parse referenced template: %v", zap.Error(err))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.WriteHeader(status)
+
+	err = parsedTemplates.ExecuteTemplate(w, "base", page)
+	if err != nil {
+		h.actionPkg.Errorf("Unable to execute parsed template: %v", zap.Error(err))
+	}
+}
+
+// setCookie sets a cookie only sent back to the portal, an empty value removes it
+func setCookie(w http.ResponseWriter, r *http.Request, name, value string) {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   security.IsSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	}
+
+	if value == "" {
+		cookie.MaxAge = -1
+	}
+
+	http.SetCookie(w, cookie)
+}
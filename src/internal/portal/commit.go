@@ -0,0 +1,119 @@
+package portal
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	iaiperrors "github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/boasihq/interactive-inputs/internal/github"
+)
+
+// commitUploadedFilesResult holds the outcome of committing an input field's uploaded files
+type commitUploadedFilesResult struct {
+
+	// commitSha is the SHA of the commit holding the uploaded files
+	commitSha string
+
+	// pullRequestUrl is the URL of the pull request opened for the commit (if requested)
+	pullRequestUrl string
+}
+
+// commitUploadedFiles commits the files accepted for the given input field to the
+// repository running the action, opening a pull request if requested. It returns
+// nil when no files were uploaded to the input field
+func (h *Handler) commitUploadedFiles(inputFieldLabel string, commitTo *fields.CommitTo) (*commitUploadedFilesResult, error) {
+
+	h.uploadManifestsMutex.Lock()
+	manifest := h.uploadManifests[inputFieldLabel]
+	h.uploadManifestsMutex.Unlock()
+
+	if manifest == nil || len(manifest.AcceptedFiles()) == 0 {
+		h.actionPkg.Infof("No files were uploaded to %s, skipping commit to %s", inputFieldLabel, commitTo.Branch)
+		return nil, nil
+	}
+
+	actionContext, err := h.actionPkg.Context()
+	if err != nil {
+		return nil, err
+	}
+
+	repoOwner, repoName := actionContext.Repo()
+	if repoOwner == "" || repoName == "" {
+		return nil, iaiperrors.ErrRepositoryNotFoundInContext
+	}
+
+	commitFiles := []github.CommitFile{}
+	for _, file := range manifest.AcceptedFiles() {
+		content, err := os.ReadFile(path.Join(manifest.CacheDir, file.StoredName))
+		if err != nil {
+			return nil, err
+		}
+
+		commitFiles = append(commitFiles, github.CommitFile{
+			Path:    path.Join(commitTo.Path, file.StoredName),
+			Content: content,
+		})
+	}
+
+	commitMessage := commitTo.Message
+	if commitMessage == "" {
+		commitMessage = fmt.Sprintf("Add files uploaded to %s via Interactive Inputs (run %d)", inputFieldLabel, actionContext.RunID)
+	}
+
+	h.actionPkg.Infof("Committing %d file(s) uploaded to %s to %s/%s@%s", len(commitFiles), inputFieldLabel, repoOwner, repoName, commitTo.Branch)
+
+	commit, err := h.githubClient.CommitFiles(&github.CommitFilesRequest{
+		Owner:   repoOwner,
+		Repo:    repoName,
+		Branch:  commitTo.Branch,
+		Base:    commitTo.Base,
+		Message: commitMessage,
+		Files:   commitFiles,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &commitUploadedFilesResult{commitSha: commit.CommitSha}
+
+	if commitTo.PullRequest == nil {
+		return result, nil
+	}
+
+	if commit.Base == commit.Branch {
+		h.actionPkg.Warningf("Skipping pull request for %s as %s is the base branch", inputFieldLabel, commit.Branch)
+		return result, nil
+	}
+
+	pullRequestTitle := commitTo.PullRequest.Title
+	if pullRequestTitle == "" {
+		pullRequestTitle = commitMessage
+	}
+
+	result.pullRequestUrl, err = h.githubClient.OpenPullRequest(&github.OpenPullRequestRequest{
+		Owner: repoOwner,
+		Repo:  repoName,
+		Title: pullRequestTitle,
+		Body:  commitTo.PullRequest.Body,
+		Head:  commit.Branch,
+		Base:  commit.Base,
+		Draft: commitTo.PullRequest.Draft,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// getInputFieldCommitTo returns the commit target for the given input field name,
+// or nil if the field does not have one.
+func (h *Handler) getInputFieldCommitTo(inputFieldName string) *fields.CommitTo {
+	if inputField := h.getInputField(inputFieldName); inputField != nil {
+		return inputField.Properties.CommitTo
+	}
+
+	return nil
+}
@@ -20,4 +20,8 @@ const (
 
 	// ErrKeyUnableToRemoveCacheDirContents is returned when the cache directory contents cannot be removed
 	ErrKeyUnableToRemoveCacheDirContents = "UnableToRemoveCacheDirContents"
+
+	// ErrKeyUploadTooLarge is returned when the files of an upload request are larger than
+	// the maximum upload size
+	ErrKeyUploadTooLarge = "UploadTooLarge"
 )
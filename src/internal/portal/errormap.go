@@ -13,4 +13,5 @@ var portalErrorMap = map[string]reply.ErrorManifestItem{
 	ErrKeyNoInputFieldCacheDirFound:      {Title: "Bad Request", Detail: "No cache directory found for input field label", StatusCode: http.StatusBadRequest},
 	ErrKeyUnableToReadCacheDir:           {Title: "Internal Server Error", Detail: "Unable to read cache directory", StatusCode: http.StatusInternalServerError},
 	ErrKeyUnableToRemoveCacheDirContents: {Title: "Internal Server Error", Detail: "Unable to remove cache directory content(s)", StatusCode: http.StatusInternalServerError},
+	ErrKeyUploadTooLarge:                 {Title: "Request Entity Too Large", Detail: "The uploaded file(s) are larger than the maximum upload size", StatusCode: http.StatusRequestEntityTooLarge},
 }
@@ -1,6 +1,8 @@
 package portal
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -9,9 +11,27 @@ import (
 	"os"
 	"path"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
+	"filippo.io/age"
+	"github.com/boasihq/interactive-inputs/internal/approval"
+	"github.com/boasihq/interactive-inputs/internal/audit"
+	"github.com/boasihq/interactive-inputs/internal/auth"
+	"github.com/boasihq/interactive-inputs/internal/cache"
+	"github.com/boasihq/interactive-inputs/internal/contentschema"
+	"github.com/boasihq/interactive-inputs/internal/encryption"
+	iaiperrors "github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/boasihq/interactive-inputs/internal/github"
+	"github.com/boasihq/interactive-inputs/internal/link"
+	"github.com/boasihq/interactive-inputs/internal/output"
+	"github.com/boasihq/interactive-inputs/internal/receipt"
+	"github.com/boasihq/interactive-inputs/internal/scanner"
+	"github.com/boasihq/interactive-inputs/internal/secrets"
+	"github.com/boasihq/interactive-inputs/internal/session"
+	"github.com/boasihq/interactive-inputs/internal/state"
 	"github.com/gorilla/mux"
 	"github.com/ooaklee/reply"
 	"github.com/sethvargo/go-githubactions"
@@ -27,6 +47,7 @@ type actionPkg interface {
 	Errorf(msg string, args ...any)
 	Fatalf(msg string, args ...any)
 	SetOutput(k string, v string)
+	AddMask(p string)
 }
 
 // Handler manages portal requests
@@ -47,19 +68,188 @@ type Handler struct {
 	// githubToken is the github token used to make Api calls
 	githubToken string
 
+	// githubClient is the client used to commit uploaded files to the repository
+	githubClient *github.Client
+
 	// inputFieldLabelToCacheDirMapping mapping of input field label to its cache directory
 	inputFieldLabelToCacheDirMapping map[string]string
+
+	// cache manages the directories uploaded files are written to
+	cache *cache.Cache
+
+	// fields is the fields displayed in the portal
+	fields *fields.Fields
+
+	// exporter exports the submitted values to GITHUB_ENV and/or a results file
+	exporter *output.Exporter
+
+	// spiller writes values too large to be set as outputs to files
+	spiller *output.Spiller
+
+	// secretDetector looks for secrets pasted into fields that aren't sensitive
+	secretDetector *secrets.Detector
+
+	// redactLogValues is true when submitted values are left out of the logs
+	redactLogValues bool
+
+	// receiptSigner signs the submission receipt, no receipt is produced when it is nil
+	receiptSigner *receipt.Signer
+
+	// receiptFile is the path the signed submission receipt is written to
+	receiptFile string
+
+	// receiptHashValues is true when only the checksums of submitted values are recorded
+	// in the submission receipt
+	receiptHashValues bool
+
+	// session tracks what happens to the portal while it is open
+	session *session.Session
+
+	// state makes sure only the first submission or cancellation closes the portal
+	state *state.Machine
+
+	// approval is true when the portal asks for an approve or reject decision
+	approval bool
+
+	// approvalPolicy is whether the step fails when the request is rejected
+	approvalPolicy string
+
+	// tally collects the decisions of several approvers before the portal closes, a single
+	// decision closes it when it is nil
+	tally *approval.Tally
+
+	// authenticator checks which of the tally's required teams approvers are members of
+	authenticator *auth.Authenticator
+
+	// scanner is the scanner uploaded files are checked with before being accepted
+	scanner scanner.Scanner
+
+	// encryptionRecipients is the list of recipients uploaded files are encrypted to
+	encryptionRecipients []age.Recipient
+
+	// auditLogger appends the portal's interactions to the audit log, nothing is recorded
+	// when it is nil
+	auditLogger *audit.Logger
+
+	// uploadManifestsMutex guards the upload manifests
+	uploadManifestsMutex sync.Mutex
+
+	// uploadManifests mapping of input field label to the manifest of its uploaded files
+	uploadManifests map[string]*UploadManifest
+}
+
+// NewHandlerRequest holds everything needed to create a portal handler
+type NewHandlerRequest struct {
+
+	// ActionPkg represents the githubactions package
+	ActionPkg actionPkg
+
+	// IsRunningLocal is true when running locally
+	IsRunningLocal bool
+
+	// EmbeddedContent embedded content of the web app
+	EmbeddedContent fs.FS
+
+	// EmbeddedContentFilePathPrefix path prefix of the embedded content
+	EmbeddedContentFilePathPrefix string
+
+	// GithubToken is the github token used to make Api calls
+	GithubToken string
+
+	// GithubClient is the client used to commit uploaded files to the repository
+	GithubClient *github.Client
+
+	// InputFieldLabelToCacheDirMapping mapping of input field label to its cache directory
+	InputFieldLabelToCacheDirMapping map[string]string
+
+	// Cache manages the directories uploaded files are written to
+	Cache *cache.Cache
+
+	// Fields is the fields displayed in the portal
+	Fields *fields.Fields
+
+	// Exporter exports the submitted values to GITHUB_ENV and/or a results file
+	Exporter *output.Exporter
+
+	// Spiller writes values too large to be set as outputs to files
+	Spiller *output.Spiller
+
+	// SecretDetector looks for secrets pasted into fields that aren't sensitive
+	SecretDetector *secrets.Detector
+
+	// RedactLogValues is true when submitted values are left out of the logs
+	RedactLogValues bool
+
+	// ReceiptSigner signs the submission receipt, no receipt is produced when it is nil
+	ReceiptSigner *receipt.Signer
+
+	// ReceiptFile is the path the signed submission receipt is written to
+	ReceiptFile string
+
+	// ReceiptHashValues is true when only the checksums of submitted values are recorded
+	// in the submission receipt
+	ReceiptHashValues bool
+
+	// Session tracks what happens to the portal while it is open
+	Session *session.Session
+
+	// State makes sure only the first submission or cancellation closes the portal
+	State *state.Machine
+
+	// Approval is true when the portal asks for an approve or reject decision
+	Approval bool
+
+	// ApprovalPolicy is whether the step fails when the request is rejected
+	ApprovalPolicy string
+
+	// Tally collects the decisions of several approvers before the portal closes, a single
+	// decision closes it when it is nil
+	Tally *approval.Tally
+
+	// Authenticator checks which of the tally's required teams approvers are members of
+	Authenticator *auth.Authenticator
+
+	// Scanner is the scanner uploaded files are checked with before being accepted
+	Scanner scanner.Scanner
+
+	// EncryptionRecipients is the list of recipients uploaded files are encrypted to
+	// before being written to the cache directory
+	EncryptionRecipients []age.Recipient
+
+	// AuditLogger appends the portal's interactions to the audit log, nothing is recorded
+	// when it is nil
+	AuditLogger *audit.Logger
 }
 
 // NewHandler returns portal handler
-func NewHandler(actionPkg actionPkg, isRunningLocal bool, embeddedContent fs.FS, embeddedContentFilePathPrefix, githubToken string, inputFieldLabelToCacheDirMapping map[string]string) *Handler {
+func NewHandler(r *NewHandlerRequest) *Handler {
 	return &Handler{
-		isRunningLocal:                   isRunningLocal,
-		actionPkg:                        actionPkg,
-		embeddedContent:                  embeddedContent,
-		embeddedContentFilePathPrefix:    embeddedContentFilePathPrefix,
-		githubToken:                      githubToken,
-		inputFieldLabelToCacheDirMapping: inputFieldLabelToCacheDirMapping,
+		isRunningLocal:                   r.IsRunningLocal,
+		actionPkg:                        r.ActionPkg,
+		embeddedContent:                  r.EmbeddedContent,
+		embeddedContentFilePathPrefix:    r.EmbeddedContentFilePathPrefix,
+		githubToken:                      r.GithubToken,
+		githubClient:                     r.GithubClient,
+		inputFieldLabelToCacheDirMapping: r.InputFieldLabelToCacheDirMapping,
+		cache:                            r.Cache,
+		fields:                           r.Fields,
+		exporter:                         r.Exporter,
+		spiller:                          r.Spiller,
+		secretDetector:                   r.SecretDetector,
+		redactLogValues:                  r.RedactLogValues,
+		receiptSigner:                    r.ReceiptSigner,
+		receiptFile:                      r.ReceiptFile,
+		receiptHashValues:                r.ReceiptHashValues,
+		session:                          r.Session,
+		state:                            r.State,
+		approval:                         r.Approval,
+		approvalPolicy:                   r.ApprovalPolicy,
+		tally:                            r.Tally,
+		authenticator:                    r.Authenticator,
+		scanner:                          r.Scanner,
+		encryptionRecipients:             r.EncryptionRecipients,
+		auditLogger:                      r.AuditLogger,
+		uploadManifests:                  make(map[string]*UploadManifest),
 	}
 }
 
@@ -86,6 +276,21 @@ func (h *Handler) CancelPortal(w http.ResponseWriter, r *http.Request) {
 		actionContext.RunID,
 	)
 
+	// only the first submission or cancellation closes the portal, repeats of the
+	// cancellation (i.e. double-clicks) are ignored
+	submitter := getSubmitter(r)
+	err = h.state.Cancel(r.Header.Get(state.IdempotencyKeyHeaderName), submitter)
+	if errors.Is(err, iaiperrors.ErrDuplicateRequest) {
+		h.actionPkg.Debugf("Ignored a repeated cancel request from %s", submitter.Display())
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if err != nil {
+		h.renderPortalClosed(w, r, "cancel")
+		return
+	}
+
 	// Parse template
 	parsedTemplates, err := template.ParseFS(h.embeddedContent, fmt.Sprintf("%sweb/ui/html/partials/responses/cancel.tmpl.html", h.embeddedContentFilePathPrefix))
 	if err != nil {
@@ -110,12 +315,19 @@ func (h *Handler) CancelPortal(w http.ResponseWriter, r *http.Request) {
 
 	h.actionPkg.Infof("Cancel request received")
 
+	h.session.SetSubmitter(submitter)
+	h.session.Close(session.OutcomeCancelled)
+	h.recordAudit(newAuditEntry(r, audit.EventCancel))
+
 	go func(actionContext *githubactions.GitHubContext) {
 
 		runId := actionContext.RunID
 		h.actionPkg.Infof("Cancelling job within run %d", runId)
 		time.Sleep(3 * time.Second)
 
+		h.cache.Cleanup(cache.CleanupEventCancel)
+		h.closeAuditLog()
+
 		h.actionPkg.Fatalf("Job within run %d cancelled", runId)
 
 	}(actionContext)
@@ -128,34 +340,275 @@ func (h *Handler) SubmitPortal(w http.ResponseWriter, r *http.Request) {
 	additionalContext := map[string]string{
 		"JobUrl": "",
 	}
+	failedCommits := []string{}
+	typedValues := map[string]interface{}{}
+	encodedValues := map[string]string{}
+
+	// which required teams an approver is a member of is checked with the GitHub API, so
+	// it's done before the portal is held for the submission
+	submitter := getSubmitter(r)
+	approverTeams := h.getApproverTeams(r)
+
+	// only the first submission or cancellation closes the portal, repeats of the
+	// winning submission (i.e. double-clicks) are ignored
+	err := h.state.BeginSubmit(r.Header.Get(state.IdempotencyKeyHeaderName), submitter)
+	if errors.Is(err, iaiperrors.ErrDuplicateRequest) {
+		h.actionPkg.Debugf("Ignored a repeated submission from %s", submitter.Display())
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// in a quorum portal another approver's decision is only held while it is tallied, so
+	// the approver is asked to try again rather than told the portal is closed
+	if err != nil && h.tally != nil && h.state.Status().State == state.StateSubmitting {
+		h.actionPkg.Infof("Asked %s to try again, another decision is being recorded", submitter.Display())
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "Another approver's decision is being recorded, please try again in a moment", http.StatusConflict)
+		return
+	}
+
+	if err != nil {
+		h.renderPortalClosed(w, r, "submit")
+		return
+	}
 
 	if h.isRunningLocal {
 		h.actionPkg.Infof("Running locally, will only print the form data to stdout")
 	}
 
-	for key, value := range r.Form {
+	// walk the declared fields rather than the form so that every field gets an
+	// output, even when the browser doesn't submit it (i.e. an empty multiselect)
+	var declaredFields []fields.Field
+	if h.fields != nil {
+		declaredFields = h.fields.Fields
+	}
+
+	// reject the submission before any outputs are set if a value contains characters
+	// the field doesn't allow (as the browser's validation can be bypassed) or a secret
+	// that shouldn't have been pasted in
+	rejectionReasons := []string{}
+	if invalidFields := getFieldsWithDisallowedCharacters(declaredFields, r.Form); len(invalidFields) > 0 {
+		rejectionReasons = append(rejectionReasons, fmt.Sprintf("The following fields contain characters that are not allowed: %s", strings.Join(invalidFields, ", ")))
+	}
+
+	// an approval portal has to be approved or rejected, with a comment saying why when
+	// it is rejected
+	var decision *approval.Decision
+	if h.approval {
+		decision, err = approval.NewDecision(&approval.NewDecisionRequest{
+			FormValue: r.Form.Get(approval.FormFieldDecision),
+			Comment:   r.Form.Get(approval.FormFieldComment),
+			Approver:  getApprover(submitter),
+		})
+
+		switch {
+		case errors.Is(err, iaiperrors.ErrApprovalCommentRequired):
+			rejectionReasons = append(rejectionReasons, "Please leave a comment saying why the request is rejected")
+		case err != nil:
+			rejectionReasons = append(rejectionReasons, "Please approve or reject the request")
+		}
+	}
+
+	if h.secretDetector.Policy() == secrets.PolicyReject {
+		if fieldsWithSecrets := h.getFieldsWithSecrets(declaredFields, r.Form); len(fieldsWithSecrets) > 0 {
+			rejectionReasons = append(rejectionReasons, fmt.Sprintf("The following fields look like they contain secrets, please remove them: %s", strings.Join(fieldsWithSecrets, ", ")))
+		}
+	}
+
+	if len(rejectionReasons) > 0 {
+		escapedRejectionReasons := []string{}
+		for _, reason := range rejectionReasons {
+			h.actionPkg.Warningf("Submission rejected. %s", reason)
+			h.session.Record("Submission rejected. " + reason)
+			h.recordAudit(newAuditValidationFailure(r, "", "Submission rejected. "+reason))
+			escapedRejectionReasons = append(escapedRejectionReasons, template.HTMLEscapeString(reason))
+		}
+
+		// the portal can be submitted again once the values are fixed
+		h.state.AbortSubmit()
+
+		http.Error(w, strings.Join(escapedRejectionReasons, "<br>"), http.StatusUnprocessableEntity)
+		return
+	}
+
+	// a quorum portal stays open until enough approvers have approved the request, or one
+	// of them rejects it. Earlier approvers only have their decision recorded, the values of
+	// the other fields come from the approver whose decision closes the portal
+	decisions := []approval.Decision{}
+	if decision != nil && h.tally != nil {
+		vote := decision
+
+		decision, err = h.tally.Add(vote, approverTeams)
+		if errors.Is(err, iaiperrors.ErrTallyAlreadyDecided) {
+			h.state.AbortSubmit()
+			h.renderPortalClosed(w, r, "submit")
+			return
+		}
+
+		if errors.Is(err, iaiperrors.ErrApproverAlreadyDecided) {
+			reason := fmt.Sprintf("%s has already decided on this request", vote.Approver)
+			h.actionPkg.Warningf("Decision rejected. %s", reason)
+			h.session.Record("Decision rejected. " + reason)
+			h.recordAudit(newAuditValidationFailure(r, "", "Decision rejected. "+reason))
+
+			h.state.AbortSubmit()
+
+			http.Error(w, template.HTMLEscapeString(reason), http.StatusUnprocessableEntity)
+			return
+		}
+
+		if decision == nil {
+			h.state.AbortSubmit()
+			h.renderDecisionRecorded(w, r, vote)
+			return
+		}
+
+		decisions = h.tally.Decisions()
+	}
+
+	if decision != nil && h.tally == nil {
+		decisions = append(decisions, *decision)
+	}
+
+	// a rejected request has no side effects, so nothing is committed or exported
+	isRejected := decision != nil && !decision.Approved()
+
+	for i := range declaredFields {
+
+		inputField := &declaredFields[i]
+		key := inputField.Label
+		outputName := inputField.OutputName()
 
 		// handle file/multifile inputs
 		if cacheDir := h.getInputFieldCacheDir(key); cacheDir != "" {
 
 			h.actionPkg.Infof("%s: %s", key, cacheDir)
+			typedValues[outputName] = cacheDir
+			encodedValues[outputName] = cacheDir
+			h.session.AddUpload(h.getInputFieldSessionUpload(key))
 
 			if !h.isRunningLocal {
 				// Can't use when running locally
-				h.actionPkg.SetOutput(key, cacheDir)
+				h.actionPkg.SetOutput(outputName, cacheDir)
+				h.actionPkg.SetOutput(outputName+fields.OutputNameSuffixManifest, getManifestPath(cacheDir))
+				h.setEscapedOutputs(inputField, cacheDir)
+			}
+
+			// commit the uploaded files to the repository if requested
+			commitTo := h.getInputFieldCommitTo(key)
+			if commitTo != nil && isRejected {
+				h.actionPkg.Infof("The request was rejected, skipping commit of the files uploaded to %s", key)
+				commitTo = nil
+			}
+
+			if commitTo != nil {
+				commitResult, err := h.commitUploadedFiles(key, commitTo)
+				if err != nil {
+					h.actionPkg.Errorf("Unable to commit the files uploaded to %s: %v", key, err)
+					failedCommits = append(failedCommits, key)
+				}
+
+				if commitResult != nil {
+					h.actionPkg.Infof("%s-commit-sha: %s", key, commitResult.commitSha)
+					if commitResult.pullRequestUrl != "" {
+						h.actionPkg.Infof("%s-pr-url: %s", key, commitResult.pullRequestUrl)
+					}
+
+					if !h.isRunningLocal {
+						h.actionPkg.SetOutput(outputName+fields.OutputNameSuffixCommitSha, commitResult.commitSha)
+						h.actionPkg.SetOutput(outputName+fields.OutputNameSuffixPullRequestUrl, commitResult.pullRequestUrl)
+					}
+				}
 			}
 
 			continue
 		}
 
-		h.actionPkg.Infof("%s: %s", key, value)
+		submittedValue, isSubmitted := r.Form[key]
+
+		// read only fields are disabled, so the browser never submits them
+		if !isSubmitted && inputField.Properties.ReadOnly {
+			submittedValue = []string{inputField.Properties.DefaultValue}
+		}
+
+		value := output.Transform(inputField, output.Normalise(inputField, submittedValue))
+
+		encodedValue, err := output.Encode(inputField, value)
+		if err != nil {
+			h.actionPkg.Warningf("Unable to encode %s in its output format, falling back to comma separated: %v", key, err)
+			encodedValue = strings.Join(value, ",")
+		}
+
+		// make sure sensitive values never show up in the logs
+		if inputField.Properties.Sensitive {
+			for _, v := range value {
+				output.Mask(h.actionPkg, v)
+			}
+			output.Mask(h.actionPkg, encodedValue)
+		}
+
+		// make sure secrets pasted into fields that aren't sensitive don't either
+		secretsFound := h.maskSecrets(inputField, value)
+
+		if h.redactLogValues {
+			h.actionPkg.Infof("%s: <redacted>", key)
+		} else {
+			h.actionPkg.Infof("%s: %s", key, value)
+		}
+
+		typedValues[outputName] = output.TypedValue(inputField, value)
+		encodedValues[outputName] = encodedValue
+
+		h.session.AddValue(session.FieldValue{
+			Label:     key,
+			Display:   inputField.Properties.Display,
+			Type:      inputField.Properties.Type,
+			Value:     encodedValue,
+			Sensitive: inputField.Properties.Sensitive || secretsFound || h.redactLogValues,
+		})
 
 		if !h.isRunningLocal {
 			// Can't use when running locally
-			h.actionPkg.SetOutput(key, strings.Join(value, ","))
+			h.setOutput(outputName, encodedValue)
+			h.setEscapedOutputs(inputField, encodedValue)
 		}
 	}
 
+	aggregatedValues, err := output.Aggregate(typedValues)
+	if err != nil {
+		h.actionPkg.Errorf("Unable to aggregate the submitted values: %v", err)
+	}
+
+	if err == nil && !h.isRunningLocal {
+		h.setOutput(output.AggregateKey, aggregatedValues)
+	}
+
+	if h.exporter != nil && h.exporter.Enabled() && isRejected {
+		h.actionPkg.Infof("The request was rejected, skipping export of the submitted values")
+	}
+
+	if h.exporter != nil && h.exporter.Enabled() && !isRejected {
+		err = h.exporter.Export(declaredFields, encodedValues, typedValues)
+		if err != nil {
+			h.actionPkg.Errorf("Unable to export the submitted values: %v", err)
+		}
+	}
+
+	if decision != nil {
+		h.recordDecision(decision, decisions)
+	}
+
+	h.session.SetSubmitter(submitter)
+	h.session.Close(session.OutcomeSubmitted)
+	h.state.CompleteSubmit()
+
+	submitAuditEntry := newAuditEntry(r, audit.EventSubmit)
+	submitAuditEntry.Detail = fmt.Sprintf("%d field(s) submitted", len(declaredFields))
+	if decision != nil {
+		submitAuditEntry.Detail = fmt.Sprintf("%s, %s", decision.Decision, submitAuditEntry.Detail)
+	}
+	h.recordAudit(submitAuditEntry)
+
 	actionContext, err := h.actionPkg.Context()
 	if err != nil {
 		h.actionPkg.Errorf("Unable to get action context: %v", zap.Error(err))
@@ -178,6 +631,17 @@ func (h *Handler) SubmitPortal(w http.ResponseWriter, r *http.Request) {
 		// }
 	}
 
+	// produce the signed receipt of what was submitted, failing the job if it can't be
+	// as later steps may rely on it for provenance
+	failedReceipt := false
+	if h.receiptSigner != nil {
+		err = h.writeReceipt(actionContext, decision, decisions)
+		if err != nil {
+			h.actionPkg.Errorf("Unable to produce the submission receipt: %v", err)
+			failedReceipt = true
+		}
+	}
+
 	// Parse template
 	parsedTemplates, err := template.ParseFS(h.embeddedContent, fmt.Sprintf("%sweb/ui/html/partials/responses/success.tmpl.html", h.embeddedContentFilePathPrefix))
 	if err != nil {
@@ -205,6 +669,21 @@ func (h *Handler) SubmitPortal(w http.ResponseWriter, r *http.Request) {
 	// put an exit command in background so that the action can finish
 	go func() {
 		time.Sleep(5 * time.Second)
+
+		h.closeAuditLog()
+
+		if len(failedCommits) > 0 {
+			h.actionPkg.Fatalf("Unable to commit the files uploaded to: %s", strings.Join(failedCommits, ", "))
+		}
+
+		if failedReceipt {
+			h.actionPkg.Fatalf("Unable to produce the submission receipt")
+		}
+
+		if decision != nil && decision.FailsStep(h.approvalPolicy) {
+			h.actionPkg.Fatalf("The request was rejected by %s", decision.Approver)
+		}
+
 		os.Exit(0)
 	}()
 }
@@ -217,7 +696,10 @@ func (h *Handler) UploadToPortal(w http.ResponseWriter, r *http.Request) {
 	var totalFiles int
 	var fileCount int = 0
 	var successFileUploads []string = []string{}
-	var failedFileUploads []string = []string{}
+	var failedFileUploads []FailedFileUpload = []FailedFileUpload{}
+	var touchedManifests map[string]*UploadManifest = map[string]*UploadManifest{}
+	var validationErrors map[string][]contentschema.ValidationError = map[string][]contentschema.ValidationError{}
+	var previews map[string]*contentschema.Preview = map[string]*contentschema.Preview{}
 	var cleanExistingCacheDir bool = true
 	var cacheCleanOverviewTmpl string = `
 Cache clean overview:
@@ -231,7 +713,17 @@ Cache clean overview:
 
 	h.actionPkg.Infof("Uploading File(s)...")
 
-	r.ParseMultipartForm(10 << 20)
+	// the rate limit handler caps the size of the body, so anything larger is refused
+	// rather than filling the runner's disk
+	var maxBytesErr *http.MaxBytesError
+	if err := r.ParseMultipartForm(10 << 20); errors.As(err, &maxBytesErr) {
+		h.actionPkg.Warningf("Refused an upload larger than the maximum upload size of %d bytes", maxBytesErr.Limit)
+		h.recordAudit(newAuditValidationFailure(r, "", fmt.Sprintf("Upload refused, it is larger than the maximum upload size of %d bytes", maxBytesErr.Limit)))
+
+		//nolint will set up default fallback later
+		getBaseResponseHandler().NewHTTPErrorResponse(w, errors.New(ErrKeyUploadTooLarge))
+		return
+	}
 
 	// If no files are uploaded, return an error
 	if r.MultipartForm == nil {
@@ -259,7 +751,7 @@ Cache clean overview:
 		file, handler, err := r.FormFile(k)
 		if err != nil {
 			h.actionPkg.Errorf("[%d of %d] Error Retrieving the file: %v", fileCount, totalFiles, err)
-			failedFileUploads = append(failedFileUploads, k)
+			failedFileUploads = append(failedFileUploads, FailedFileUpload{Name: k, Reason: "Unable to retrieve the file"})
 			continue
 		}
 
@@ -279,7 +771,7 @@ Cache clean overview:
 		fileBytes, err := io.ReadAll(file)
 		if err != nil {
 			h.actionPkg.Errorf("[%d of %d] Unable to read file: %v", fileCount, totalFiles, err)
-			failedFileUploads = append(failedFileUploads, handler.Filename)
+			failedFileUploads = append(failedFileUploads, FailedFileUpload{Name: handler.Filename, Reason: "Unable to read the file"})
 			continue
 		}
 
@@ -289,7 +781,7 @@ Cache clean overview:
 
 			status, totalFilesToDelete, totalFilesDeleted, deletedFiles, failedFiles, err := h.cleanUpCacheDir(inputFieldLabel, true)
 			if err != nil && err.Error() == ErrKeyUnableToRemoveCacheDirContents {
-				failedFileUploads = append(failedFileUploads, handler.Filename)
+				failedFileUploads = append(failedFileUploads, FailedFileUpload{Name: handler.Filename, Reason: "Unable to clear previously uploaded files"})
 
 				h.actionPkg.Debugf(cacheCleanOverviewTmpl, status, totalFilesToDelete, totalFilesDeleted, deletedFiles, failedFiles, err)
 
@@ -319,25 +811,117 @@ Cache clean overview:
 			cleanExistingCacheDir = false
 		}
 
+		// shape the manifest entry for the file, a new manifest is started for
+		// each input field the first time it is seen in the upload request
+		manifest, ok := touchedManifests[inputFieldLabel]
+		if !ok {
+			manifest = h.resetInputFieldManifest(inputFieldLabel)
+			touchedManifests[inputFieldLabel] = manifest
+		}
+
+		fileChecksum := sha256.Sum256(fileBytes)
+		manifestFile := UploadManifestFile{
+			Name:        handler.Filename,
+			Size:        handler.Size,
+			ContentType: handler.Header.Get("Content-Type"),
+			Sha256:      hex.EncodeToString(fileChecksum[:]),
+			UploadedAt:  time.Now().UTC(),
+		}
+
+		// scan the file for malware (if enabled) before accepting it
+		if h.scanner != nil && h.scanner.Enabled() {
+			h.actionPkg.Debugf("  • Scanning file: %s", handler.Filename)
+
+			manifestFile.Scan = h.scanner.Scan(handler.Filename, fileBytes)
+			if !manifestFile.Scan.Clean() {
+				h.actionPkg.Warningf("[%d of %d] File '%s' rejected: %s", fileCount, totalFiles, handler.Filename, manifestFile.Scan.Reason())
+				h.recordAudit(newAuditValidationFailure(r, inputFieldLabel, fmt.Sprintf("File '%s' rejected: %s", handler.Filename, manifestFile.Scan.Reason())))
+
+				manifestFile.Status = ManifestFileStatusRejected
+				manifestFile.Reason = manifestFile.Scan.Reason()
+				manifest.Files = append(manifest.Files, manifestFile)
+				failedFileUploads = append(failedFileUploads, FailedFileUpload{Name: handler.Filename, Reason: manifestFile.Reason})
+				continue
+			}
+		}
+
+		// validate structured files against the field's content schema (if any)
+		if contentSchema := h.getInputFieldContentSchema(inputFieldLabel); contentSchema != nil {
+			validationResult := contentschema.Validate(contentSchema, handler.Filename, fileBytes)
+			previews[handler.Filename] = validationResult.Preview
+
+			if !validationResult.Valid() {
+				h.actionPkg.Errorf("[%d of %d] File '%s' does not match the content schema for input field: %s", fileCount, totalFiles, handler.Filename, inputFieldLabel)
+				for _, validationError := range validationResult.Errors {
+					h.actionPkg.Debugf("  • %s: %s", validationError.Location, validationError.Message)
+				}
+
+				h.recordAudit(newAuditValidationFailure(r, inputFieldLabel, fmt.Sprintf("File '%s' does not match the content schema", handler.Filename)))
+
+				validationErrors[handler.Filename] = validationResult.Errors
+				manifestFile.Status = ManifestFileStatusRejected
+				manifestFile.Reason = "File does not match the content schema"
+				manifest.Files = append(manifest.Files, manifestFile)
+				failedFileUploads = append(failedFileUploads, FailedFileUpload{Name: handler.Filename, Reason: manifestFile.Reason})
+				continue
+			}
+		}
+
+		// encrypt the file (if enabled) so that only the holder of the private key
+		// can read it once it is in the cache directory
+		manifestFile.StoredName = handler.Filename
+		if len(h.encryptionRecipients) > 0 {
+			fileBytes, err = encryption.Encrypt(h.encryptionRecipients, fileBytes)
+			if err != nil {
+				h.actionPkg.Errorf("[%d of %d] Unable to encrypt file: %v", fileCount, totalFiles, err)
+				manifestFile.Status = ManifestFileStatusRejected
+				manifestFile.Reason = "Unable to encrypt the file"
+				manifest.Files = append(manifest.Files, manifestFile)
+				failedFileUploads = append(failedFileUploads, FailedFileUpload{Name: handler.Filename, Reason: manifestFile.Reason})
+				continue
+			}
+
+			manifestFile.Encrypted = true
+			manifestFile.StoredName = handler.Filename + encryption.EncryptedFileExtension
+		}
+
 		// create placeholder file in temp directory to hold uploaded file
 		inputCacheDir := h.getInputFieldCacheDir(inputFieldLabel)
-		err = os.WriteFile(fmt.Sprintf("%s/%s", inputCacheDir, handler.Filename), fileBytes, 0644)
+		err = os.WriteFile(fmt.Sprintf("%s/%s", inputCacheDir, manifestFile.StoredName), fileBytes, h.cache.FileMode())
 		if err != nil {
 			h.actionPkg.Errorf("[%d of %d] Unable to write file to input field cache dir: %s", fileCount, totalFiles, inputCacheDir)
-			failedFileUploads = append(failedFileUploads, handler.Filename)
+			manifestFile.Status = ManifestFileStatusRejected
+			manifestFile.Reason = "Unable to write the file to the cache directory"
+			manifest.Files = append(manifest.Files, manifestFile)
+			failedFileUploads = append(failedFileUploads, FailedFileUpload{Name: handler.Filename, Reason: manifestFile.Reason})
 			continue
 		}
 
 		// add file to successful uploads
+		manifestFile.Status = ManifestFileStatusAccepted
+		manifest.Files = append(manifest.Files, manifestFile)
 		successFileUploads = append(successFileUploads, handler.Filename)
 
 	}
 
+	// record the outcome of the upload in the manifest of each input field
+	for inputFieldLabel, manifest := range touchedManifests {
+		err := writeManifest(manifest, h.cache.FileMode())
+		if err != nil {
+			h.actionPkg.Errorf("Unable to write upload manifest for input field: %s", inputFieldLabel)
+		}
+
+		h.recordAudit(newAuditUpload(r, manifest))
+	}
+
 	h.actionPkg.Infof("Successfully uploaded %d of %d files!\n\n", len(successFileUploads), totalFiles)
+	h.session.Record(fmt.Sprintf("%d of %d file(s) uploaded", len(successFileUploads), totalFiles))
 
 	response := UploadToPortalResponse{
-		UploadedFiles: successFileUploads,
-		FailedFiles:   failedFileUploads,
+		UploadedFiles:    successFileUploads,
+		FailedFiles:      failedFileUploads,
+		ValidationErrors: validationErrors,
+		Previews:         previews,
 	}
 
 	// TODO: better handle these failure/ partial failure situation
@@ -396,7 +980,20 @@ func (h *Handler) ResetUpload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the reset files are no longer part of the upload
+	manifest := h.resetInputFieldManifest(inputFieldLabel)
+	err = writeManifest(manifest, h.cache.FileMode())
+	if err != nil {
+		h.actionPkg.Errorf("Unable to write upload manifest for input field: %s", inputFieldLabel)
+	}
+
 	h.actionPkg.Infof("Cache directory contents reseted for input field label: %s\n\n", inputFieldLabel)
+
+	resetAuditEntry := newAuditEntry(r, audit.EventReset)
+	resetAuditEntry.Field = inputFieldLabel
+	resetAuditEntry.Detail = fmt.Sprintf("%d of %d file(s) removed", totalFilesDeleted, totalFilesToDelete)
+	h.recordAudit(resetAuditEntry)
+
 	//nolint will set up default fallback later
 	getBaseResponseHandler().NewHTTPDataResponse(w, http.StatusOK, &ResetUploadResponse{
 		Status:             status,
@@ -503,6 +1100,235 @@ func (h *Handler) getInputFieldCacheDir(inputFieldName string) string {
 	return h.inputFieldLabelToCacheDirMapping[inputFieldName]
 }
 
+// resetInputFieldManifest replaces the manifest of the given input field with an empty one
+func (h *Handler) resetInputFieldManifest(inputFieldName string) *UploadManifest {
+	h.uploadManifestsMutex.Lock()
+	defer h.uploadManifestsMutex.Unlock()
+
+	manifest := &UploadManifest{
+		Label:    inputFieldName,
+		CacheDir: h.getInputFieldCacheDir(inputFieldName),
+		Files:    []UploadManifestFile{},
+	}
+	h.uploadManifests[inputFieldName] = manifest
+
+	return manifest
+}
+
+// setOutput sets the output, writing the value to a file (and setting its path as the
+// <key>-file output) when it is too large to be set as an output
+func (h *Handler) setOutput(key, value string) {
+	if h.spiller == nil || !h.spiller.ShouldSpill(value) {
+		h.actionPkg.SetOutput(key, value)
+		return
+	}
+
+	spillPath, err := h.spiller.Spill(key, value)
+	if err != nil {
+		h.actionPkg.Errorf("Unable to write the value of %s to a file, setting it as an output instead: %v", key, err)
+		h.actionPkg.SetOutput(key, value)
+		return
+	}
+
+	spillKey := key + output.SpillFileKeySuffix
+	h.actionPkg.Warningf("The value of %s (%d bytes) is too large for an output, it has been written to %s instead. Use the %s output to read it", key, len(value), spillPath, spillKey)
+	h.actionPkg.SetOutput(key, "")
+	h.actionPkg.SetOutput(spillKey, spillPath)
+}
+
+// setEscapedOutputs sets an additional output for every way the field asks for its
+// value to be escaped, i.e. <label>-shell
+func (h *Handler) setEscapedOutputs(inputField *fields.Field, value string) {
+	for _, escape := range inputField.Properties.EscapedOutputs {
+		escapedValue := output.Escape(escape, value)
+
+		// escaping can split the value up, so the masked value may no longer match
+		if inputField.Properties.Sensitive {
+			output.Mask(h.actionPkg, escapedValue)
+		}
+
+		h.setOutput(output.EscapedKey(inputField.OutputName(), escape), escapedValue)
+	}
+}
+
+// maskSecrets masks any secrets found in the values of a field that isn't sensitive, warning
+// that they were found. It returns whether any secrets were found
+func (h *Handler) maskSecrets(inputField *fields.Field, values []string) bool {
+	if !isSecretDetectionField(inputField) {
+		return false
+	}
+
+	matches := []secrets.Match{}
+	for _, value := range values {
+		matches = append(matches, h.secretDetector.Detect(value)...)
+	}
+
+	if len(matches) == 0 {
+		return false
+	}
+
+	for _, match := range matches {
+		output.Mask(h.actionPkg, match.Value)
+	}
+
+	h.actionPkg.Warningf(
+		"Possible secret (%s) found in %s, it has been masked in the logs. Mark the field as sensitive if it is expected to hold secrets",
+		strings.Join(secrets.Kinds(matches), ", "),
+		inputField.Label,
+	)
+
+	return true
+}
+
+// getFieldsWithSecrets returns the display names (and the kinds of secret found) of the fields
+// that aren't sensitive, but whose submitted values look like they contain secrets
+func (h *Handler) getFieldsWithSecrets(declaredFields []fields.Field, form map[string][]string) []string {
+	fieldsWithSecrets := []string{}
+
+	for i := range declaredFields {
+		field := &declaredFields[i]
+		if !isSecretDetectionField(field) {
+			continue
+		}
+
+		matches := []secrets.Match{}
+		for _, value := range form[field.Label] {
+			matches = append(matches, h.secretDetector.Detect(value)...)
+		}
+
+		if len(matches) > 0 {
+			fieldsWithSecrets = append(fieldsWithSecrets, fmt.Sprintf("%s (%s)", getFieldDisplay(field), strings.Join(secrets.Kinds(matches), ", ")))
+		}
+	}
+
+	return fieldsWithSecrets
+}
+
+// isSecretDetectionField returns whether the values of the field are checked for secrets,
+// which is only done for free text fields that aren't expected to hold secrets
+func isSecretDetectionField(field *fields.Field) bool {
+	if field.Properties.Sensitive {
+		return false
+	}
+
+	return field.Properties.Type == "text" || field.Properties.Type == "textarea"
+}
+
+// getFieldDisplay returns the display name of the field, falling back to its label
+func getFieldDisplay(field *fields.Field) string {
+	if field.Properties.Display == "" {
+		return field.Label
+	}
+
+	return field.Properties.Display
+}
+
+// getFieldsWithDisallowedCharacters returns the display names of the fields whose submitted
+// values contain characters their allowedCharacters policy doesn't allow
+func getFieldsWithDisallowedCharacters(declaredFields []fields.Field, form map[string][]string) []string {
+	invalidFields := []string{}
+
+	for i := range declaredFields {
+		field := &declaredFields[i]
+		if field.Properties.AllowedCharacters == "" {
+			continue
+		}
+
+		for _, value := range form[field.Label] {
+			if !field.Properties.HasAllowedCharacters(value) {
+				invalidFields = append(invalidFields, getFieldDisplay(field))
+				break
+			}
+		}
+	}
+
+	return invalidFields
+}
+
+// getInputFieldSessionUpload returns the files uploaded to the given input field in the
+// shape recorded by the session
+func (h *Handler) getInputFieldSessionUpload(inputFieldName string) session.Upload {
+	h.uploadManifestsMutex.Lock()
+	defer h.uploadManifestsMutex.Unlock()
+
+	upload := session.Upload{
+		Label:        inputFieldName,
+		ManifestPath: getManifestPath(h.getInputFieldCacheDir(inputFieldName)),
+		Files:        []session.UploadFile{},
+	}
+
+	manifest := h.uploadManifests[inputFieldName]
+	if manifest == nil {
+		return upload
+	}
+
+	for _, file := range manifest.Files {
+		upload.Files = append(upload.Files, session.UploadFile{
+			Name:   file.Name,
+			Size:   file.Size,
+			Sha256: file.Sha256,
+			Status: file.Status,
+			Reason: file.Reason,
+		})
+	}
+
+	return upload
+}
+
+// getSubmitter returns who made the request, using the address the ngrok edge saw the
+// request come from, as the rest of X-Forwarded-For can be forged by the client
+func getSubmitter(r *http.Request) *session.Submitter {
+	submitter := &session.Submitter{
+		Ip:        getClientIp(r),
+		UserAgent: r.UserAgent(),
+	}
+
+	// the login is only known when visitors have to sign in
+	if identity := auth.IdentityFromContext(r.Context()); identity != nil {
+		submitter.Login = identity.Login
+	}
+
+	// the link is only known when visitors have to open the portal with a signed link
+	submitter.Link = link.RecipientFromContext(r.Context())
+
+	return submitter
+}
+
+// getApprover returns who made a decision, their GitHub login when known or else the IP
+// address they made it from
+func getApprover(submitter *session.Submitter) string {
+	if submitter.Login != "" {
+		return submitter.Login
+	}
+
+	return submitter.Ip
+}
+
+// getInputField returns the input field with the given name, or nil if there is no such field.
+func (h *Handler) getInputField(inputFieldName string) *fields.Field {
+	if h.fields == nil {
+		return nil
+	}
+
+	for i := range h.fields.Fields {
+		if h.fields.Fields[i].Label == inputFieldName {
+			return &h.fields.Fields[i]
+		}
+	}
+
+	return nil
+}
+
+// getInputFieldContentSchema returns the content schema for the given input field name,
+// or nil if the field does not have one.
+func (h *Handler) getInputFieldContentSchema(inputFieldName string) *fields.ContentSchema {
+	if inputField := h.getInputField(inputFieldName); inputField != nil {
+		return inputField.Properties.ContentSchema
+	}
+
+	return nil
+}
+
 // getBaseResponseHandler returns response handler configured with respective error map
 func getBaseResponseHandler() *reply.Replier {
 	return reply.NewReplier(append([]reply.ErrorManifest{}, portalErrorMap))
@@ -0,0 +1,242 @@
+package portal_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/approval"
+	"github.com/boasihq/interactive-inputs/internal/fields"
+	"github.com/boasihq/interactive-inputs/internal/output"
+	"github.com/boasihq/interactive-inputs/internal/portal"
+	"github.com/boasihq/interactive-inputs/internal/secrets"
+	"github.com/boasihq/interactive-inputs/internal/session"
+	"github.com/boasihq/interactive-inputs/internal/state"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_SubmitPortal_Submitter(t *testing.T) {
+
+	tests := []struct {
+		name                string
+		remoteAddr          string
+		forwardedFor        string
+		expectedApprover    string
+		expectedRefusedFrom string
+	}{
+		{
+			name:                "direct request",
+			remoteAddr:          "192.0.2.1:52000",
+			expectedApprover:    "192.0.2.1",
+			expectedRefusedFrom: "Anonymous (192.0.2.1)",
+		},
+		{
+			name:                "forwarded by the ngrok edge",
+			remoteAddr:          "127.0.0.1:52000",
+			forwardedFor:        "203.0.113.7",
+			expectedApprover:    "203.0.113.7",
+			expectedRefusedFrom: "Anonymous (203.0.113.7)",
+		},
+		{
+			name:                "forged forwarded address is not trusted",
+			remoteAddr:          "127.0.0.1:52000",
+			forwardedFor:        "198.51.100.66, 203.0.113.7",
+			expectedApprover:    "203.0.113.7",
+			expectedRefusedFrom: "Anonymous (203.0.113.7)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_REPOSITORY", "boasihq/interactive-inputs")
+			t.Setenv("GITHUB_RUN_ID", "1")
+
+			newRequest := func() *http.Request {
+				form := url.Values{approval.FormFieldDecision: {approval.FormValueApprove}}
+				r := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(form.Encode()))
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				r.RemoteAddr = tt.remoteAddr
+				if tt.forwardedFor != "" {
+					r.Header.Set("X-Forwarded-For", tt.forwardedFor)
+				}
+				return r
+			}
+
+			// the decision is recorded against the approver's address while the quorum
+			// isn't reached
+			tally := approval.NewTally(&approval.NewTallyRequest{Quorum: 2})
+			portalSession := session.New(&session.NewSessionRequest{})
+			portalState := state.New()
+			handler := newTestHandler(&portal.NewHandlerRequest{
+				Session:        portalSession,
+				State:          portalState,
+				Approval:       true,
+				ApprovalPolicy: approval.PolicyFailOnReject,
+				Tally:          tally,
+			})
+
+			handler.SubmitPortal(httptest.NewRecorder(), newRequest())
+
+			decisions := tally.Decisions()
+			if assert.Len(t, decisions, 1) {
+				assert.Equal(t, tt.expectedApprover, decisions[0].Approver)
+			}
+
+			// the visitor refused once the portal is closed is recorded by their address
+			assert.NoError(t, portalState.Cancel("", &session.Submitter{Ip: "192.0.2.99"}))
+
+			handler.SubmitPortal(httptest.NewRecorder(), newRequest())
+
+			timeline := portalSession.Timeline()
+			assert.Equal(t, "Refused to submit the portal for "+tt.expectedRefusedFrom+", it was already cancelled", timeline[len(timeline)-1].Description)
+		})
+	}
+}
+
+// newTestHandler returns a portal handler rendering the templates on disk
+func newTestHandler(r *portal.NewHandlerRequest) *portal.Handler {
+	r.EmbeddedContent = os.DirFS("../..")
+	r.EmbeddedContentFilePathPrefix = "internal/"
+
+	if r.ActionPkg == nil {
+		r.ActionPkg = githubactions.New(githubactions.WithWriter(io.Discard))
+	}
+
+	if r.SecretDetector == nil {
+		r.SecretDetector = secrets.NewDetector(&secrets.NewDetectorRequest{})
+	}
+
+	return portal.NewHandler(r)
+}
+
+func TestHandler_SubmitPortal_Decision(t *testing.T) {
+
+	tests := []struct {
+		name               string
+		decision           string
+		comment            string
+		expectedLog        string
+		expectedExportFile bool
+	}{
+		{
+			name:               "approved request commits and exports",
+			decision:           approval.FormValueApprove,
+			expectedLog:        "No files were uploaded to report, skipping commit to reports",
+			expectedExportFile: true,
+		},
+		{
+			name:               "rejected request neither commits nor exports",
+			decision:           approval.FormValueReject,
+			comment:            "Not this week",
+			expectedLog:        "The request was rejected, skipping commit of the files uploaded to report",
+			expectedExportFile: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_REPOSITORY", "boasihq/interactive-inputs")
+			t.Setenv("GITHUB_RUN_ID", "1")
+			t.Setenv("GITHUB_OUTPUT", filepath.Join(t.TempDir(), "output"))
+
+			actionLog := bytes.NewBuffer(nil)
+			action := githubactions.New(githubactions.WithWriter(actionLog))
+			exportFile := filepath.Join(t.TempDir(), "results.json")
+
+			handler := newTestHandler(&portal.NewHandlerRequest{
+				ActionPkg: action,
+				Fields: &fields.Fields{Fields: []fields.Field{
+					{Label: "notes", Properties: fields.FieldProperties{Type: "text"}},
+					{Label: "report", Properties: fields.FieldProperties{Type: "file", CommitTo: &fields.CommitTo{Path: "reports", Branch: "reports"}}},
+				}},
+				InputFieldLabelToCacheDirMapping: map[string]string{"report": t.TempDir()},
+				Exporter: output.NewExporter(&output.NewExporterRequest{
+					FilePath:  exportFile,
+					ActionPkg: action,
+				}),
+				Session:        session.New(&session.NewSessionRequest{}),
+				State:          state.New(),
+				Approval:       true,
+				ApprovalPolicy: approval.PolicyAlwaysSucceed,
+			})
+
+			form := url.Values{
+				"notes":                    {"Release notes"},
+				approval.FormFieldDecision: {tt.decision},
+				approval.FormFieldComment:  {tt.comment},
+			}
+			r := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+
+			handler.SubmitPortal(w, r)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, actionLog.String(), tt.expectedLog)
+
+			_, err := os.Stat(exportFile)
+			assert.Equal(t, tt.expectedExportFile, err == nil)
+		})
+	}
+}
+
+func TestHandler_SubmitPortal_WhileSubmitting(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		tally          *approval.Tally
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "approver is asked to try again while another decision is tallied",
+			tally:          approval.NewTally(&approval.NewTallyRequest{Quorum: 2}),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "Another approver's decision is being recorded, please try again in a moment",
+		},
+		{
+			name:           "approver is told the portal is being submitted without a quorum",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "Already Being Submitted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_REPOSITORY", "boasihq/interactive-inputs")
+			t.Setenv("GITHUB_RUN_ID", "1")
+
+			// another approver's decision is being handled
+			portalState := state.New()
+			assert.NoError(t, portalState.BeginSubmit("", &session.Submitter{Login: "octocat"}))
+
+			handler := newTestHandler(&portal.NewHandlerRequest{
+				Session:        session.New(&session.NewSessionRequest{}),
+				State:          portalState,
+				Approval:       true,
+				ApprovalPolicy: approval.PolicyFailOnReject,
+				Tally:          tt.tally,
+			})
+
+			form := url.Values{approval.FormFieldDecision: {approval.FormValueApprove}}
+			r := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+
+			handler.SubmitPortal(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			if tt.tally != nil {
+				assert.Empty(t, tt.tally.Decisions())
+			}
+		})
+	}
+}
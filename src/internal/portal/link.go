@@ -0,0 +1,121 @@
+package portal
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	iaiperrors "github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/link"
+	"github.com/boasihq/interactive-inputs/internal/security"
+	"github.com/boasihq/interactive-inputs/internal/session"
+)
+
+// LinkHandler manages letting visitors in to the portal with the signed links sent to the
+// notifiers
+type LinkHandler struct {
+
+	// actionPkg represents the githubactions package
+	actionPkg actionPkg
+
+	// signer creates and checks the signed links
+	signer *link.Signer
+
+	// session tracks what happens to the portal while it is open
+	session *session.Session
+}
+
+// NewLinkHandlerRequest holds everything needed to create a link handler
+type NewLinkHandlerRequest struct {
+
+	// ActionPkg represents the githubactions package
+	ActionPkg actionPkg
+
+	// Signer creates and checks the signed links
+	Signer *link.Signer
+
+	// Session tracks what happens to the portal while it is open
+	Session *session.Session
+}
+
+// NewLinkHandler returns link handler
+func NewLinkHandler(r *NewLinkHandlerRequest) *LinkHandler {
+	return &LinkHandler{
+		actionPkg: r.ActionPkg,
+		signer:    r.Signer,
+		session:   r.Session,
+	}
+}
+
+// RequireSignedLink is the middleware that stops visitors who didn't open the portal with
+// a valid signed link from using it. Opening a signed link sets a session cookie, so the
+// browser can keep using the portal without it, and the recipient of the link is added to
+// the request's context
+func (h *LinkHandler) RequireSignedLink(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if strings.HasPrefix(r.URL.Path, "/static/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if token := r.URL.Query().Get(link.QueryParameterName); token != "" {
+			h.redeem(w, r, token)
+			return
+		}
+
+		recipient, ok := h.signer.Recipient(r)
+		if !ok {
+			http.Error(w, "This portal can only be opened with the signed link sent to you", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(link.WithRecipient(r.Context(), recipient)))
+	})
+}
+
+// redeem checks the signed link the visitor opened, starting their session and sending
+// them on to the page without the link's token when it is valid
+func (h *LinkHandler) redeem(w http.ResponseWriter, r *http.Request, token string) {
+	submitter := getSubmitter(r)
+
+	recipient, err := h.signer.Redeem(token)
+	switch {
+	case errors.Is(err, iaiperrors.ErrSignedLinkExpired):
+		h.actionPkg.Warningf("Refused the expired %s link opened from %s", recipient, submitter.Ip)
+		h.session.Record(fmt.Sprintf("Refused the expired %s link opened from %s", recipient, submitter.Ip))
+		http.Error(w, "This link has expired", http.StatusForbidden)
+		return
+	case errors.Is(err, iaiperrors.ErrSignedLinkAlreadyUsed):
+		h.actionPkg.Warningf("Refused the already used %s link opened from %s", recipient, submitter.Ip)
+		h.session.Record(fmt.Sprintf("Refused the already used %s link opened from %s", recipient, submitter.Ip))
+		http.Error(w, "This link has already been used", http.StatusForbidden)
+		return
+	case err != nil:
+		h.actionPkg.Warningf("Refused an invalid link opened from %s", submitter.Ip)
+		h.session.Record(fmt.Sprintf("Refused an invalid link opened from %s", submitter.Ip))
+		http.Error(w, "This link is invalid", http.StatusForbidden)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     link.SessionCookieName,
+		Value:    h.signer.NewSession(recipient),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   security.IsSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	h.actionPkg.Infof("The portal was opened with the %s link from %s", recipient, submitter.Ip)
+	h.session.Record(fmt.Sprintf("Opened with the %s link from %s", recipient, submitter.Ip))
+
+	// drop the token, so it doesn't linger in the browser's address bar or history
+	redirectUrl := *r.URL
+	query := redirectUrl.Query()
+	query.Del(link.QueryParameterName)
+	redirectUrl.RawQuery = query.Encode()
+
+	http.Redirect(w, r, redirectUrl.RequestURI(), http.StatusFound)
+}
@@ -0,0 +1,92 @@
+package portal
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/cache"
+	"github.com/boasihq/interactive-inputs/internal/scanner"
+)
+
+const (
+	// ManifestFileStatusAccepted is the status of a file that was written to the cache directory
+	ManifestFileStatusAccepted = "accepted"
+
+	// ManifestFileStatusRejected is the status of a file that was rejected during upload
+	ManifestFileStatusRejected = "rejected"
+)
+
+// UploadManifest represents the record of the files uploaded for an input field
+type UploadManifest struct {
+
+	// Label is the label of the input field the files were uploaded to
+	Label string `json:"label"`
+
+	// CacheDir is the directory the accepted files were written to
+	CacheDir string `json:"cache_dir"`
+
+	// Files is the list of files uploaded to the input field
+	Files []UploadManifestFile `json:"files"`
+}
+
+// UploadManifestFile represents a single file uploaded to the portal
+type UploadManifestFile struct {
+
+	// Name is the name of the uploaded file
+	Name string `json:"name"`
+
+	// Size is the size of the uploaded file in bytes
+	Size int64 `json:"size"`
+
+	// ContentType is the content type reported by the browser
+	ContentType string `json:"content_type,omitempty"`
+
+	// Sha256 is the hex encoded SHA-256 checksum of the uploaded file (before encryption)
+	Sha256 string `json:"sha256"`
+
+	// StoredName is the name the file was written to the cache directory with
+	StoredName string `json:"stored_name,omitempty"`
+
+	// Encrypted is whether the file was encrypted before it was written to the cache directory
+	Encrypted bool `json:"encrypted"`
+
+	// Status is whether the file was accepted or rejected
+	Status string `json:"status"`
+
+	// Reason is why the file was rejected
+	Reason string `json:"reason,omitempty"`
+
+	// Scan is the verdict of the malware scan (if enabled)
+	Scan *scanner.Verdict `json:"scan,omitempty"`
+
+	// UploadedAt is when the file was uploaded
+	UploadedAt time.Time `json:"uploaded_at"`
+}
+
+// AcceptedFiles returns the files that were accepted and written to the cache directory
+func (m *UploadManifest) AcceptedFiles() []UploadManifestFile {
+	acceptedFiles := []UploadManifestFile{}
+	for _, file := range m.Files {
+		if file.Status == ManifestFileStatusAccepted {
+			acceptedFiles = append(acceptedFiles, file)
+		}
+	}
+
+	return acceptedFiles
+}
+
+// getManifestPath returns the path the manifest of the given cache directory is written to
+func getManifestPath(cacheDir string) string {
+	return cacheDir + cache.ManifestFileSuffix
+}
+
+// writeManifest writes the manifest as JSON next to its cache directory
+func writeManifest(manifest *UploadManifest, fileMode os.FileMode) error {
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(getManifestPath(manifest.CacheDir), manifestBytes, fileMode)
+}
@@ -0,0 +1,173 @@
+package portal
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/ratelimit"
+)
+
+const (
+	// DefaultMaxRequestSize is the largest body (in bytes) accepted by routes other than
+	// the upload one, on top of the room made for values large enough to be spilled
+	DefaultMaxRequestSize int64 = 1 << 20
+
+	// DefaultMaxUploadSize is the default largest body (in bytes) accepted by the upload route
+	DefaultMaxUploadSize int64 = 100 << 20
+
+	// uploadPath is the path of the upload route
+	uploadPath = "/api/v1/upload"
+
+	// formEncodingFactor is how much larger form encoding can make a value, i.e. a newline
+	// is sent as %0A
+	formEncodingFactor = 3
+)
+
+// RateLimitHandler manages protecting the portal from being hammered, by limiting how
+// many requests are accepted, how many uploads are handled at once and how large bodies
+// can be
+type RateLimitHandler struct {
+
+	// actionPkg represents the githubactions package
+	actionPkg actionPkg
+
+	// limiter limits how many requests are accepted per minute
+	limiter *ratelimit.Limiter
+
+	// maxRequestSize is the largest body (in bytes) accepted by routes other than the upload one
+	maxRequestSize int64
+
+	// maxUploadSize is the largest body (in bytes) accepted by the upload route
+	maxUploadSize int64
+
+	// uploadSlots holds a token for every upload being handled, nil when unlimited
+	uploadSlots chan struct{}
+}
+
+// NewRateLimitHandlerRequest holds everything needed to create a rate limit handler
+type NewRateLimitHandlerRequest struct {
+
+	// ActionPkg represents the githubactions package
+	ActionPkg actionPkg
+
+	// Limiter limits how many requests are accepted per minute
+	Limiter *ratelimit.Limiter
+
+	// MaxUploadSize is the largest body (in bytes) accepted by the upload route, defaults
+	// to DefaultMaxUploadSize
+	MaxUploadSize int64
+
+	// MaxConcurrentUploads is the number of uploads handled at the same time, unlimited
+	// when 0
+	MaxConcurrentUploads int
+
+	// OutputSpillThreshold is the size (in bytes) above which values are written to a file,
+	// the largest body accepted by routes other than the upload one is raised so that such
+	// values can be submitted
+	OutputSpillThreshold int
+}
+
+// NewRateLimitHandler returns rate limit handler
+func NewRateLimitHandler(r *NewRateLimitHandlerRequest) *RateLimitHandler {
+
+	var maxUploadSize int64 = DefaultMaxUploadSize
+	var uploadSlots chan struct{}
+
+	// a value has to be larger than the spill threshold to be written to a file, so the
+	// submission has to be able to carry one (once form encoded) along with the other values
+	var maxRequestSize int64 = DefaultMaxRequestSize + int64(r.OutputSpillThreshold)*formEncodingFactor
+
+	if r.MaxUploadSize > 0 {
+		maxUploadSize = r.MaxUploadSize
+	}
+
+	if r.MaxConcurrentUploads > 0 {
+		uploadSlots = make(chan struct{}, r.MaxConcurrentUploads)
+	}
+
+	return &RateLimitHandler{
+		actionPkg:      r.ActionPkg,
+		limiter:        r.Limiter,
+		maxRequestSize: maxRequestSize,
+		maxUploadSize:  maxUploadSize,
+		uploadSlots:    uploadSlots,
+	}
+}
+
+// Limit is the middleware that refuses requests over the limits with 429 responses,
+// telling clients when to try again with the Retry-After header, and caps the size of
+// every request's body
+func (h *RateLimitHandler) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		ip := getClientIp(r)
+
+		decision := h.limiter.Allow(ip)
+		if !decision.Allowed {
+			if decision.FirstRefusal && decision.Global {
+				h.actionPkg.Warningf("The portal is receiving too many requests, refusing requests for %s", decision.RetryAfter.Round(time.Second))
+			} else if decision.FirstRefusal {
+				h.actionPkg.Warningf("%s is making too many requests to the portal, refusing its requests for %s", ip, decision.RetryAfter.Round(time.Second))
+			}
+
+			tooManyRequests(w, decision.RetryAfter.Seconds(), "Too many requests, please try again later")
+			return
+		}
+
+		isUpload := r.URL.Path == uploadPath && r.Method == http.MethodPost
+
+		maxSize := h.maxRequestSize
+		if isUpload {
+			maxSize = h.maxUploadSize
+		}
+
+		if r.ContentLength > maxSize {
+			h.actionPkg.Warningf("Refused a %d byte %s request to %s from %s, the limit is %d bytes", r.ContentLength, r.Method, r.URL.Path, ip, maxSize)
+			http.Error(w, fmt.Sprintf("Request body too large, the limit is %d bytes", maxSize), http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxSize)
+
+		if isUpload && h.uploadSlots != nil {
+			select {
+			case h.uploadSlots <- struct{}{}:
+				defer func() { <-h.uploadSlots }()
+			default:
+				h.actionPkg.Warningf("Refused an upload from %s, %d uploads are already in progress", ip, cap(h.uploadSlots))
+				tooManyRequests(w, 5, "Too many uploads in progress, please try again shortly")
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// tooManyRequests responds with 429, telling the client how many seconds to wait before
+// trying again
+func tooManyRequests(w http.ResponseWriter, retryAfterSeconds float64, message string) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Max(1, math.Ceil(retryAfterSeconds)))))
+	http.Error(w, message, http.StatusTooManyRequests)
+}
+
+// getClientIp returns the IP address requests are limited by and submitters are recorded
+// with. This is the address the ngrok edge saw the request come from (the last one it
+// appended to X-Forwarded-For), as clients can send their own X-Forwarded-For to dodge the
+// limits or pose as someone else
+func getClientIp(r *http.Request) string {
+	if forwardedFor := r.Header.Get("X-Forwarded-For"); forwardedFor != "" {
+		addresses := strings.Split(forwardedFor, ",")
+		return strings.TrimSpace(addresses[len(addresses)-1])
+	}
+
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+
+	return r.RemoteAddr
+}
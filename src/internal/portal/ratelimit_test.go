@@ -0,0 +1,78 @@
+package portal_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/output"
+	"github.com/boasihq/interactive-inputs/internal/portal"
+	"github.com/boasihq/interactive-inputs/internal/ratelimit"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitHandler_Limit(t *testing.T) {
+
+	tests := []struct {
+		name                 string
+		outputSpillThreshold int
+		value                string
+		expectedStatus       int
+		expectedValue        string
+	}{
+		{
+			name:                 "value just over the spill threshold reaches the handler",
+			outputSpillThreshold: output.DefaultSpillThreshold,
+			value:                strings.Repeat("a", output.DefaultSpillThreshold+1),
+			expectedStatus:       http.StatusOK,
+			expectedValue:        strings.Repeat("a", output.DefaultSpillThreshold+1),
+		},
+		{
+			name:                 "form encoded value just over the spill threshold reaches the handler",
+			outputSpillThreshold: output.DefaultSpillThreshold,
+			value:                strings.Repeat("\n", output.DefaultSpillThreshold+1),
+			expectedStatus:       http.StatusOK,
+			expectedValue:        strings.Repeat("\n", output.DefaultSpillThreshold+1),
+		},
+		{
+			name:           "body over the limit is refused without spilling",
+			value:          strings.Repeat("a", int(portal.DefaultMaxRequestSize)),
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:                 "body over the limit is refused with spilling",
+			outputSpillThreshold: output.DefaultSpillThreshold,
+			value:                strings.Repeat("\n", int(portal.DefaultMaxRequestSize)+output.DefaultSpillThreshold*2),
+			expectedStatus:       http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rateLimitHandler := portal.NewRateLimitHandler(&portal.NewRateLimitHandlerRequest{
+				ActionPkg:            githubactions.New(githubactions.WithWriter(io.Discard)),
+				Limiter:              ratelimit.NewLimiter(&ratelimit.NewLimiterRequest{}),
+				OutputSpillThreshold: tt.outputSpillThreshold,
+			})
+
+			var receivedValue string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				receivedValue = r.FormValue("notes")
+			})
+
+			body := url.Values{"notes": {tt.value}}.Encode()
+			r := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(body))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+
+			rateLimitHandler.Limit(next).ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedValue, receivedValue)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package portal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/boasihq/interactive-inputs/internal/approval"
+	"github.com/boasihq/interactive-inputs/internal/receipt"
+	"github.com/sethvargo/go-githubactions"
+)
+
+// writeReceipt builds the receipt of what was submitted from the (closed) session and the
+// decisions made on the request (if any), signs it, writes it to the receipt file and sets
+// it as an output. Values of sensitive fields, values that contained secrets and, when
+// requested, every value are only recorded by their checksum
+func (h *Handler) writeReceipt(actionContext *githubactions.GitHubContext, decision *approval.Decision, decisions []approval.Decision) error {
+
+	fieldsSha256, err := receipt.HashDefinition(h.fields)
+	if err != nil {
+		return err
+	}
+
+	receiptRequest := &receipt.NewReceiptRequest{
+		FieldsSha256: fieldsSha256,
+		OpenedAt:     h.session.OpenedAt(),
+		SubmittedAt:  h.session.ClosedAt(),
+	}
+
+	if actionContext != nil {
+		repoOwner, repoName := actionContext.Repo()
+
+		receiptRequest.Repository = repoOwner + "/" + repoName
+		receiptRequest.Workflow = actionContext.Workflow
+		receiptRequest.RunId = actionContext.RunID
+		receiptRequest.RunAttempt = actionContext.RunAttempt
+		receiptRequest.Sha = actionContext.SHA
+	}
+
+	if submitter := h.session.Submitter(); submitter != nil {
+		receiptRequest.Submitter = submitter.Login
+	}
+
+	if decision != nil {
+		receiptRequest.Decision = decision.Decision
+		receiptRequest.Comment = decision.Comment
+		receiptRequest.Approver = decision.Approver
+	}
+
+	for _, approverDecision := range decisions {
+		receiptRequest.Approvers = append(receiptRequest.Approvers, receipt.Decision{
+			Decision: approverDecision.Decision,
+			Comment:  approverDecision.Comment,
+			Approver: approverDecision.Approver,
+		})
+	}
+
+	for _, value := range h.session.Values() {
+		receiptRequest.Values = append(receiptRequest.Values, receipt.NewValue(value.Label, value.Value, value.Sensitive || h.receiptHashValues))
+	}
+
+	for _, upload := range h.session.Uploads() {
+		receiptUpload := receipt.Upload{
+			Label: upload.Label,
+			Files: []receipt.UploadFile{},
+		}
+
+		for _, file := range upload.Files {
+			receiptUpload.Files = append(receiptUpload.Files, receipt.UploadFile{
+				Name:   file.Name,
+				Size:   file.Size,
+				Sha256: file.Sha256,
+				Status: file.Status,
+			})
+		}
+
+		receiptRequest.Uploads = append(receiptRequest.Uploads, receiptUpload)
+	}
+
+	submissionReceipt := receipt.New(receiptRequest)
+
+	err = h.receiptSigner.Sign(submissionReceipt)
+	if err != nil {
+		return err
+	}
+
+	encodedReceipt, err := json.Marshal(submissionReceipt)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(h.receiptFile), 0700)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(h.receiptFile, encodedReceipt, 0600)
+	if err != nil {
+		return err
+	}
+
+	h.actionPkg.Infof("%s: %s", receipt.OutputKeyReceiptFile, h.receiptFile)
+
+	// the receipt output isn't spilled, as its file is always written
+	if !h.isRunningLocal {
+		h.actionPkg.SetOutput(receipt.OutputKeyReceipt, string(encodedReceipt))
+		h.actionPkg.SetOutput(receipt.OutputKeyReceiptFile, h.receiptFile)
+	}
+
+	return nil
+}
@@ -1,5 +1,7 @@
 package portal
 
+import "github.com/boasihq/interactive-inputs/internal/contentschema"
+
 // UploadToPortalResponse represents the response for uploading files to the portal
 type UploadToPortalResponse struct {
 
@@ -9,8 +11,25 @@ type UploadToPortalResponse struct {
 	// UploadedFiles represents the list of files uploaded successfully
 	UploadedFiles []string `json:"uploaded_files,omitempty"`
 
-	// FailedFiles represents the list of files that failed to upload
-	FailedFiles []string `json:"failed_files,omitempty"`
+	// FailedFiles represents the list of files that failed to upload, along with the
+	// reason they were rejected
+	FailedFiles []FailedFileUpload `json:"failed_files,omitempty"`
+
+	// ValidationErrors represents the content schema violations found, keyed by file name
+	ValidationErrors map[string][]contentschema.ValidationError `json:"validation_errors,omitempty"`
+
+	// Previews represents a preview of the first rows of each structured file, keyed by file name
+	Previews map[string]*contentschema.Preview `json:"previews,omitempty"`
+}
+
+// FailedFileUpload represents a file that failed to upload
+type FailedFileUpload struct {
+
+	// Name is the name of the file
+	Name string `json:"name"`
+
+	// Reason is why the file was rejected
+	Reason string `json:"reason"`
 }
 
 // ResetUploadResponse represents the response for resetting the upload
@@ -15,6 +15,7 @@ type portalEventHandler interface {
 	CancelPortal(w http.ResponseWriter, r *http.Request)
 	UploadToPortal(w http.ResponseWriter, r *http.Request)
 	ResetUpload(w http.ResponseWriter, r *http.Request)
+	GetTally(w http.ResponseWriter, r *http.Request)
 }
 
 // uiHandler expected methods for valid ui handler
@@ -22,6 +23,35 @@ type uiHandler interface {
 	Home(w http.ResponseWriter, r *http.Request)
 }
 
+// rateLimitHandler expected methods for valid rate limit handler
+type rateLimitHandler interface {
+	Limit(next http.Handler) http.Handler
+}
+
+// auditHandler expected methods for valid audit handler
+type auditHandler interface {
+	Track(next http.Handler) http.Handler
+}
+
+// securityHandler expected methods for valid security handler
+type securityHandler interface {
+	Protect(next http.Handler) http.Handler
+}
+
+// linkHandler expected methods for valid link handler
+type linkHandler interface {
+	RequireSignedLink(next http.Handler) http.Handler
+}
+
+// authHandler expected methods for valid auth handler
+type authHandler interface {
+	Login(w http.ResponseWriter, r *http.Request)
+	Callback(w http.ResponseWriter, r *http.Request)
+	PollDevice(w http.ResponseWriter, r *http.Request)
+	Unlock(w http.ResponseWriter, r *http.Request)
+	RequireLogin(next http.Handler) http.Handler
+}
+
 // AttachRoutesRequest holds everything needed to attach portal
 // routes to router
 type AttachRoutesRequest struct {
@@ -35,6 +65,22 @@ type AttachRoutesRequest struct {
 	// UiHandler valid ui handler
 	UiHandler uiHandler
 
+	// RateLimitHandler valid rate limit handler, requests aren't limited when it is nil
+	RateLimitHandler rateLimitHandler
+
+	// SecurityHandler valid security handler, sets the security headers and checks the
+	// CSRF token of every request
+	SecurityHandler securityHandler
+
+	// LinkHandler valid link handler, visitors don't need a signed link when it is nil
+	LinkHandler linkHandler
+
+	// AuthHandler valid auth handler, visitors don't need to sign in when it is nil
+	AuthHandler authHandler
+
+	// AuditHandler valid audit handler, page views aren't recorded when it is nil
+	AuditHandler auditHandler
+
 	// EmbeddedContent embedded content of the web app
 	EmbeddedContent fs.FS
 
@@ -56,12 +102,41 @@ func AttachRoutes(request *AttachRoutesRequest) {
 		os.Exit(1)
 	}
 
+	// limits apply to every route before anything else, so refusing requests stays cheap
+	if request.RateLimitHandler != nil {
+		request.Router.Use(request.RateLimitHandler.Limit)
+	}
+
+	// security headers and CSRF checks apply to every route, including the sign in ones
+	request.Router.Use(request.SecurityHandler.Protect)
+
 	// Create path for handling static assets
 	request.Router.PathPrefix("/static/").Handler(http.StripPrefix("/static/", http.FileServer(http.FS(staticSubFS))))
 
+	// require visitors to open the portal with a signed link before anything else
+	if request.LinkHandler != nil {
+		request.Router.Use(request.LinkHandler.RequireSignedLink)
+	}
+
+	// require visitors to sign in with GitHub (or unlock the portal with the access code)
+	// before they can use the portal
+	if request.AuthHandler != nil {
+		request.Router.HandleFunc("/auth/login", request.AuthHandler.Login).Methods("GET")
+		request.Router.HandleFunc("/auth/callback", request.AuthHandler.Callback).Methods("GET")
+		request.Router.HandleFunc("/auth/device", request.AuthHandler.PollDevice).Methods("POST")
+		request.Router.HandleFunc("/auth/unlock", request.AuthHandler.Unlock).Methods("POST")
+		request.Router.Use(request.AuthHandler.RequireLogin)
+	}
+
+	// tag requests once who made them is known, so it can be recorded in the audit log
+	if request.AuditHandler != nil {
+		request.Router.Use(request.AuditHandler.Track)
+	}
+
 	request.Router.HandleFunc("/", request.UiHandler.Home).Methods("GET")
 	request.Router.HandleFunc("/submit", request.PortalEventHandler.SubmitPortal).Methods("POST")
 	request.Router.HandleFunc("/cancel", request.PortalEventHandler.CancelPortal).Methods("POST")
+	request.Router.HandleFunc("/tally", request.PortalEventHandler.GetTally).Methods("GET")
 
 	apiRouter := request.Router.PathPrefix("/api/v1").Subrouter()
 	apiRouter.HandleFunc("/upload", request.PortalEventHandler.UploadToPortal).Methods("POST", "OPTIONS")
@@ -0,0 +1,94 @@
+package portal
+
+import (
+	"fmt"
+	"net/http"
+	"text/template"
+
+	"github.com/boasihq/interactive-inputs/internal/state"
+	"go.uber.org/zap"
+)
+
+// renderPortalClosed responds to a submit or cancel request that lost the race to close
+// the portal, telling the visitor who closed it and when
+func (h *Handler) renderPortalClosed(w http.ResponseWriter, r *http.Request, action string) {
+
+	status := h.state.Status()
+	heading, message := getPortalClosedMessage(status)
+
+	submitter := getSubmitter(r)
+	h.actionPkg.Warningf("Refused to %s the portal for %s, it is already %s", action, submitter.Display(), status.State)
+	h.session.Record(fmt.Sprintf("Refused to %s the portal for %s, it was already %s", action, submitter.Display(), status.State))
+
+	h.renderMessage(w, "closed.tmpl.html", heading, message)
+}
+
+// renderMessage responds with the response template, showing the heading and (escaped)
+// message along with a link back to the run
+func (h *Handler) renderMessage(w http.ResponseWriter, templateName, heading, message string) {
+
+	additionalContext := map[string]string{
+		"JobUrl":  "",
+		"Heading": heading,
+
+		// text/template doesn't escape, and messages can hold the submitter's IP address
+		// (which comes from a header) or comments
+		"Message": template.HTMLEscapeString(message),
+	}
+
+	actionContext, err := h.actionPkg.Context()
+	if err == nil {
+		repoOwner, repoName := actionContext.Repo()
+
+		additionalContext["JobUrl"] = fmt.Sprintf(
+			"%s/%s/actions/runs/%d",
+			actionContext.ServerURL,
+			repoOwner+"/"+repoName,
+			actionContext.RunID,
+		)
+	}
+
+	// Parse template
+	parsedTemplates, err := template.ParseFS(h.embeddedContent, fmt.Sprintf("%sweb/ui/html/partials/responses/%s", h.embeddedContentFilePathPrefix, templateName))
+	if err != nil {
+		h.actionPkg.Errorf("Unable to parse referenced template: %v", zap.Error(err))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	// Added templates needed for htmx replacement
+	w.Header().Set("HX-Trigger", "template-executed")
+	w.Header().Set("HX-Trigger-After-Swap", "template-swapped")
+	w.Header().Set("Content-Type", "text/html")
+	w.WriteHeader(http.StatusOK)
+
+	// Write template to response
+	err = parsedTemplates.Execute(w, additionalContext)
+	if err != nil {
+		h.actionPkg.Errorf("Unable to execute parsed template: %v", zap.Error(err))
+	}
+}
+
+// getPortalClosedMessage returns the heading and message telling visitors who closed the
+// portal and when
+func getPortalClosedMessage(status state.Status) (string, string) {
+
+	by := "someone else"
+	if status.Submitter != nil {
+		by = status.Submitter.Display()
+	}
+	at := status.ChangedAt.Format("2006-01-02 15:04:05 UTC")
+
+	switch status.State {
+	case state.StateSubmitting:
+		return "Already Being Submitted", fmt.Sprintf("This portal is already being submitted by %s (since %s)", by, at)
+	case state.StateSubmitted:
+		return "Already Submitted", fmt.Sprintf("This portal was already submitted by %s at %s", by, at)
+	case state.StateCancelled:
+		return "Already Cancelled", fmt.Sprintf("This portal was already cancelled by %s at %s", by, at)
+	case state.StateExpired:
+		return "Portal Expired", fmt.Sprintf("This portal expired at %s", at)
+	}
+
+	return "Portal Closed", "This portal is no longer accepting input"
+}
@@ -0,0 +1,200 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// window is the period limits are expressed over
+	window = time.Minute
+
+	// staleAfter is how long a client's bucket is kept after its last request
+	staleAfter = 10 * time.Minute
+)
+
+// NewLimiterRequest is the request object for creating a new instance of Limiter
+type NewLimiterRequest struct {
+
+	// PerIpLimit is the number of requests a single IP address can make per minute,
+	// unlimited when 0
+	PerIpLimit int
+
+	// GlobalLimit is the number of requests accepted per minute across every IP address,
+	// unlimited when 0
+	GlobalLimit int
+
+	// Now returns the current time, defaults to time.Now
+	Now func() time.Time
+}
+
+// NewLimiter returns a new instance of Limiter
+func NewLimiter(r *NewLimiterRequest) *Limiter {
+
+	var now func() time.Time = time.Now
+
+	if r.Now != nil {
+		now = r.Now
+	}
+
+	limiter := &Limiter{
+		perIpLimit: r.PerIpLimit,
+		now:        now,
+		buckets:    make(map[string]*bucket),
+	}
+
+	if r.GlobalLimit > 0 {
+		limiter.global = newBucket(r.GlobalLimit, now())
+	}
+
+	return limiter
+}
+
+// Limiter limits how many requests are accepted per minute, from each IP address and in
+// total, refilling continuously so clients aren't all let back in at once
+type Limiter struct {
+
+	// perIpLimit is the number of requests a single IP address can make per minute
+	perIpLimit int
+
+	// now returns the current time
+	now func() time.Time
+
+	// mutex guards the buckets
+	mutex sync.Mutex
+
+	// global is the bucket shared by every IP address, nil when unlimited
+	global *bucket
+
+	// buckets mapping of IP address to its bucket
+	buckets map[string]*bucket
+
+	// lastPrunedAt is when stale buckets were last removed
+	lastPrunedAt time.Time
+}
+
+// Decision is the outcome of checking a request against the limits
+type Decision struct {
+
+	// Allowed is whether the request can be served
+	Allowed bool
+
+	// RetryAfter is how long the client should wait before trying again
+	RetryAfter time.Duration
+
+	// Global is whether the global (rather than the IP address's) limit was reached
+	Global bool
+
+	// FirstRefusal is whether this is the first request refused since the client was
+	// last allowed, so each throttling episode is only logged once
+	FirstRefusal bool
+}
+
+// Allow takes a request from the IP address's and the global allowance, returning
+// whether it can be served
+func (l *Limiter) Allow(ip string) Decision {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	l.prune(now)
+
+	var ipBucket *bucket
+	if l.perIpLimit > 0 {
+		ipBucket = l.buckets[ip]
+		if ipBucket == nil {
+			ipBucket = newBucket(l.perIpLimit, now)
+			l.buckets[ip] = ipBucket
+		}
+
+		if retryAfter := ipBucket.wait(now); retryAfter > 0 {
+			return ipBucket.refuse(retryAfter, false)
+		}
+	}
+
+	if l.global != nil {
+		if retryAfter := l.global.wait(now); retryAfter > 0 {
+			return l.global.refuse(retryAfter, true)
+		}
+		l.global.take()
+	}
+
+	if ipBucket != nil {
+		ipBucket.take()
+	}
+
+	return Decision{Allowed: true}
+}
+
+// prune removes the buckets of IP addresses that haven't made a request in a while, so
+// the limiter doesn't grow with every address that has ever reached the portal
+func (l *Limiter) prune(now time.Time) {
+	if now.Sub(l.lastPrunedAt) < staleAfter {
+		return
+	}
+
+	for ip, ipBucket := range l.buckets {
+		if now.Sub(ipBucket.updatedAt) > staleAfter {
+			delete(l.buckets, ip)
+		}
+	}
+
+	l.lastPrunedAt = now
+}
+
+// bucket is a token bucket holding up to a minute's worth of requests
+type bucket struct {
+
+	// capacity is the most tokens the bucket holds
+	capacity float64
+
+	// tokens is how many requests can currently be made
+	tokens float64
+
+	// refillRate is how many tokens are added per second
+	refillRate float64
+
+	// updatedAt is when the tokens were last refilled
+	updatedAt time.Time
+
+	// refusing is whether the last request was refused
+	refusing bool
+}
+
+// newBucket returns a full bucket allowing limit requests per minute
+func newBucket(limit int, now time.Time) *bucket {
+	return &bucket{
+		capacity:   float64(limit),
+		tokens:     float64(limit),
+		refillRate: float64(limit) / window.Seconds(),
+		updatedAt:  now,
+	}
+}
+
+// wait refills the bucket, returning how long until a token is available (0 when one
+// is available now)
+func (b *bucket) wait(now time.Time) time.Duration {
+	b.tokens = math.Min(b.capacity, b.tokens+now.Sub(b.updatedAt).Seconds()*b.refillRate)
+	b.updatedAt = now
+
+	if b.tokens >= 1 {
+		return 0
+	}
+
+	return time.Duration((1 - b.tokens) / b.refillRate * float64(time.Second))
+}
+
+// take uses up a token
+func (b *bucket) take() {
+	b.tokens--
+	b.refusing = false
+}
+
+// refuse records that a request was refused, returning the decision for it
+func (b *bucket) refuse(retryAfter time.Duration, global bool) Decision {
+	firstRefusal := !b.refusing
+	b.refusing = true
+
+	return Decision{RetryAfter: retryAfter, Global: global, FirstRefusal: firstRefusal}
+}
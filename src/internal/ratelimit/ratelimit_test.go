@@ -0,0 +1,111 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+// request is a request made to the limiter, after the clock has moved on by elapsed
+type request struct {
+	ip      string
+	elapsed time.Duration
+}
+
+func TestLimiter_Allow(t *testing.T) {
+
+	tests := []struct {
+		name              string
+		request           ratelimit.NewLimiterRequest
+		requests          []request
+		expectedDecisions []ratelimit.Decision
+	}{
+		{
+			name:    "unlimited",
+			request: ratelimit.NewLimiterRequest{},
+			requests: []request{
+				{ip: "192.0.2.1"},
+				{ip: "192.0.2.1"},
+				{ip: "192.0.2.1"},
+			},
+			expectedDecisions: []ratelimit.Decision{
+				{Allowed: true},
+				{Allowed: true},
+				{Allowed: true},
+			},
+		},
+		{
+			name:    "per IP limit reached",
+			request: ratelimit.NewLimiterRequest{PerIpLimit: 2},
+			requests: []request{
+				{ip: "192.0.2.1"},
+				{ip: "192.0.2.1"},
+				{ip: "192.0.2.1"},
+				{ip: "192.0.2.1"},
+				{ip: "192.0.2.2"},
+			},
+			expectedDecisions: []ratelimit.Decision{
+				{Allowed: true},
+				{Allowed: true},
+				{RetryAfter: 30 * time.Second, FirstRefusal: true},
+				{RetryAfter: 30 * time.Second},
+				{Allowed: true},
+			},
+		},
+		{
+			name:    "per IP limit refills",
+			request: ratelimit.NewLimiterRequest{PerIpLimit: 2},
+			requests: []request{
+				{ip: "192.0.2.1"},
+				{ip: "192.0.2.1"},
+				{ip: "192.0.2.1", elapsed: 10 * time.Second},
+				{ip: "192.0.2.1", elapsed: 20 * time.Second},
+				{ip: "192.0.2.1"},
+				{ip: "192.0.2.1"},
+			},
+			expectedDecisions: []ratelimit.Decision{
+				{Allowed: true},
+				{Allowed: true},
+				{RetryAfter: 20 * time.Second, FirstRefusal: true},
+				{Allowed: true},
+				{RetryAfter: 30 * time.Second, FirstRefusal: true},
+				{RetryAfter: 30 * time.Second},
+			},
+		},
+		{
+			name:    "global limit reached",
+			request: ratelimit.NewLimiterRequest{PerIpLimit: 2, GlobalLimit: 3},
+			requests: []request{
+				{ip: "192.0.2.1"},
+				{ip: "192.0.2.2"},
+				{ip: "192.0.2.3"},
+				{ip: "192.0.2.4"},
+			},
+			expectedDecisions: []ratelimit.Decision{
+				{Allowed: true},
+				{Allowed: true},
+				{Allowed: true},
+				{RetryAfter: 20 * time.Second, Global: true, FirstRefusal: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			tt.request.Now = func() time.Time { return now }
+
+			limiter := ratelimit.NewLimiter(&tt.request)
+
+			var decisions []ratelimit.Decision
+			for _, r := range tt.requests {
+				now = now.Add(r.elapsed)
+				decisions = append(decisions, limiter.Allow(r.ip))
+			}
+
+			assert.Equal(t, tt.expectedDecisions, decisions)
+		})
+	}
+}
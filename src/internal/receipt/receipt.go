@@ -0,0 +1,296 @@
+package receipt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/fields"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// Version is the version of the receipt format
+	Version = 1
+
+	// OutputKeyReceipt is the key of the output holding the signed receipt
+	OutputKeyReceipt = "receipt"
+
+	// OutputKeyReceiptFile is the key of the output holding the path the signed receipt was written to
+	OutputKeyReceiptFile = "receipt-file"
+
+	// DefaultFileName is the name of the file the receipt is written to when a path isn't provided
+	DefaultFileName = "interactive-inputs-receipt.json"
+)
+
+// Receipt is a tamper-evident record of what was submitted to the portal
+type Receipt struct {
+
+	// Version is the version of the receipt format
+	Version int `json:"version"`
+
+	// Repository is the repository the workflow ran in, i.e. owner/repo
+	Repository string `json:"repository"`
+
+	// Workflow is the name of the workflow
+	Workflow string `json:"workflow"`
+
+	// RunId is the ID of the workflow run
+	RunId int64 `json:"run_id"`
+
+	// RunAttempt is the attempt of the workflow run
+	RunAttempt int64 `json:"run_attempt"`
+
+	// Sha is the commit the workflow ran against
+	Sha string `json:"sha"`
+
+	// FieldsSha256 is the hex encoded SHA-256 checksum of the form definition
+	FieldsSha256 string `json:"fields_sha256"`
+
+	// OpenedAt is when the portal was opened (RFC 3339, UTC)
+	OpenedAt string `json:"opened_at"`
+
+	// SubmittedAt is when the portal was submitted (RFC 3339, UTC)
+	SubmittedAt string `json:"submitted_at"`
+
+	// Submitter is the GitHub login of the submitter (if known)
+	Submitter string `json:"submitter,omitempty"`
+
+	// Decision is whether the request was approved or rejected (approval mode only)
+	Decision string `json:"decision,omitempty"`
+
+	// Comment is the comment left with the decision (approval mode only)
+	Comment string `json:"comment,omitempty"`
+
+	// Approver is who made the decision that closed the portal (approval mode only)
+	Approver string `json:"approver,omitempty"`
+
+	// Approvers is the list of decisions made on the request, in the order they were made,
+	// i.e. every approval counted towards the quorum (approval mode only)
+	Approvers []Decision `json:"approvers,omitempty"`
+
+	// Values is the list of submitted values
+	Values []Value `json:"values"`
+
+	// Uploads is the list of files uploaded to the portal's file fields
+	Uploads []Upload `json:"uploads"`
+
+	// Signature is the signature of the receipt (without the signature)
+	Signature *Signature `json:"signature,omitempty"`
+}
+
+// Value represents a submitted value
+type Value struct {
+
+	// Label is the label of the field
+	Label string `json:"label"`
+
+	// Value is the submitted value, left out when only its checksum is recorded
+	Value *string `json:"value,omitempty"`
+
+	// Sha256 is the hex encoded SHA-256 checksum of the submitted value
+	Sha256 string `json:"sha256"`
+}
+
+// Decision represents an approver's decision on the request
+type Decision struct {
+
+	// Decision is whether the request was approved or rejected
+	Decision string `json:"decision"`
+
+	// Comment is the comment left with the decision
+	Comment string `json:"comment"`
+
+	// Approver is who made the decision, their GitHub login when known or else the IP
+	// address they made it from
+	Approver string `json:"approver"`
+}
+
+// Upload represents the files uploaded to a file field
+type Upload struct {
+
+	// Label is the label of the field
+	Label string `json:"label"`
+
+	// Files is the list of files uploaded
+	Files []UploadFile `json:"files"`
+}
+
+// UploadFile represents a single uploaded file
+type UploadFile struct {
+
+	// Name is the name of the file
+	Name string `json:"name"`
+
+	// Size is the size of the file in bytes
+	Size int64 `json:"size"`
+
+	// Sha256 is the hex encoded SHA-256 checksum of the file
+	Sha256 string `json:"sha256"`
+
+	// Status is whether the file was accepted or rejected
+	Status string `json:"status"`
+}
+
+// Signature represents the signature of a receipt
+type Signature struct {
+
+	// Algorithm is the algorithm the receipt was signed with
+	Algorithm string `json:"algorithm"`
+
+	// KeyId identifies the key the receipt was signed with (Ed25519 only)
+	KeyId string `json:"key_id,omitempty"`
+
+	// Value is the base64 encoded signature
+	Value string `json:"value"`
+}
+
+// NewReceiptRequest is the request object for creating a new Receipt
+type NewReceiptRequest struct {
+
+	// Repository is the repository the workflow ran in, i.e. owner/repo
+	Repository string
+
+	// Workflow is the name of the workflow
+	Workflow string
+
+	// RunId is the ID of the workflow run
+	RunId int64
+
+	// RunAttempt is the attempt of the workflow run
+	RunAttempt int64
+
+	// Sha is the commit the workflow ran against
+	Sha string
+
+	// FieldsSha256 is the hex encoded SHA-256 checksum of the form definition
+	FieldsSha256 string
+
+	// OpenedAt is when the portal was opened
+	OpenedAt time.Time
+
+	// SubmittedAt is when the portal was submitted
+	SubmittedAt time.Time
+
+	// Submitter is the GitHub login of the submitter (if known)
+	Submitter string
+
+	// Decision is whether the request was approved or rejected (approval mode only)
+	Decision string
+
+	// Comment is the comment left with the decision (approval mode only)
+	Comment string
+
+	// Approver is who made the decision that closed the portal (approval mode only)
+	Approver string
+
+	// Approvers is the list of decisions made on the request, in the order they were made
+	// (approval mode only)
+	Approvers []Decision
+
+	// Values is the list of submitted values
+	Values []Value
+
+	// Uploads is the list of files uploaded to the portal's file fields
+	Uploads []Upload
+}
+
+// New returns a new, unsigned Receipt
+func New(r *NewReceiptRequest) *Receipt {
+
+	var values []Value = r.Values
+	var uploads []Upload = r.Uploads
+
+	if values == nil {
+		values = []Value{}
+	}
+
+	if uploads == nil {
+		uploads = []Upload{}
+	}
+
+	return &Receipt{
+		Version:      Version,
+		Repository:   r.Repository,
+		Workflow:     r.Workflow,
+		RunId:        r.RunId,
+		RunAttempt:   r.RunAttempt,
+		Sha:          r.Sha,
+		FieldsSha256: r.FieldsSha256,
+		OpenedAt:     r.OpenedAt.UTC().Format(time.RFC3339Nano),
+		SubmittedAt:  r.SubmittedAt.UTC().Format(time.RFC3339Nano),
+		Submitter:    r.Submitter,
+		Decision:     r.Decision,
+		Comment:      r.Comment,
+		Approver:     r.Approver,
+		Approvers:    r.Approvers,
+		Values:       values,
+		Uploads:      uploads,
+	}
+}
+
+// NewValue returns the value to record, leaving the value itself out (and only recording
+// its checksum) when hashOnly is true
+func NewValue(label, value string, hashOnly bool) Value {
+	recordedValue := Value{
+		Label:  label,
+		Sha256: Sha256(value),
+	}
+
+	if !hashOnly {
+		recordedValue.Value = &value
+	}
+
+	return recordedValue
+}
+
+// Canonical returns the canonical JSON encoding of the receipt without its signature,
+// which is what gets signed. Struct fields are always encoded in the same order, so the
+// encoding is stable as long as the receipt format version doesn't change
+func (r *Receipt) Canonical() ([]byte, error) {
+	unsigned := *r
+	unsigned.Signature = nil
+
+	return json.Marshal(unsigned)
+}
+
+// Parse decodes an encoded receipt, refusing keys the receipt format doesn't have (and
+// anything after the receipt) as they wouldn't be covered by the signature, so a receipt
+// with content added to it can't still be verified
+func Parse(encodedReceipt []byte) (*Receipt, error) {
+	decoder := json.NewDecoder(bytes.NewReader(encodedReceipt))
+	decoder.DisallowUnknownFields()
+
+	var parsedReceipt Receipt
+	err := decoder.Decode(&parsedReceipt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidReceiptProvided, err)
+	}
+
+	if _, err = decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("%w: unexpected content after the receipt", errors.ErrInvalidReceiptProvided)
+	}
+
+	return &parsedReceipt, nil
+}
+
+// HashDefinition returns the hex encoded SHA-256 checksum of the form definition
+func HashDefinition(definition *fields.Fields) (string, error) {
+	encodedDefinition, err := yaml.Marshal(definition)
+	if err != nil {
+		return "", err
+	}
+
+	return Sha256(string(encodedDefinition)), nil
+}
+
+// Sha256 returns the hex encoded SHA-256 checksum of the value
+func Sha256(value string) string {
+	checksum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(checksum[:])
+}
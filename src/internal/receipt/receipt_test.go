@@ -0,0 +1,381 @@
+package receipt_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/receipt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigner_Sign(t *testing.T) {
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	encodedPrivateKey, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	assert.NoError(t, err)
+	encodedPublicKey, err := x509.MarshalPKIXPublicKey(publicKey)
+	assert.NoError(t, err)
+
+	privateKeyPem := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: encodedPrivateKey})
+	publicKeyPem := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: encodedPublicKey})
+	seed := []byte(base64.StdEncoding.EncodeToString(privateKey.Seed()))
+
+	tests := []struct {
+		name          string
+		algorithm     string
+		signingKey    []byte
+		verifyingKey  []byte
+		verifyAs      string
+		tamper        func(r *receipt.Receipt)
+		expectedError error
+	}{
+		{
+			name:         "hmac",
+			algorithm:    receipt.AlgorithmHmacSha256,
+			signingKey:   []byte("s3cr3t"),
+			verifyingKey: []byte("s3cr3t"),
+		},
+		{
+			name:          "hmac with wrong secret",
+			algorithm:     receipt.AlgorithmHmacSha256,
+			signingKey:    []byte("s3cr3t"),
+			verifyingKey:  []byte("guess"),
+			expectedError: errors.ErrInvalidReceiptSignature,
+		},
+		{
+			name:         "ed25519 verified with public key",
+			algorithm:    receipt.AlgorithmEd25519,
+			signingKey:   privateKeyPem,
+			verifyingKey: publicKeyPem,
+		},
+		{
+			name:         "ed25519 seed verified with base64 public key",
+			algorithm:    receipt.AlgorithmEd25519,
+			signingKey:   seed,
+			verifyingKey: []byte(base64.StdEncoding.EncodeToString(publicKey)),
+		},
+		{
+			name:         "ed25519 verified with private key",
+			algorithm:    receipt.AlgorithmEd25519,
+			signingKey:   privateKeyPem,
+			verifyingKey: privateKeyPem,
+		},
+		{
+			name:         "tampered value",
+			algorithm:    receipt.AlgorithmEd25519,
+			signingKey:   privateKeyPem,
+			verifyingKey: publicKeyPem,
+			tamper: func(r *receipt.Receipt) {
+				tamperedValue := "production"
+				r.Values[0].Value = &tamperedValue
+			},
+			expectedError: errors.ErrInvalidReceiptSignature,
+		},
+		{
+			name:         "tampered upload",
+			algorithm:    receipt.AlgorithmHmacSha256,
+			signingKey:   []byte("s3cr3t"),
+			verifyingKey: []byte("s3cr3t"),
+			tamper: func(r *receipt.Receipt) {
+				r.Uploads[0].Files[0].Sha256 = "def"
+			},
+			expectedError: errors.ErrInvalidReceiptSignature,
+		},
+		{
+			name:          "algorithm mismatch",
+			algorithm:     receipt.AlgorithmHmacSha256,
+			signingKey:    publicKeyPem,
+			verifyingKey:  publicKeyPem,
+			verifyAs:      receipt.AlgorithmEd25519,
+			expectedError: errors.ErrInvalidReceiptSignature,
+		},
+		{
+			name: "unsigned",
+			tamper: func(r *receipt.Receipt) {
+				r.Signature = nil
+			},
+			signingKey:    []byte("s3cr3t"),
+			verifyingKey:  []byte("s3cr3t"),
+			expectedError: errors.ErrInvalidReceiptSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := receipt.NewSigner(&receipt.NewSignerRequest{Algorithm: tt.algorithm, Key: tt.signingKey})
+			assert.NoError(t, err)
+
+			r := newReceipt()
+			assert.NoError(t, signer.Sign(r))
+
+			if tt.tamper != nil {
+				tt.tamper(r)
+			}
+
+			verifyAs := tt.verifyAs
+			if verifyAs == "" {
+				verifyAs = tt.algorithm
+			}
+			if verifyAs == "" {
+				verifyAs = receipt.AlgorithmHmacSha256
+			}
+
+			err = receipt.Verify(r, verifyAs, tt.verifyingKey)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestNewSigner(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		algorithm     string
+		key           []byte
+		expectedError error
+	}{
+		{
+			name:          "empty hmac secret",
+			algorithm:     receipt.AlgorithmHmacSha256,
+			key:           []byte(" "),
+			expectedError: errors.ErrInvalidReceiptSigningKeyProvided,
+		},
+		{
+			name:          "ed25519 key that isn't a key",
+			algorithm:     receipt.AlgorithmEd25519,
+			key:           []byte("s3cr3t"),
+			expectedError: errors.ErrInvalidReceiptSigningKeyProvided,
+		},
+		{
+			name:          "unsupported algorithm",
+			algorithm:     "rsa",
+			key:           []byte("s3cr3t"),
+			expectedError: errors.ErrInvalidReceiptSigningKeyProvided,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := receipt.NewSigner(&receipt.NewSignerRequest{Algorithm: tt.algorithm, Key: tt.key})
+			assert.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestReceipt_Canonical(t *testing.T) {
+
+	r := newReceipt()
+
+	canonical, err := r.Canonical()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"version": 1,
+		"repository": "octo-org/octo-repo",
+		"workflow": "Deploy",
+		"run_id": 1234,
+		"run_attempt": 1,
+		"sha": "c0ffee",
+		"fields_sha256": "abc123",
+		"opened_at": "2024-05-01T12:00:00Z",
+		"submitted_at": "2024-05-01T12:01:30Z",
+		"submitter": "octocat",
+		"values": [
+			{"label": "environment", "value": "staging", "sha256": "`+receipt.Sha256("staging")+`"},
+			{"label": "api-key", "sha256": "`+receipt.Sha256("s3cr3t")+`"}
+		],
+		"uploads": [
+			{"label": "artifacts", "files": [{"name": "report.pdf", "size": 2048, "sha256": "abc", "status": "accepted"}]}
+		]
+	}`, string(canonical))
+
+	signer, err := receipt.NewSigner(&receipt.NewSignerRequest{Key: []byte("s3cr3t")})
+	assert.NoError(t, err)
+	assert.NoError(t, signer.Sign(r))
+
+	signedCanonical, err := r.Canonical()
+	assert.NoError(t, err)
+	assert.Equal(t, canonical, signedCanonical)
+}
+
+func TestReceipt_Canonical_Decision(t *testing.T) {
+
+	canonical, err := newApprovalReceipt().Canonical()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"version": 1,
+		"repository": "octo-org/octo-repo",
+		"workflow": "Deploy",
+		"run_id": 1234,
+		"run_attempt": 1,
+		"sha": "c0ffee",
+		"fields_sha256": "abc123",
+		"opened_at": "2024-05-01T12:00:00Z",
+		"submitted_at": "2024-05-01T12:01:30Z",
+		"submitter": "hubot",
+		"decision": "approved",
+		"comment": "Ship it",
+		"approver": "hubot",
+		"approvers": [
+			{"decision": "approved", "comment": "", "approver": "octocat"},
+			{"decision": "approved", "comment": "Ship it", "approver": "hubot"}
+		],
+		"values": [],
+		"uploads": []
+	}`, string(canonical))
+}
+
+func TestParse(t *testing.T) {
+
+	signer, err := receipt.NewSigner(&receipt.NewSignerRequest{Key: []byte("s3cr3t")})
+	assert.NoError(t, err)
+
+	r := newReceipt()
+	assert.NoError(t, signer.Sign(r))
+
+	encodedReceipt, err := json.Marshal(r)
+	assert.NoError(t, err)
+	encoded := string(encodedReceipt)
+
+	approvalReceipt := newApprovalReceipt()
+	assert.NoError(t, signer.Sign(approvalReceipt))
+
+	encodedApprovalReceipt, err := json.Marshal(approvalReceipt)
+	assert.NoError(t, err)
+	encodedApproval := string(encodedApprovalReceipt)
+
+	tests := []struct {
+		name                string
+		encoded             string
+		expectedError       error
+		expectedVerifyError error
+	}{
+		{
+			name:    "signed receipt",
+			encoded: encoded,
+		},
+		{
+			name:    "indented receipt",
+			encoded: indent(t, encodedReceipt),
+		},
+		{
+			name:          "added key",
+			encoded:       strings.Replace(encoded, `"version":1,`, `"version":1,"approved_by":"mallory",`, 1),
+			expectedError: errors.ErrInvalidReceiptProvided,
+		},
+		{
+			name:          "added key to a value",
+			encoded:       strings.Replace(encoded, `"label":"environment",`, `"label":"environment","note":"changed",`, 1),
+			expectedError: errors.ErrInvalidReceiptProvided,
+		},
+		{
+			name:    "signed approval receipt",
+			encoded: encodedApproval,
+		},
+		{
+			name:                "changed decision",
+			encoded:             strings.Replace(encodedApproval, `"decision":"approved","comment":"Ship it"`, `"decision":"rejected","comment":"Ship it"`, 1),
+			expectedVerifyError: errors.ErrInvalidReceiptSignature,
+		},
+		{
+			name:          "added key to an approver",
+			encoded:       strings.Replace(encodedApproval, `"approver":"octocat"`, `"approver":"octocat","team":"octo-org/admins"`, 1),
+			expectedError: errors.ErrInvalidReceiptProvided,
+		},
+		{
+			name:          "content after the receipt",
+			encoded:       encoded + `{"submitter":"mallory"}`,
+			expectedError: errors.ErrInvalidReceiptProvided,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsedReceipt, err := receipt.Parse([]byte(tt.encoded))
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+
+			err = receipt.Verify(parsedReceipt, receipt.AlgorithmHmacSha256, []byte("s3cr3t"))
+			if tt.expectedVerifyError != nil {
+				assert.ErrorIs(t, err, tt.expectedVerifyError)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func indent(t *testing.T, encoded []byte) string {
+	var indented bytes.Buffer
+	assert.NoError(t, json.Indent(&indented, encoded, "", "  "))
+
+	return indented.String()
+}
+
+func newReceipt() *receipt.Receipt {
+	openedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	return receipt.New(&receipt.NewReceiptRequest{
+		Repository:   "octo-org/octo-repo",
+		Workflow:     "Deploy",
+		RunId:        1234,
+		RunAttempt:   1,
+		Sha:          "c0ffee",
+		FieldsSha256: "abc123",
+		OpenedAt:     openedAt,
+		SubmittedAt:  openedAt.Add(90 * time.Second),
+		Submitter:    "octocat",
+		Values: []receipt.Value{
+			receipt.NewValue("environment", "staging", false),
+			receipt.NewValue("api-key", "s3cr3t", true),
+		},
+		Uploads: []receipt.Upload{
+			{
+				Label: "artifacts",
+				Files: []receipt.UploadFile{{Name: "report.pdf", Size: 2048, Sha256: "abc", Status: "accepted"}},
+			},
+		},
+	})
+}
+
+func newApprovalReceipt() *receipt.Receipt {
+	openedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	return receipt.New(&receipt.NewReceiptRequest{
+		Repository:   "octo-org/octo-repo",
+		Workflow:     "Deploy",
+		RunId:        1234,
+		RunAttempt:   1,
+		Sha:          "c0ffee",
+		FieldsSha256: "abc123",
+		OpenedAt:     openedAt,
+		SubmittedAt:  openedAt.Add(90 * time.Second),
+		Submitter:    "hubot",
+		Decision:     "approved",
+		Comment:      "Ship it",
+		Approver:     "hubot",
+		Approvers: []receipt.Decision{
+			{Decision: "approved", Approver: "octocat"},
+			{Decision: "approved", Comment: "Ship it", Approver: "hubot"},
+		},
+	})
+}
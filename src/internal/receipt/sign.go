@@ -0,0 +1,232 @@
+package receipt
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+)
+
+const (
+	// AlgorithmEd25519 signs receipts with an Ed25519 private key, so they can be verified
+	// with the public key
+	AlgorithmEd25519 = "ed25519"
+
+	// AlgorithmHmacSha256 signs receipts with a shared secret
+	AlgorithmHmacSha256 = "hmac-sha256"
+)
+
+// ValidAlgorithms is the list of algorithms receipts can be signed with
+var ValidAlgorithms = []string{AlgorithmEd25519, AlgorithmHmacSha256}
+
+// NewSignerRequest is the request object for creating a new instance of Signer
+type NewSignerRequest struct {
+
+	// Algorithm is the algorithm receipts are signed with
+	Algorithm string
+
+	// Key is the HMAC secret (surrounding whitespace is ignored), or the Ed25519 private key
+	// (PEM encoded PKCS #8, or the base64 encoded seed or private key)
+	Key []byte
+}
+
+// NewSigner returns a new instance of Signer, or an error if the key isn't valid for the algorithm
+func NewSigner(r *NewSignerRequest) (*Signer, error) {
+
+	var algorithm string = AlgorithmHmacSha256
+
+	if r.Algorithm != "" {
+		algorithm = r.Algorithm
+	}
+
+	signer := &Signer{
+		algorithm: algorithm,
+	}
+
+	switch algorithm {
+	case AlgorithmHmacSha256:
+		if len(bytes.TrimSpace(r.Key)) == 0 {
+			return nil, fmt.Errorf("%w: the HMAC secret is empty", errors.ErrInvalidReceiptSigningKeyProvided)
+		}
+		signer.hmacKey = hmacKey(r.Key)
+	case AlgorithmEd25519:
+		privateKey, err := parseEd25519PrivateKey(r.Key)
+		if err != nil {
+			return nil, err
+		}
+		signer.privateKey = privateKey
+	default:
+		return nil, fmt.Errorf("%w: unsupported algorithm '%s'", errors.ErrInvalidReceiptSigningKeyProvided, algorithm)
+	}
+
+	return signer, nil
+}
+
+// Signer signs receipts
+type Signer struct {
+
+	// algorithm is the algorithm receipts are signed with
+	algorithm string
+
+	// hmacKey is the shared secret used by hmac-sha256
+	hmacKey []byte
+
+	// privateKey is the private key used by ed25519
+	privateKey ed25519.PrivateKey
+}
+
+// Sign signs the canonical encoding of the receipt, replacing any existing signature
+func (s *Signer) Sign(r *Receipt) error {
+	canonical, err := r.Canonical()
+	if err != nil {
+		return err
+	}
+
+	signature := &Signature{Algorithm: s.algorithm}
+
+	switch s.algorithm {
+	case AlgorithmEd25519:
+		signature.KeyId = keyId(s.privateKey.Public().(ed25519.PublicKey))
+		signature.Value = base64.StdEncoding.EncodeToString(ed25519.Sign(s.privateKey, canonical))
+	default:
+		signature.Value = base64.StdEncoding.EncodeToString(hmacSha256(s.hmacKey, canonical))
+	}
+
+	r.Signature = signature
+
+	return nil
+}
+
+// Verify checks that the receipt was signed with the given algorithm and key, and hasn't been
+// modified since. The algorithm must be provided by the verifier rather than trusted from the
+// receipt, so that a receipt can't switch the algorithm it is checked with. For ed25519 the key
+// is the public key (PEM encoded PKIX, or base64 encoded), although the private key is accepted too
+func Verify(r *Receipt, algorithm string, key []byte) error {
+	if r.Signature == nil {
+		return fmt.Errorf("%w: the receipt isn't signed", errors.ErrInvalidReceiptSignature)
+	}
+
+	if r.Signature.Algorithm != algorithm {
+		return fmt.Errorf("%w: the receipt is signed with '%s', expected '%s'", errors.ErrInvalidReceiptSignature, r.Signature.Algorithm, algorithm)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(r.Signature.Value)
+	if err != nil {
+		return fmt.Errorf("%w: the signature isn't base64 encoded", errors.ErrInvalidReceiptSignature)
+	}
+
+	canonical, err := r.Canonical()
+	if err != nil {
+		return err
+	}
+
+	switch algorithm {
+	case AlgorithmEd25519:
+		publicKey, err := parseEd25519PublicKey(key)
+		if err != nil {
+			return err
+		}
+
+		if !ed25519.Verify(publicKey, canonical, signature) {
+			return errors.ErrInvalidReceiptSignature
+		}
+	case AlgorithmHmacSha256:
+		if !hmac.Equal(hmacSha256(hmacKey(key), canonical), signature) {
+			return errors.ErrInvalidReceiptSignature
+		}
+	default:
+		return fmt.Errorf("%w: unsupported algorithm '%s'", errors.ErrInvalidReceiptSignature, algorithm)
+	}
+
+	return nil
+}
+
+// hmacKey returns the HMAC secret without surrounding whitespace, so receipts signed with the
+// (trimmed) action input verify with the secret read from a file ending in a newline
+func hmacKey(key []byte) []byte {
+	return bytes.TrimSpace(key)
+}
+
+// hmacSha256 returns the HMAC-SHA256 of the content
+func hmacSha256(key, content []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(content)
+
+	return mac.Sum(nil)
+}
+
+// keyId returns a short identifier of the public key, so verifiers can tell which key
+// signed a receipt
+func keyId(publicKey ed25519.PublicKey) string {
+	checksum := sha256.Sum256(publicKey)
+	return hex.EncodeToString(checksum[:8])
+}
+
+// parseEd25519PrivateKey parses a PEM encoded PKCS #8 private key, or a base64 encoded seed
+// or private key
+func parseEd25519PrivateKey(key []byte) (ed25519.PrivateKey, error) {
+	if block, _ := pem.Decode(key); block != nil {
+		parsedKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrInvalidReceiptSigningKeyProvided, err)
+		}
+
+		privateKey, ok := parsedKey.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: the private key isn't an Ed25519 key", errors.ErrInvalidReceiptSigningKeyProvided)
+		}
+
+		return privateKey, nil
+	}
+
+	decodedKey, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(key)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: the private key must be PEM or base64 encoded", errors.ErrInvalidReceiptSigningKeyProvided)
+	}
+
+	switch len(decodedKey) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(decodedKey), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(decodedKey), nil
+	}
+
+	return nil, fmt.Errorf("%w: the private key must be a %d byte seed or %d byte key", errors.ErrInvalidReceiptSigningKeyProvided, ed25519.SeedSize, ed25519.PrivateKeySize)
+}
+
+// parseEd25519PublicKey parses a PEM encoded PKIX public key or a base64 encoded public key,
+// falling back to deriving the public key from a private key
+func parseEd25519PublicKey(key []byte) (ed25519.PublicKey, error) {
+	if block, _ := pem.Decode(key); block != nil && block.Type == "PUBLIC KEY" {
+		parsedKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrInvalidReceiptSigningKeyProvided, err)
+		}
+
+		publicKey, ok := parsedKey.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: the public key isn't an Ed25519 key", errors.ErrInvalidReceiptSigningKeyProvided)
+		}
+
+		return publicKey, nil
+	}
+
+	if decodedKey, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(key))); err == nil && len(decodedKey) == ed25519.PublicKeySize {
+		return ed25519.PublicKey(decodedKey), nil
+	}
+
+	privateKey, err := parseEd25519PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return privateKey.Public().(ed25519.PublicKey), nil
+}
@@ -2,21 +2,53 @@ package runner
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/fs"
 	"net/http"
 	"os"
+	"path/filepath"
 	"time"
 
+	"github.com/boasihq/interactive-inputs/internal/approval"
+	"github.com/boasihq/interactive-inputs/internal/audit"
+	"github.com/boasihq/interactive-inputs/internal/auth"
+	"github.com/boasihq/interactive-inputs/internal/cache"
 	"github.com/boasihq/interactive-inputs/internal/config"
+	"github.com/boasihq/interactive-inputs/internal/encryption"
 	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/github"
+	"github.com/boasihq/interactive-inputs/internal/link"
 	"github.com/boasihq/interactive-inputs/internal/notifier"
+	"github.com/boasihq/interactive-inputs/internal/output"
 	"github.com/boasihq/interactive-inputs/internal/portal"
+	"github.com/boasihq/interactive-inputs/internal/ratelimit"
+	"github.com/boasihq/interactive-inputs/internal/receipt"
+	"github.com/boasihq/interactive-inputs/internal/scanner"
+	"github.com/boasihq/interactive-inputs/internal/secrets"
+	"github.com/boasihq/interactive-inputs/internal/security"
+	"github.com/boasihq/interactive-inputs/internal/session"
+	"github.com/boasihq/interactive-inputs/internal/state"
+	"github.com/boasihq/interactive-inputs/internal/subcommand"
+	"github.com/boasihq/interactive-inputs/internal/tunnel"
 	webui "github.com/boasihq/interactive-inputs/internal/web"
 	"github.com/gorilla/mux"
+	"github.com/sethvargo/go-githubactions"
 	"go.uber.org/zap"
-	"golang.ngrok.com/ngrok"
-	nconfig "golang.ngrok.com/ngrok/config"
+)
+
+const (
+	// PostStateKey is the key of the state saved by the main step, its presence
+	// signals that the action is being invoked as the post step
+	PostStateKey = "IAIP_POST"
+
+	// CacheDirsStateKey is the key of the state holding the cache directories
+	// the post step should remove
+	CacheDirsStateKey = "IAIP_CACHE_DIRS"
+
+	// closingGracePeriod is how long a submission or cancellation that beat the timeout
+	// is given to finish closing the portal
+	closingGracePeriod = 10 * time.Second
 )
 
 func InvokeAction(ctx context.Context, ctxCancel context.CancelFunc, cfg *config.Config, embeddedContent fs.FS, embeddedContentFilePathPrefix string) error {
@@ -25,8 +57,6 @@ func InvokeAction(ctx context.Context, ctxCancel context.CancelFunc, cfg *config
 
 	var githubActionWorkingDir string = os.Getenv("GITHUB_WORKSPACE")
 	var isRunningLocal bool = os.Getenv("IAIP_LOCAL_RUN") != ""
-	var isInteractiveInputsCacheDirAvailable bool = false
-	var interactiveInputsCacheDir string
 	var inputFieldLabelToCacheDirMapping map[string]string = make(map[string]string)
 
 	if githubActionWorkingDir == "" {
@@ -78,13 +108,62 @@ func InvokeAction(ctx context.Context, ctxCancel context.CancelFunc, cfg *config
 		cfg.Action.Debugf("Discord Notifier Verification Succeeded")
 	}
 
+	/// Scanners
+	fileScanner := scanner.NewClamdScanner(&scanner.NewClamdScannerRequest{})
+
+	if cfg.ScannerClamdAddress != "" {
+		fileScanner = scanner.NewClamdScanner(&scanner.NewClamdScannerRequest{
+			Enabled:   true,
+			Address:   cfg.ScannerClamdAddress,
+			Timeout:   cfg.ScannerTimeout,
+			ActionPkg: cfg.Action,
+		})
+	}
+
+	if cfg.ScannerCommand != "" {
+		fileScanner = scanner.NewCommandScanner(&scanner.NewCommandScannerRequest{
+			Enabled:   true,
+			Command:   cfg.ScannerCommand,
+			Timeout:   cfg.ScannerTimeout,
+			ActionPkg: cfg.Action,
+		})
+	}
+
+	if fileScanner.Enabled() {
+		verifiedScannerErr := fileScanner.Verify()
+		if verifiedScannerErr != nil {
+			cfg.Action.Errorf("Upload Scanner Verification Failed")
+			return verifiedScannerErr
+		}
+
+		cfg.Action.Debugf("Upload Scanner Verification Succeeded")
+	}
+
+	/// Cache
+	var uploadCacheRootDir string = githubActionWorkingDir
+	if cfg.UploadCacheDir != "" {
+		uploadCacheRootDir = cfg.UploadCacheDir
+	}
+
+	uploadCache := cache.New(&cache.NewCacheRequest{
+		RootDir:    uploadCacheRootDir,
+		FileMode:   cfg.UploadCacheFileMode,
+		StaleAfter: cfg.UploadCacheStaleAfter,
+		CleanupOn:  cfg.UploadCacheCleanup,
+		ActionPkg:  cfg.Action,
+	})
+
+	// remove directories left behind by previous runs, i.e. on self-hosted
+	// runners where the workspace is reused
+	if totalSwept := uploadCache.SweepStale(); totalSwept > 0 {
+		cfg.Action.Infof("Removed %d stale cache directories left over from previous runs", totalSwept)
+	}
+
 	// Create cache directory mapping for all the file and
 	// multifile input fields defined in the config. We'll
 	// use this hold all the files uploaded by the user
 	// during the action run
 	if cfg.Fields != nil {
-		var err error
-		var baseCacheDir string = fmt.Sprintf("%s/.__interactive-inputs-cache", os.Getenv("GITHUB_WORKSPACE"))
 
 		// check fields for file and multifile input fields
 		for _, v := range cfg.Fields.Fields {
@@ -94,32 +173,264 @@ func InvokeAction(ctx context.Context, ctxCancel context.CancelFunc, cfg *config
 				continue
 			}
 
-			// create base cache directory for holding uploaded files
-			// if it doesn't exist
-			if !isInteractiveInputsCacheDirAvailable {
-				// create temp directory to hold uploaded files
-				err = os.MkdirAll(baseCacheDir, os.ModePerm)
-				if err != nil {
-					cfg.Action.Errorf("Unable to base cache directory: %v", zap.Error(err))
-					return err
-				}
-
-				cfg.Action.Debugf("Base cache directory created: %s", interactiveInputsCacheDir)
-				isInteractiveInputsCacheDirAvailable = true
-			}
-
 			// create sub-directory for holding uploaded files for
 			// for the current input field
 			cfg.Action.Debugf("Creating cache sub-directory for %s uploads", v.Label)
-			inputFieldCacheDir, err := os.MkdirTemp(baseCacheDir, fmt.Sprintf("%s-%d", v.Label, time.Now().UnixNano()))
+			inputFieldCacheDir, err := uploadCache.CreateInputFieldDir(v.Label)
 			if err != nil {
-				cfg.Action.Errorf("Unable to create temp directory: %v", zap.Error(err))
+				cfg.Action.Errorf("Unable to create cache directory: %v", zap.Error(err))
 				return err
 			}
 
 			// add mapping of input field label to cache sub-directory
 			inputFieldLabelToCacheDirMapping[v.Label] = inputFieldCacheDir
 		}
+
+		cfg.Action.Debugf("Base cache directory: %s", uploadCache.BaseDir())
+	}
+
+	// hand the cache directories over to the post step, which removes
+	// them once the job ends
+	if uploadCache.CleanupOn(cache.CleanupEventJobEnd) && len(inputFieldLabelToCacheDirMapping) > 0 {
+		cacheDirsState, err := json.Marshal(uploadCache.InputFieldDirs())
+		if err != nil {
+			cfg.Action.Errorf("Unable to save cache directories for the post step: %v", err)
+			return err
+		}
+
+		cfg.Action.SaveState(CacheDirsStateKey, string(cacheDirsState))
+	}
+
+	/// Encryption
+	encryptionRecipients, err := encryption.ParseRecipients(cfg.UploadEncryptionRecipients)
+	if err != nil {
+		cfg.Action.Errorf("Unable to parse upload encryption recipients: %v", err)
+		return err
+	}
+
+	if len(encryptionRecipients) > 0 {
+		cfg.Action.Infof("Uploaded files will be encrypted to %d recipient(s)", len(encryptionRecipients))
+	}
+
+	// expose the path to the binary so that later steps can use its helper
+	// subcommands, i.e. decrypt
+	if !isRunningLocal {
+		executablePath, err := os.Executable()
+		if err != nil {
+			cfg.Action.Warningf("Unable to determine the path of the action binary: %v", err)
+		}
+		if err == nil {
+			cfg.Action.SetOutput(subcommand.OutputKeyCliPath, executablePath)
+		}
+	}
+
+	/// GitHub
+	var githubApiUrl string = cfg.GithubApiUrl
+	if githubApiUrl == "" {
+		actionContext, err := cfg.Action.Context()
+		if err == nil {
+			githubApiUrl = actionContext.APIURL
+		}
+	}
+
+	githubClient := github.NewClient(&github.NewClientRequest{
+		ApiUrl:    githubApiUrl,
+		Token:     cfg.GithubToken,
+		ActionPkg: cfg.Action,
+	})
+
+	/// Auth
+	// GitHub's OAuth endpoints live on the server the workflow runs on unless told otherwise
+	var authOAuthUrl string = cfg.AuthOAuthUrl
+	var repository string
+	if actionContext, err := cfg.Action.Context(); err == nil {
+		if authOAuthUrl == "" {
+			authOAuthUrl = actionContext.ServerURL
+		}
+
+		repoOwner, repoName := actionContext.Repo()
+		repository = repoOwner + "/" + repoName
+	}
+
+	// the access code is only ever shared through the notifiers, so it is masked in case
+	// it finds its way into the logs
+	var accessCode string = cfg.AccessCode
+	if cfg.AuthMode == auth.ModeAccessCode && accessCode == "" {
+		generatedAccessCode, err := auth.GenerateAccessCode()
+		if err != nil {
+			cfg.Action.Errorf("Unable to generate the access code: %v", err)
+			return err
+		}
+
+		accessCode = generatedAccessCode
+		cfg.Action.AddMask(accessCode)
+	}
+
+	authenticator := auth.NewAuthenticator(&auth.NewAuthenticatorRequest{
+		Mode:                  cfg.AuthMode,
+		ClientId:              cfg.AuthClientId,
+		ClientSecret:          cfg.AuthClientSecret,
+		OAuthUrl:              authOAuthUrl,
+		ApiUrl:                githubApiUrl,
+		GithubToken:           cfg.GithubToken,
+		Repository:            repository,
+		AllowedUsers:          cfg.AuthAllowedUsers,
+		AllowedTeams:          cfg.AuthAllowedTeams,
+		MinimumPermission:     cfg.AuthMinimumPermission,
+		AccessCode:            accessCode,
+		AccessCodeMaxAttempts: cfg.AccessCodeMaxAttempts,
+	})
+
+	switch {
+	case authenticator.Mode() == auth.ModeAccessCode:
+		cfg.Action.Infof("Visitors will have to unlock the portal with the access code sent to the notifiers")
+	case authenticator.Enabled():
+		cfg.Action.Infof("Visitors will have to sign in with GitHub (%s flow) to use the portal", authenticator.Mode())
+	}
+
+	/// Exports
+	// GITHUB_ENV isn't available when running locally
+	valuesExporter := output.NewExporter(&output.NewExporterRequest{
+		Env:             cfg.ExportEnv && !isRunningLocal,
+		EnvPrefix:       cfg.ExportEnvPrefix,
+		EnvNameStyle:    cfg.ExportEnvNameStyle,
+		FilePath:        cfg.ExportFile,
+		FileFormat:      cfg.ExportFileFormat,
+		SensitivePolicy: cfg.ExportSensitive,
+		ActionPkg:       cfg.Action,
+	})
+
+	// oversized values are written to the runner's temporary directory, which is
+	// emptied at the end of every job
+	outputSpiller := output.NewSpiller(&output.NewSpillerRequest{
+		RootDir:   os.Getenv("RUNNER_TEMP"),
+		Threshold: cfg.OutputSpillThreshold,
+	})
+
+	/// Secret detection
+	secretDetector := secrets.NewDetector(&secrets.NewDetectorRequest{
+		Policy: cfg.SecretDetection,
+	})
+
+	/// Receipt
+	var receiptSigner *receipt.Signer
+	if cfg.ReceiptSigningKey != "" {
+		receiptSigner, err = receipt.NewSigner(&receipt.NewSignerRequest{
+			Algorithm: cfg.ReceiptSigningAlgorithm,
+			Key:       []byte(cfg.ReceiptSigningKey),
+		})
+		if err != nil {
+			cfg.Action.Errorf("Unable to create the receipt signer: %v", err)
+			return err
+		}
+	}
+
+	// the receipt is written to the runner's temporary directory unless told otherwise
+	receiptFile := cfg.ReceiptFile
+	if receiptFile == "" {
+		receiptFile = filepath.Join(getTempDir(), receipt.DefaultFileName)
+	}
+
+	/// Audit log
+	auditLogFile := cfg.AuditLogFile
+	if auditLogFile == "" {
+		auditLogFile = filepath.Join(getTempDir(), audit.DefaultFileName)
+	}
+
+	auditLogger, err := audit.NewLogger(&audit.NewLoggerRequest{
+		ActionPkg:  cfg.Action,
+		Path:       auditLogFile,
+		WebhookUrl: cfg.AuditLogWebhook,
+	})
+	if err != nil {
+		cfg.Action.Errorf("Unable to open the audit log: %v", err)
+		return err
+	}
+	defer auditLogger.Close()
+
+	cfg.Action.Infof("%s: %s", audit.OutputKeyAuditLogFile, auditLogFile)
+	if !isRunningLocal {
+		cfg.Action.SetOutput(audit.OutputKeyAuditLogFile, auditLogFile)
+	}
+
+	/// Session
+	portalSession := session.New(&session.NewSessionRequest{
+		Title: cfg.Title,
+	})
+
+	// outputs and the job summary aren't available when running locally
+	portalSession.OnClose(func(s *session.Session) {
+		metadataOutputs := s.Metadata().Outputs()
+		for _, key := range session.OutputKeys {
+			cfg.Action.Infof("%s: %s", key, metadataOutputs[key])
+			if !isRunningLocal {
+				cfg.Action.SetOutput(key, metadataOutputs[key])
+			}
+		}
+	})
+
+	if cfg.JobSummary && !isRunningLocal {
+		portalSession.OnClose(func(s *session.Session) {
+			cfg.Action.AddStepSummary(s.Markdown())
+		})
+	}
+
+	if cfg.NotifierCompletionMessage != "" {
+		portalSession.OnClose(func(s *session.Session) {
+			completionMessage, err := s.Metadata().Render(cfg.NotifierCompletionMessage)
+			if err != nil {
+				cfg.Action.Errorf("Unable to render the notifier completion message: %v", err)
+				return
+			}
+
+			if slackNotifier.Enabled() {
+				if _, err := slackNotifier.Notify(cfg.Title, completionMessage); err != nil {
+					cfg.Action.Errorf("Slack Notifier Notification Failed: %v", err)
+				}
+			}
+
+			if discordNotifier.Enabled() {
+				if _, err := discordNotifier.Notify(cfg.Title, completionMessage); err != nil {
+					cfg.Action.Errorf("Discord Notifier Notification Failed: %v", err)
+				}
+			}
+		})
+	}
+
+	// the notifiers are told what was decided, so nobody has to open the run to find out
+	if cfg.Approval {
+		portalSession.OnClose(func(s *session.Session) {
+			decision := s.Decision()
+			if decision == nil {
+				return
+			}
+
+			if slackNotifier.Enabled() {
+				if _, err := slackNotifier.Notify(cfg.Title, decision.Message()); err != nil {
+					cfg.Action.Errorf("Slack Notifier Notification Failed: %v", err)
+				}
+			}
+
+			if discordNotifier.Enabled() {
+				if _, err := discordNotifier.Notify(cfg.Title, decision.Message()); err != nil {
+					cfg.Action.Errorf("Discord Notifier Notification Failed: %v", err)
+				}
+			}
+		})
+	}
+
+	// only the first submission or cancellation closes the portal
+	portalState := state.New()
+
+	// a quorum portal collects the decisions of several approvers before it closes
+	var approvalTally *approval.Tally
+	if cfg.ApprovalQuorumEnabled() {
+		approvalTally = approval.NewTally(&approval.NewTallyRequest{
+			Quorum:        cfg.ApprovalQuorum,
+			RequiredUsers: cfg.ApprovalRequiredUsers,
+			RequiredTeams: cfg.ApprovalRequiredTeams,
+		})
+		cfg.Action.Infof("The portal stays open until %d approver(s) have approved the request, or one of them rejects it", cfg.ApprovalQuorum)
 	}
 
 	/// Handlers
@@ -129,44 +440,167 @@ func InvokeAction(ctx context.Context, ctxCancel context.CancelFunc, cfg *config
 		Config:                        cfg,
 	})
 
-	portalEventHandler := portal.NewHandler(cfg.Action, isRunningLocal, embeddedContent, embeddedContentFilePathPrefix, cfg.GithubToken, inputFieldLabelToCacheDirMapping)
+	portalEventHandler := portal.NewHandler(&portal.NewHandlerRequest{
+		ActionPkg:                        cfg.Action,
+		IsRunningLocal:                   isRunningLocal,
+		EmbeddedContent:                  embeddedContent,
+		EmbeddedContentFilePathPrefix:    embeddedContentFilePathPrefix,
+		GithubToken:                      cfg.GithubToken,
+		GithubClient:                     githubClient,
+		InputFieldLabelToCacheDirMapping: inputFieldLabelToCacheDirMapping,
+		Cache:                            uploadCache,
+		Fields:                           cfg.Fields,
+		Exporter:                         valuesExporter,
+		Spiller:                          outputSpiller,
+		SecretDetector:                   secretDetector,
+		RedactLogValues:                  cfg.RedactLogValues,
+		ReceiptSigner:                    receiptSigner,
+		ReceiptFile:                      receiptFile,
+		ReceiptHashValues:                cfg.ReceiptHashValues,
+		Session:                          portalSession,
+		State:                            portalState,
+		Approval:                         cfg.Approval,
+		ApprovalPolicy:                   cfg.ApprovalPolicy,
+		Tally:                            approvalTally,
+		Authenticator:                    authenticator,
+		Scanner:                          fileScanner,
+		EncryptionRecipients:             encryptionRecipients,
+		AuditLogger:                      auditLogger,
+	})
 
 	/// Routes
 	r := mux.NewRouter()
 
-	portal.AttachRoutes(&portal.AttachRoutesRequest{
+	securityGuard := security.NewGuard(&security.NewGuardRequest{
+		ActionPkg: cfg.Action,
+		Session:   portalSession,
+	})
+
+	rateLimitHandler := portal.NewRateLimitHandler(&portal.NewRateLimitHandlerRequest{
+		ActionPkg: cfg.Action,
+		Limiter: ratelimit.NewLimiter(&ratelimit.NewLimiterRequest{
+			PerIpLimit:  cfg.RateLimitPerIp,
+			GlobalLimit: cfg.RateLimitGlobal,
+		}),
+		MaxUploadSize:        int64(cfg.MaxUploadSize) << 20,
+		MaxConcurrentUploads: cfg.MaxConcurrentUploads,
+		OutputSpillThreshold: cfg.OutputSpillThreshold,
+	})
+
+	auditHandler := portal.NewAuditHandler(&portal.NewAuditHandlerRequest{
+		ActionPkg:   cfg.Action,
+		AuditLogger: auditLogger,
+	})
+
+	attachRoutesRequest := &portal.AttachRoutesRequest{
 		Router:                        r,
 		PortalEventHandler:            portalEventHandler,
 		UiHandler:                     uiHandler,
+		RateLimitHandler:              rateLimitHandler,
+		SecurityHandler:               securityGuard,
+		AuditHandler:                  auditHandler,
 		EmbeddedContent:               embeddedContent,
 		EmbeddedContentFilePathPrefix: embeddedContentFilePathPrefix,
 		ActionPkg:                     cfg.Action,
-	})
+	}
+
+	if authenticator.Enabled() {
+		attachRoutesRequest.AuthHandler = portal.NewAuthHandler(&portal.NewAuthHandlerRequest{
+			ActionPkg:                     cfg.Action,
+			Authenticator:                 authenticator,
+			EmbeddedContent:               embeddedContent,
+			EmbeddedContentFilePathPrefix: embeddedContentFilePathPrefix,
+			Title:                         cfg.Title,
+			Session:                       portalSession,
+		})
+	}
+
+	// the portal can only be opened with the links sent to the notifiers
+	var linkSigner *link.Signer
+	if cfg.SignedLinks {
+		signedLinkExpiry := cfg.SignedLinkExpiry
+		if signedLinkExpiry == 0 {
+			signedLinkExpiry = cfg.Timeout
+		}
+
+		linkSigner = link.NewSigner(&link.NewSignerRequest{
+			Expiry:    time.Duration(signedLinkExpiry) * time.Second,
+			SingleUse: cfg.SignedLinkSingleUse,
+		})
+
+		attachRoutesRequest.LinkHandler = portal.NewLinkHandler(&portal.NewLinkHandlerRequest{
+			ActionPkg: cfg.Action,
+			Signer:    linkSigner,
+			Session:   portalSession,
+		})
+
+		cfg.Action.Infof("Visitors will have to open the portal with the signed link sent to the notifiers, which expires after %d seconds", signedLinkExpiry)
+	}
+
+	portal.AttachRoutes(attachRoutesRequest)
 
 	/// Server
 	serverDone := make(chan error, 1)
 	serverInitMessageTmpl := "Your Interactive Inputs portal is reachable at: %s"
 	notifierSlackEnterInputMessageTmpl := "<%s|*Enter required input*>"
 	notifierDiscordEnterInputMessageTmpl := "[**Enter required input**](%s)"
+	notifierSlackAccessCodeMessage := ""
+	notifierDiscordAccessCodeMessage := ""
+	if authenticator.Mode() == auth.ModeAccessCode {
+		notifierSlackAccessCodeMessage = fmt.Sprintf("\nAccess code: *%s*", accessCode)
+		notifierDiscordAccessCodeMessage = fmt.Sprintf("\nAccess code: **%s**", accessCode)
+	}
 	universalNotifierFailedToSelfHost := "A failure has occurred while starting/running your self-hosted portal: %v"
 
+	// each notifier is sent its own signed link when they are enabled, so the timeline
+	// shows which one the portal was opened with
+	getNotifierPortalUrl := func(portalUrl, recipient string) (string, error) {
+		if linkSigner == nil {
+			return portalUrl, nil
+		}
+
+		return linkSigner.Sign(portalUrl, recipient)
+	}
+
 	// TODO: Add a flag to enable/disable the ngrok tunnel respsective
 	// of whether the action is running locally or not
 	if !isRunningLocal {
-		ln, err := ngrok.Listen(ctx,
-			nconfig.HTTPEndpoint(),
-			ngrok.WithAuthtoken(cfg.NgrokAuthtoken),
-		)
+		endpoint := tunnel.NewEndpoint(&tunnel.NewEndpointRequest{
+			Authtoken:           cfg.NgrokAuthtoken,
+			Domain:              cfg.NgrokDomain,
+			Region:              cfg.NgrokRegion,
+			Metadata:            cfg.NgrokMetadata,
+			OAuthProvider:       cfg.NgrokOAuthProvider,
+			OAuthAllowedEmails:  cfg.NgrokOAuthAllowedEmails,
+			OAuthAllowedDomains: cfg.NgrokOAuthAllowedDomains,
+			BasicAuth:           cfg.NgrokBasicAuth,
+			AllowCidrs:          cfg.NgrokAllowCidrs,
+			DenyCidrs:           cfg.NgrokDenyCidrs,
+		})
+
+		ln, err := endpoint.Listen(ctx)
 		if err != nil {
+			cfg.Action.Errorf("Unable to start the ngrok tunnel: %v", err)
 			return err
 		}
 
+		if endpoint.Protected() {
+			cfg.Action.Infof("Requests to the portal will be checked by ngrok before they reach it")
+		}
+
 		serverInitMessage := fmt.Sprintf(serverInitMessageTmpl, ln.URL())
+		portalSession.SetPortalUrl(ln.URL())
 
 		cfg.Action.Noticef(serverInitMessage)
 
 		if slackNotifier.Enabled() {
-			_, err := slackNotifier.Notify(cfg.Title, fmt.Sprintf(notifierSlackEnterInputMessageTmpl, ln.URL()))
+			slackPortalUrl, err := getNotifierPortalUrl(ln.URL(), link.RecipientSlack)
+			if err != nil {
+				cfg.Action.Errorf("Unable to sign the Slack Notifier link: %v", err)
+				return err
+			}
+
+			_, err = slackNotifier.Notify(cfg.Title, fmt.Sprintf(notifierSlackEnterInputMessageTmpl, slackPortalUrl)+notifierSlackAccessCodeMessage)
 			if err != nil {
 				cfg.Action.Errorf("Slack Notifier Notification Failed: %v", err)
 				return err
@@ -174,7 +608,13 @@ func InvokeAction(ctx context.Context, ctxCancel context.CancelFunc, cfg *config
 		}
 
 		if discordNotifier.Enabled() {
-			_, err := discordNotifier.Notify(cfg.Title, fmt.Sprintf(notifierDiscordEnterInputMessageTmpl, ln.URL()))
+			discordPortalUrl, err := getNotifierPortalUrl(ln.URL(), link.RecipientDiscord)
+			if err != nil {
+				cfg.Action.Errorf("Unable to sign the Discord Notifier link: %v", err)
+				return err
+			}
+
+			_, err = discordNotifier.Notify(cfg.Title, fmt.Sprintf(notifierDiscordEnterInputMessageTmpl, discordPortalUrl)+notifierDiscordAccessCodeMessage)
 			if err != nil {
 				cfg.Action.Errorf("Discord Notifier Notification Failed: %v", err)
 				return err
@@ -211,10 +651,17 @@ func InvokeAction(ctx context.Context, ctxCancel context.CancelFunc, cfg *config
 		server := &http.Server{Addr: localPort, Handler: r}
 		completeLocalUrl := fmt.Sprintf("http://localhost%s", localPort)
 		serverInitMessage := fmt.Sprintf(serverInitMessageTmpl, completeLocalUrl)
+		portalSession.SetPortalUrl(completeLocalUrl)
 
 		cfg.Action.Noticef(serverInitMessage)
 		if slackNotifier.Enabled() {
-			_, err := slackNotifier.Notify(cfg.Title, fmt.Sprintf(notifierSlackEnterInputMessageTmpl, completeLocalUrl))
+			slackPortalUrl, err := getNotifierPortalUrl(completeLocalUrl, link.RecipientSlack)
+			if err != nil {
+				cfg.Action.Errorf("Unable to sign the Slack Notifier link: %v", err)
+				return err
+			}
+
+			_, err = slackNotifier.Notify(cfg.Title, fmt.Sprintf(notifierSlackEnterInputMessageTmpl, slackPortalUrl)+notifierSlackAccessCodeMessage)
 			if err != nil {
 				cfg.Action.Errorf("Slack Notifier Notification Failed: %v", err)
 				return err
@@ -222,7 +669,13 @@ func InvokeAction(ctx context.Context, ctxCancel context.CancelFunc, cfg *config
 		}
 
 		if discordNotifier.Enabled() {
-			_, err := discordNotifier.Notify(cfg.Title, fmt.Sprintf(notifierDiscordEnterInputMessageTmpl, completeLocalUrl))
+			discordPortalUrl, err := getNotifierPortalUrl(completeLocalUrl, link.RecipientDiscord)
+			if err != nil {
+				cfg.Action.Errorf("Unable to sign the Discord Notifier link: %v", err)
+				return err
+			}
+
+			_, err = discordNotifier.Notify(cfg.Title, fmt.Sprintf(notifierDiscordEnterInputMessageTmpl, discordPortalUrl)+notifierDiscordAccessCodeMessage)
 			if err != nil {
 				cfg.Action.Errorf("Discord Notifier Notification Failed: %v", err)
 				return err
@@ -255,18 +708,77 @@ func InvokeAction(ctx context.Context, ctxCancel context.CancelFunc, cfg *config
 		}()
 	}
 
+	// expirePortal closes the portal once it times out. When a submission or cancellation
+	// beat the timeout, it is given time to finish (and exit) instead
+	expirePortal := func() {
+		if !portalState.Expire() {
+			cfg.Action.Infof("The portal timed out after it was %s, waiting for it to finish closing", portalState.Status().State)
+			time.Sleep(closingGracePeriod)
+		}
+
+		portalSession.Close(session.OutcomeTimedOut)
+		uploadCache.Cleanup(cache.CleanupEventTimeout)
+	}
+
 	select {
 	case err := <-serverDone:
+		if ctx.Err() == context.DeadlineExceeded {
+			expirePortal()
+		}
+
 		return handlePrettierTimeoutErrorMessage(err, cfg.Timeout)
 	case <-ctx.Done():
 		// Timeout occurred
 		ctxCancel() // Ensure all resources are cleaned up
 
+		if ctx.Err() == context.DeadlineExceeded {
+			expirePortal()
+		}
+
 		return handlePrettierTimeoutErrorMessage(ctx.Err(), cfg.Timeout)
 	}
 
 }
 
+// IsPostStep returns whether the action is being invoked as the post step, which
+// runs once the job ends
+func IsPostStep() bool {
+	return os.Getenv("STATE_"+PostStateKey) != ""
+}
+
+// InvokePostAction handles the post step by removing the cache directories saved
+// by the main step (if cleaning up when the job ends was requested)
+func InvokePostAction(action *githubactions.Action) error {
+
+	cacheDirsState := os.Getenv("STATE_" + CacheDirsStateKey)
+	if cacheDirsState == "" {
+		action.Debugf("No cached uploads to remove at the end of the job")
+		return nil
+	}
+
+	var cacheDirs []string
+	err := json.Unmarshal([]byte(cacheDirsState), &cacheDirs)
+	if err != nil {
+		action.Errorf("Unable to read cache directories saved by the main step: %v", err)
+		return err
+	}
+
+	action.Infof("Removing cached uploads (%s)", cache.CleanupEventJobEnd)
+	cache.RemoveDirs(cacheDirs, action)
+
+	return nil
+}
+
+// getTempDir returns the runner's temporary directory, falling back to the system's one
+// when running locally
+func getTempDir() string {
+	if tempDir := os.Getenv("RUNNER_TEMP"); tempDir != "" {
+		return tempDir
+	}
+
+	return os.TempDir()
+}
+
 // handlePrettierTimeoutErrorMessage is a helper function that prints a nicer error message
 // when the context deadline is exceeded. Otherwise, it returns the original error.
 func handlePrettierTimeoutErrorMessage(err error, timeout int) error {
@@ -0,0 +1,206 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/sethvargo/go-githubactions"
+)
+
+const (
+	// clamdChunkSize is the size of the chunks the file is streamed to clamd in
+	clamdChunkSize int = 64 * 1024
+
+	// DefaultTimeout is the default timeout (in seconds) used when scanning a file
+	DefaultTimeout int = 60
+)
+
+// NewClamdScannerRequest is the request object for creating a new
+// instance of a clamd Scanner.
+type NewClamdScannerRequest struct {
+	// Enabled whether the scanner is enabled or not
+	Enabled bool
+
+	// Address is the address of the clamd daemon, i.e. unix:///var/run/clamav/clamd.ctl
+	// or tcp://127.0.0.1:3310
+	Address string
+
+	// Timeout is how long (in seconds) a scan can take before it is considered failed
+	Timeout int
+
+	// ActionPkg represents the githubactions package
+	ActionPkg *githubactions.Action
+}
+
+// NewClamdScanner returns a new instance of a clamd Scanner
+func NewClamdScanner(r *NewClamdScannerRequest) Scanner {
+
+	var timeout int = DefaultTimeout
+
+	if r.Timeout > 0 {
+		timeout = r.Timeout
+	}
+
+	network, address := parseClamdAddress(r.Address)
+
+	return &ClamdScanner{
+		enabled: r.Enabled,
+		network: network,
+		address: address,
+		timeout: time.Duration(timeout) * time.Second,
+		action:  r.ActionPkg,
+	}
+}
+
+// ClamdScanner is a struct that implements the Scanner interface by streaming
+// files to a clamd daemon using the INSTREAM command
+type ClamdScanner struct {
+
+	// enabled whether the scanner is enabled or not
+	enabled bool
+
+	// network is the network used to reach clamd, either unix or tcp
+	network string
+
+	// address is the socket path or host:port of clamd
+	address string
+
+	// timeout is how long a scan can take before it is considered failed
+	timeout time.Duration
+
+	// action represents the githubactions package
+	action *githubactions.Action
+}
+
+// Scan streams the file to clamd and returns the verdict
+func (s *ClamdScanner) Scan(fileName string, content []byte) *Verdict {
+
+	verdict := &Verdict{Scanner: "clamd"}
+
+	response, err := s.command("zINSTREAM\x00", content)
+	verdict.ScannedAt = time.Now().UTC()
+	if err != nil {
+		s.action.Errorf("Unable to scan '%s' with clamd: %v", fileName, err)
+		verdict.Status = VerdictStatusError
+		verdict.Detail = err.Error()
+		return verdict
+	}
+
+	// clamd responds with "stream: OK", "stream: <signature> FOUND"
+	// or "<reason> ERROR"
+	response = strings.TrimPrefix(response, "stream: ")
+	switch {
+	case response == "OK":
+		verdict.Status = VerdictStatusClean
+	case strings.HasSuffix(response, " FOUND"):
+		verdict.Status = VerdictStatusInfected
+		verdict.Signature = strings.TrimSuffix(response, " FOUND")
+	default:
+		verdict.Status = VerdictStatusError
+		verdict.Detail = strings.TrimSpace(strings.TrimSuffix(response, "ERROR"))
+	}
+
+	return verdict
+}
+
+// Verify checks that clamd is reachable by sending the PING command, which is
+// expected to be answered with PONG
+func (s *ClamdScanner) Verify() error {
+
+	s.action.Debugf("Initiating the verification of the clamd scanner provided.")
+
+	response, err := s.command("zPING\x00", nil)
+	if err != nil {
+		s.action.Errorf("An error occured while making call to clamd. Error: %v", err)
+		return err
+	}
+
+	if response != "PONG" {
+		s.action.Errorf("Unexpected response from clamd: %s", response)
+		return errors.ErrUnexpectedScannerVerificationResponse
+	}
+
+	s.action.Debugf("Successfully verified the clamd scanner provided.")
+
+	return nil
+}
+
+// Enabled returns whether the scanner is enabled or not
+func (s *ClamdScanner) Enabled() bool {
+	return s.enabled
+}
+
+// command sends the given command to clamd, streaming the content (if any) in
+// length-prefixed chunks, and returns clamd's null terminated response
+func (s *ClamdScanner) command(command string, content []byte) (string, error) {
+
+	conn, err := net.DialTimeout(s.network, s.address, s.timeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	err = conn.SetDeadline(time.Now().Add(s.timeout))
+	if err != nil {
+		return "", err
+	}
+
+	writer := bufio.NewWriter(conn)
+	if _, err = writer.WriteString(command); err != nil {
+		return "", err
+	}
+
+	if content != nil {
+		chunkLength := make([]byte, 4)
+		for reader := bytes.NewReader(content); reader.Len() > 0; {
+			chunk := make([]byte, clamdChunkSize)
+			n, _ := reader.Read(chunk)
+
+			binary.BigEndian.PutUint32(chunkLength, uint32(n))
+			if _, err = writer.Write(chunkLength); err != nil {
+				return "", err
+			}
+			if _, err = writer.Write(chunk[:n]); err != nil {
+				return "", err
+			}
+		}
+
+		// a zero length chunk marks the end of the stream
+		binary.BigEndian.PutUint32(chunkLength, 0)
+		if _, err = writer.Write(chunkLength); err != nil {
+			return "", err
+		}
+	}
+
+	if err = writer.Flush(); err != nil {
+		return "", err
+	}
+
+	response, err := bufio.NewReader(conn).ReadString('\x00')
+	if err != nil && response == "" {
+		return "", fmt.Errorf("unable to read clamd response: %w", err)
+	}
+
+	return strings.TrimSpace(strings.TrimSuffix(response, "\x00")), nil
+}
+
+// parseClamdAddress splits the clamd address into its network and address, supporting
+// unix:///path/to/socket, tcp://host:port, a bare socket path or a bare host:port
+func parseClamdAddress(address string) (string, string) {
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		return "unix", strings.TrimPrefix(address, "unix://")
+	case strings.HasPrefix(address, "tcp://"):
+		return "tcp", strings.TrimPrefix(address, "tcp://")
+	case strings.HasPrefix(address, "/"):
+		return "unix", address
+	}
+
+	return "tcp", address
+}
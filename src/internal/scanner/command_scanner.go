@@ -0,0 +1,192 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	iaiperrors "github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/sethvargo/go-githubactions"
+)
+
+const (
+	// commandScannerFilePlaceholder is the placeholder in the scanner command that is
+	// replaced with the path of the file to scan
+	commandScannerFilePlaceholder = "{file}"
+
+	// commandScannerInfectedExitCode is the exit code scanner commands use to signal an
+	// infected file, following the convention used by clamscan
+	commandScannerInfectedExitCode int = 1
+)
+
+// NewCommandScannerRequest is the request object for creating a new
+// instance of a command Scanner.
+type NewCommandScannerRequest struct {
+	// Enabled whether the scanner is enabled or not
+	Enabled bool
+
+	// Command is the scanner command to run against each file, i.e. `clamscan --no-summary {file}`.
+	// The {file} placeholder is replaced with the path of the file, if no placeholder is present
+	// the path is appended as the last argument.
+	Command string
+
+	// Timeout is how long (in seconds) a scan can take before it is considered failed
+	Timeout int
+
+	// ActionPkg represents the githubactions package
+	ActionPkg *githubactions.Action
+}
+
+// NewCommandScanner returns a new instance of a command Scanner
+func NewCommandScanner(r *NewCommandScannerRequest) Scanner {
+
+	var timeout int = DefaultTimeout
+
+	if r.Timeout > 0 {
+		timeout = r.Timeout
+	}
+
+	return &CommandScanner{
+		enabled: r.Enabled,
+		command: strings.Fields(r.Command),
+		timeout: time.Duration(timeout) * time.Second,
+		action:  r.ActionPkg,
+	}
+}
+
+// CommandScanner is a struct that implements the Scanner interface by running a
+// configurable command against each file. An exit code of 0 means the file is clean,
+// 1 means the file is infected and any other exit code means the scan failed.
+type CommandScanner struct {
+
+	// enabled whether the scanner is enabled or not
+	enabled bool
+
+	// command is the scanner command split into its arguments
+	command []string
+
+	// timeout is how long a scan can take before it is considered failed
+	timeout time.Duration
+
+	// action represents the githubactions package
+	action *githubactions.Action
+}
+
+// Scan writes the file to a temporary location, runs the scanner command against
+// it and returns the verdict
+func (s *CommandScanner) Scan(fileName string, content []byte) *Verdict {
+
+	verdict := &Verdict{Scanner: filepath.Base(s.command[0])}
+
+	scanDir, err := os.MkdirTemp("", "interactive-inputs-scan-")
+	if err != nil {
+		return s.failedVerdict(verdict, fileName, err)
+	}
+	defer os.RemoveAll(scanDir)
+
+	scanFilePath := filepath.Join(scanDir, filepath.Base(fileName))
+	err = os.WriteFile(scanFilePath, content, 0600)
+	if err != nil {
+		return s.failedVerdict(verdict, fileName, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.command[0], s.commandArgs(scanFilePath)...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err = cmd.Run()
+	verdict.ScannedAt = time.Now().UTC()
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		verdict.Status = VerdictStatusClean
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == commandScannerInfectedExitCode:
+		verdict.Status = VerdictStatusInfected
+		verdict.Signature = firstLine(strings.ReplaceAll(output.String(), scanFilePath, fileName))
+	default:
+		if detail := firstLine(output.String()); detail != "" {
+			err = errors.New(detail)
+		}
+		return s.failedVerdict(verdict, fileName, err)
+	}
+
+	return verdict
+}
+
+// Verify checks that the scanner command can be found
+func (s *CommandScanner) Verify() error {
+
+	s.action.Debugf("Initiating the verification of the scanner command provided.")
+
+	if len(s.command) == 0 {
+		s.action.Errorf("No scanner command was provided")
+		return iaiperrors.ErrInvalidScannerCommandProvided
+	}
+
+	if _, err := exec.LookPath(s.command[0]); err != nil {
+		s.action.Errorf("Unable to find the scanner command '%s': %v", s.command[0], err)
+		return iaiperrors.ErrInvalidScannerCommandProvided
+	}
+
+	s.action.Debugf("Successfully verified the scanner command provided.")
+
+	return nil
+}
+
+// Enabled returns whether the scanner is enabled or not
+func (s *CommandScanner) Enabled() bool {
+	return s.enabled
+}
+
+// commandArgs returns the scanner command's arguments with the file placeholder
+// replaced, or the file path appended if there is no placeholder
+func (s *CommandScanner) commandArgs(filePath string) []string {
+	var args []string
+	var placeholderFound bool
+
+	for _, arg := range s.command[1:] {
+		if strings.Contains(arg, commandScannerFilePlaceholder) {
+			placeholderFound = true
+			arg = strings.ReplaceAll(arg, commandScannerFilePlaceholder, filePath)
+		}
+		args = append(args, arg)
+	}
+
+	if !placeholderFound {
+		args = append(args, filePath)
+	}
+
+	return args
+}
+
+// failedVerdict marks the verdict as failed with the error as its detail
+func (s *CommandScanner) failedVerdict(verdict *Verdict, fileName string, err error) *Verdict {
+	s.action.Errorf("Unable to scan '%s' with %s: %v", fileName, verdict.Scanner, err)
+
+	verdict.ScannedAt = time.Now().UTC()
+	verdict.Status = VerdictStatusError
+	verdict.Detail = err.Error()
+
+	return verdict
+}
+
+// firstLine returns the first non-empty line of the output
+func firstLine(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+
+	return ""
+}
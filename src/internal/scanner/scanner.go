@@ -0,0 +1,65 @@
+package scanner
+
+import "time"
+
+const (
+	// VerdictStatusClean is the status of a file that passed the scan
+	VerdictStatusClean = "clean"
+
+	// VerdictStatusInfected is the status of a file the scanner flagged as malicious
+	VerdictStatusInfected = "infected"
+
+	// VerdictStatusError is the status of a file that could not be scanned
+	VerdictStatusError = "error"
+)
+
+type Scanner interface {
+
+	// Scan streams the content of the named file to the scanner
+	// returning the verdict reached for the file
+	Scan(fileName string, content []byte) *Verdict
+
+	// Verifys the connection to the scanner
+	Verify() error
+
+	// Enabled returns whether the scanner is enabled or not
+	Enabled() bool
+}
+
+// Verdict represents the outcome of scanning a file
+type Verdict struct {
+
+	// Scanner is the name of the scanner that produced the verdict, i.e. clamd
+	Scanner string `json:"scanner"`
+
+	// Status is the outcome of the scan, one of clean, infected or error
+	Status string `json:"status"`
+
+	// Signature is the name of the signature that matched when the file is infected
+	Signature string `json:"signature,omitempty"`
+
+	// Detail holds additional information returned by the scanner, such as the
+	// reason a scan failed
+	Detail string `json:"detail,omitempty"`
+
+	// ScannedAt is when the scan completed
+	ScannedAt time.Time `json:"scanned_at"`
+}
+
+// Clean returns whether the file passed the scan
+func (v *Verdict) Clean() bool {
+	return v.Status == VerdictStatusClean
+}
+
+// Reason returns a human-friendly reason for rejecting the file, or an empty
+// string if the file is clean
+func (v *Verdict) Reason() string {
+	switch v.Status {
+	case VerdictStatusInfected:
+		return "Rejected by " + v.Scanner + ": malware detected (" + v.Signature + ")"
+	case VerdictStatusError:
+		return "Rejected by " + v.Scanner + ": scan failed (" + v.Detail + ")"
+	}
+
+	return ""
+}
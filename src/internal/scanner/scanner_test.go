@@ -0,0 +1,166 @@
+package scanner_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/scanner"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+)
+
+// startFakeClamd starts a TCP server that speaks enough of the clamd protocol
+// to answer PING and INSTREAM commands, flagging any stream containing "EICAR"
+func startFakeClamd(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to start fake clamd: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+
+				command, err := reader.ReadString('\x00')
+				if err != nil {
+					return
+				}
+
+				switch command {
+				case "zPING\x00":
+					conn.Write([]byte("PONG\x00"))
+				case "zINSTREAM\x00":
+					var stream bytes.Buffer
+					chunkLength := make([]byte, 4)
+					for {
+						if _, err := io.ReadFull(reader, chunkLength); err != nil {
+							return
+						}
+						length := binary.BigEndian.Uint32(chunkLength)
+						if length == 0 {
+							break
+						}
+						if _, err := io.CopyN(&stream, reader, int64(length)); err != nil {
+							return
+						}
+					}
+
+					if strings.Contains(stream.String(), "EICAR") {
+						conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+						return
+					}
+					conn.Write([]byte("stream: OK\x00"))
+				}
+			}(conn)
+		}
+	}()
+
+	return "tcp://" + listener.Addr().String()
+}
+
+func TestClamdScanner(t *testing.T) {
+
+	action := githubactions.New(githubactions.WithWriter(io.Discard))
+	clamdScanner := scanner.NewClamdScanner(&scanner.NewClamdScannerRequest{
+		Enabled:   true,
+		Address:   startFakeClamd(t),
+		ActionPkg: action,
+	})
+
+	assert.NoError(t, clamdScanner.Verify())
+
+	tests := []struct {
+		name              string
+		content           []byte
+		expectedStatus    string
+		expectedSignature string
+	}{
+		{
+			name:           "clean file",
+			content:        []byte("hello world"),
+			expectedStatus: scanner.VerdictStatusClean,
+		},
+		{
+			name:              "infected file",
+			content:           []byte("X5O!P%@AP[4\\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*"),
+			expectedStatus:    scanner.VerdictStatusInfected,
+			expectedSignature: "Eicar-Test-Signature",
+		},
+		{
+			name:           "large clean file streamed in chunks",
+			content:        bytes.Repeat([]byte("a"), 200*1024),
+			expectedStatus: scanner.VerdictStatusClean,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := clamdScanner.Scan("file.txt", tt.content)
+
+			assert.Equal(t, "clamd", verdict.Scanner)
+			assert.Equal(t, tt.expectedStatus, verdict.Status)
+			assert.Equal(t, tt.expectedSignature, verdict.Signature)
+			assert.False(t, verdict.ScannedAt.IsZero())
+		})
+	}
+}
+
+func TestCommandScanner(t *testing.T) {
+
+	action := githubactions.New(githubactions.WithWriter(io.Discard))
+
+	tests := []struct {
+		name           string
+		command        string
+		expectedStatus string
+		expectedReason string
+	}{
+		{
+			name:           "clean file",
+			command:        "true",
+			expectedStatus: scanner.VerdictStatusClean,
+		},
+		{
+			name:           "infected file",
+			command:        "false {file}",
+			expectedStatus: scanner.VerdictStatusInfected,
+			expectedReason: "Rejected by false: malware detected ()",
+		},
+		{
+			name:           "failed scan",
+			command:        "ls /path/that/does/not/exist",
+			expectedStatus: scanner.VerdictStatusError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commandScanner := scanner.NewCommandScanner(&scanner.NewCommandScannerRequest{
+				Enabled:   true,
+				Command:   tt.command,
+				ActionPkg: action,
+			})
+
+			assert.NoError(t, commandScanner.Verify())
+
+			verdict := commandScanner.Scan("file.txt", []byte("hello world"))
+			assert.Equal(t, tt.expectedStatus, verdict.Status)
+			if tt.expectedReason != "" {
+				assert.Equal(t, tt.expectedReason, verdict.Reason())
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package secrets
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/boasihq/interactive-inputs/internal/toolbox"
+)
+
+const (
+	// PolicyOff doesn't look for secrets in submitted values
+	PolicyOff = "off"
+
+	// PolicyReject rejects the submission when a secret is found, asking the user to remove it
+	PolicyReject = "reject"
+
+	// PolicyMask masks any secrets found in the logs and warns about them
+	PolicyMask = "mask"
+
+	// KindHighEntropyString is the kind of secret matched by its entropy rather than a pattern
+	KindHighEntropyString = "high entropy string"
+
+	// highEntropyMinLength is the minimum length of a word before its entropy is checked
+	highEntropyMinLength = 24
+
+	// highEntropyThreshold is the entropy (in bits per character) above which a word is
+	// considered a secret
+	highEntropyThreshold = 4.0
+)
+
+var (
+	// ValidPolicies is the list of ways secrets found in submitted values can be handled
+	ValidPolicies = []string{PolicyOff, PolicyReject, PolicyMask}
+
+	// patterns is the list of well known secret formats, keyed by the kind of secret they match
+	// (group is the submatch holding the secret when the pattern also matches its context)
+	patterns = []struct {
+		kind    string
+		pattern *regexp.Regexp
+		group   int
+	}{
+		{kind: "private key", pattern: regexp.MustCompile(`-----BEGIN[A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----[\s\S]*?(-----END[A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----|$)`)},
+		{kind: "AWS access key", pattern: regexp.MustCompile(`\b(AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`)},
+		{kind: "AWS secret key", pattern: regexp.MustCompile(`(?i)aws.{0,20}secret.{0,20}?['"=: ]\s*['"]?([A-Za-z0-9/+]{40})\b`), group: 1},
+		{kind: "GitHub token", pattern: regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`)},
+		{kind: "Slack token", pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`)},
+		{kind: "Slack webhook", pattern: regexp.MustCompile(`https://hooks\.slack\.com/(services|workflows)/[A-Za-z0-9/_-]+`)},
+	}
+
+	// wordSeparators matches the characters values are split on before checking the
+	// entropy of each word
+	wordSeparators = regexp.MustCompile(`[^A-Za-z0-9+/=_\-]+`)
+)
+
+// NewDetectorRequest is the request object for creating a new instance of Detector
+type NewDetectorRequest struct {
+
+	// Policy is how secrets found in submitted values are handled
+	Policy string
+}
+
+// NewDetector returns a new instance of Detector
+func NewDetector(r *NewDetectorRequest) *Detector {
+
+	var policy string = PolicyMask
+
+	if r.Policy != "" {
+		policy = r.Policy
+	}
+
+	return &Detector{
+		policy: policy,
+	}
+}
+
+// Detector looks for secrets accidentally pasted into fields that aren't sensitive
+type Detector struct {
+
+	// policy is how secrets found in submitted values are handled
+	policy string
+}
+
+// Match represents a secret found in a value
+type Match struct {
+
+	// Kind is the kind of secret, i.e. GitHub token
+	Kind string
+
+	// Value is the secret itself
+	Value string
+}
+
+// Policy returns how secrets found in submitted values are handled
+func (d *Detector) Policy() string {
+	return d.policy
+}
+
+// Enabled returns whether submitted values are checked for secrets
+func (d *Detector) Enabled() bool {
+	return d.policy != PolicyOff
+}
+
+// Detect returns the secrets found in the value
+func (d *Detector) Detect(value string) []Match {
+	matches := []Match{}
+
+	if !d.Enabled() {
+		return matches
+	}
+
+	for _, p := range patterns {
+		for _, submatches := range p.pattern.FindAllStringSubmatch(value, -1) {
+			matches = append(matches, Match{Kind: p.kind, Value: submatches[p.group]})
+			value = strings.ReplaceAll(value, submatches[0], " ")
+		}
+	}
+
+	for _, word := range wordSeparators.Split(value, -1) {
+		if isHighEntropy(word) {
+			matches = append(matches, Match{Kind: KindHighEntropyString, Value: word})
+		}
+	}
+
+	return matches
+}
+
+// Kinds returns the distinct kinds of secrets in the matches, in the order they were found
+func Kinds(matches []Match) []string {
+	kinds := []string{}
+
+	for _, match := range matches {
+		if !toolbox.StringInSlice(match.Kind, kinds) {
+			kinds = append(kinds, match.Kind)
+		}
+	}
+
+	return kinds
+}
+
+// isHighEntropy returns whether the word is long and random enough to likely be a secret,
+// words made up of a single class of characters (i.e. hex digests) are ignored
+func isHighEntropy(word string) bool {
+	if len(word) < highEntropyMinLength {
+		return false
+	}
+
+	var hasUpper, hasLower, hasDigit bool
+	for _, r := range word {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+
+	if !hasUpper || !hasLower || !hasDigit {
+		return false
+	}
+
+	return entropy(word) >= highEntropyThreshold
+}
+
+// entropy returns the Shannon entropy of the word in bits per character
+func entropy(word string) float64 {
+	frequencies := map[rune]float64{}
+	for _, r := range word {
+		frequencies[r]++
+	}
+
+	var total float64 = float64(len([]rune(word)))
+	var bits float64
+	for _, frequency := range frequencies {
+		probability := frequency / total
+		bits -= probability * math.Log2(probability)
+	}
+
+	return bits
+}
@@ -0,0 +1,208 @@
+package security
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/boasihq/interactive-inputs/internal/session"
+	"github.com/sethvargo/go-githubactions"
+)
+
+const (
+	// CsrfCookieName is the name of the cookie tying CSRF tokens to the visitor's browser
+	CsrfCookieName = "iaip_csrf"
+
+	// CsrfHeaderName is the header htmx and fetch requests send the CSRF token in
+	CsrfHeaderName = "X-CSRF-Token"
+
+	// CsrfFormFieldName is the form field plain HTML forms send the CSRF token in
+	CsrfFormFieldName = "csrf-token"
+
+	// contentSecurityPolicyTmpl is the policy every response is served with. Only the
+	// embedded assets and inline scripts carrying the response's nonce may run, while
+	// Alpine needs eval for its expressions and Tailwind injects inline styles
+	contentSecurityPolicyTmpl = "default-src 'self'; script-src 'self' 'nonce-%s' 'unsafe-eval'; style-src 'self' 'unsafe-inline'; img-src 'self' data:; font-src 'self'; connect-src 'self'; object-src 'none'; base-uri 'none'; form-action 'self'; frame-ancestors 'none'"
+
+	// hstsMaxAge is how long (in seconds) browsers should only reach the portal over HTTPS
+	hstsMaxAge = 31536000
+)
+
+// contextKey is the key values are stored under in the request context
+type contextKey string
+
+const (
+	// csrfTokenContextKey is the key the request's CSRF token is stored under
+	csrfTokenContextKey contextKey = "csrf-token"
+
+	// cspNonceContextKey is the key the response's script nonce is stored under
+	cspNonceContextKey contextKey = "csp-nonce"
+)
+
+// NewGuardRequest is the request object for creating a new instance of Guard
+type NewGuardRequest struct {
+
+	// ActionPkg represents the githubactions package
+	ActionPkg *githubactions.Action
+
+	// Session tracks what happens to the portal while it is open
+	Session *session.Session
+}
+
+// NewGuard returns a new instance of Guard
+func NewGuard(r *NewGuardRequest) *Guard {
+
+	// the key CSRF tokens are signed with only lives as long as the portal
+	key := make([]byte, 32)
+	rand.Read(key)
+
+	return &Guard{
+		actionPkg: r.ActionPkg,
+		session:   r.Session,
+		key:       key,
+	}
+}
+
+// Guard sets the security headers on every response and stops cross-site requests
+// from changing the portal
+type Guard struct {
+
+	// actionPkg represents the githubactions package
+	actionPkg *githubactions.Action
+
+	// session tracks what happens to the portal while it is open
+	session *session.Session
+
+	// key is the key CSRF tokens are signed with
+	key []byte
+}
+
+// Protect is the middleware that sets the security headers, issues the visitor's CSRF
+// token and refuses mutating requests that don't carry it. The token and the response's
+// script nonce are added to the request's context for the templates to use
+func (g *Guard) Protect(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		nonce, err := randomValue()
+		if err != nil {
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		setHeaders(w, r, nonce)
+
+		var csrfSessionId string
+		if cookie, err := r.Cookie(CsrfCookieName); err == nil && cookie.Value != "" {
+			csrfSessionId = cookie.Value
+		}
+
+		if isMutatingMethod(r.Method) && !g.validCsrfToken(r, csrfSessionId) {
+			g.actionPkg.Warningf("Refused a %s request to %s without a valid CSRF token", r.Method, r.URL.Path)
+			g.session.Record(fmt.Sprintf("Refused a cross-site %s request to %s", r.Method, r.URL.Path))
+			http.Error(w, "Invalid or missing CSRF token, please reload the portal", http.StatusForbidden)
+			return
+		}
+
+		if csrfSessionId == "" {
+			csrfSessionId, err = randomValue()
+			if err != nil {
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+
+			http.SetCookie(w, &http.Cookie{
+				Name:     CsrfCookieName,
+				Value:    csrfSessionId,
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   IsSecureRequest(r),
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+
+		ctx := context.WithValue(r.Context(), csrfTokenContextKey, g.signCsrfSessionId(csrfSessionId))
+		ctx = context.WithValue(ctx, cspNonceContextKey, nonce)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// CsrfToken returns the CSRF token of the request's visitor, or an empty string
+func CsrfToken(ctx context.Context) string {
+	csrfToken, _ := ctx.Value(csrfTokenContextKey).(string)
+	return csrfToken
+}
+
+// CspNonce returns the nonce inline scripts in the response must carry, or an empty string
+func CspNonce(ctx context.Context) string {
+	nonce, _ := ctx.Value(cspNonceContextKey).(string)
+	return nonce
+}
+
+// IsSecureRequest returns whether the request reached the portal over HTTPS, which it
+// does through the ngrok tunnel even though the portal itself serves plain HTTP
+func IsSecureRequest(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
+
+// validCsrfToken returns whether the request carries the CSRF token issued to its visitor,
+// in the header or (for plain HTML forms) the form field
+func (g *Guard) validCsrfToken(r *http.Request, csrfSessionId string) bool {
+	if csrfSessionId == "" {
+		return false
+	}
+
+	csrfToken := r.Header.Get(CsrfHeaderName)
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); csrfToken == "" && mediaType == "application/x-www-form-urlencoded" {
+		csrfToken = r.PostFormValue(CsrfFormFieldName)
+	}
+
+	return hmac.Equal([]byte(csrfToken), []byte(g.signCsrfSessionId(csrfSessionId)))
+}
+
+// signCsrfSessionId returns the CSRF token for the visitor's CSRF cookie
+func (g *Guard) signCsrfSessionId(csrfSessionId string) string {
+	mac := hmac.New(sha256.New, g.key)
+	mac.Write([]byte(csrfSessionId))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// setHeaders sets the security headers every response is served with
+func setHeaders(w http.ResponseWriter, r *http.Request, nonce string) {
+	w.Header().Set("Content-Security-Policy", fmt.Sprintf(contentSecurityPolicyTmpl, nonce))
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+
+	// only the tunnel serves the portal over HTTPS, browsers would refuse to reach a
+	// local portal again if it was set there
+	if IsSecureRequest(r) {
+		w.Header().Set("Strict-Transport-Security", fmt.Sprintf("max-age=%d", hstsMaxAge))
+	}
+}
+
+// isMutatingMethod returns whether requests with the method can change the portal
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
+
+// randomValue returns a random, URL safe value
+func randomValue() (string, error) {
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(randomBytes), nil
+}
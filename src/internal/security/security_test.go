@@ -0,0 +1,130 @@
+package security_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/security"
+	"github.com/boasihq/interactive-inputs/internal/session"
+	"github.com/sethvargo/go-githubactions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuard_Protect(t *testing.T) {
+
+	guard := security.NewGuard(&security.NewGuardRequest{
+		ActionPkg: githubactions.New(githubactions.WithWriter(io.Discard)),
+		Session:   session.New(&session.NewSessionRequest{}),
+	})
+
+	var csrfToken, cspNonce string
+	handler := guard.Protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		csrfToken = security.CsrfToken(r.Context())
+		cspNonce = security.CspNonce(r.Context())
+	}))
+
+	// the visitor's first request issues their CSRF cookie
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Security-Policy"), "'nonce-"+cspNonce+"'")
+	assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "no-referrer", w.Header().Get("Referrer-Policy"))
+	assert.Empty(t, w.Header().Get("Strict-Transport-Security"))
+	assert.NotEmpty(t, csrfToken)
+
+	csrfCookie := w.Result().Cookies()[0]
+	assert.Equal(t, security.CsrfCookieName, csrfCookie.Name)
+	assert.Equal(t, http.SameSiteLaxMode, csrfCookie.SameSite)
+
+	issuedCsrfToken := csrfToken
+
+	tests := []struct {
+		name           string
+		method         string
+		withCookie     bool
+		header         string
+		form           url.Values
+		forwardedProto string
+		expectedStatus int
+	}{
+		{
+			name:           "token in header",
+			method:         http.MethodPost,
+			withCookie:     true,
+			header:         issuedCsrfToken,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "token in form field",
+			method:         http.MethodPost,
+			withCookie:     true,
+			form:           url.Values{security.CsrfFormFieldName: {issuedCsrfToken}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing token",
+			method:         http.MethodPost,
+			withCookie:     true,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "forged token",
+			method:         http.MethodDelete,
+			withCookie:     true,
+			header:         "forged",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "token without its cookie",
+			method:         http.MethodPost,
+			header:         issuedCsrfToken,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "preflight without token",
+			method:         http.MethodOptions,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "served over the tunnel",
+			method:         http.MethodGet,
+			forwardedProto: "https",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader
+			if tt.form != nil {
+				body = strings.NewReader(tt.form.Encode())
+			}
+
+			r := httptest.NewRequest(tt.method, "/cancel", body)
+			if tt.form != nil {
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			if tt.withCookie {
+				r.AddCookie(csrfCookie)
+			}
+			if tt.header != "" {
+				r.Header.Set(security.CsrfHeaderName, tt.header)
+			}
+			if tt.forwardedProto != "" {
+				r.Header.Set("X-Forwarded-Proto", tt.forwardedProto)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+			assert.Equal(t, tt.forwardedProto == "https", w.Header().Get("Strict-Transport-Security") != "")
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package session
+
+import (
+	"bytes"
+	"strconv"
+	"text/template"
+	"time"
+)
+
+const (
+	// OutputKeyOutcome is the key of the output holding how the portal was closed
+	OutputKeyOutcome = "outcome"
+
+	// OutputKeyOpenedAt is the key of the output holding when the portal was opened
+	OutputKeyOpenedAt = "opened-at"
+
+	// OutputKeySubmittedAt is the key of the output holding when the portal was submitted
+	OutputKeySubmittedAt = "submitted-at"
+
+	// OutputKeyResponseSeconds is the key of the output holding how long the portal was open for
+	OutputKeyResponseSeconds = "response-seconds"
+
+	// OutputKeyPortalUrl is the key of the output holding the URL the portal was reachable at
+	OutputKeyPortalUrl = "portal-url"
+
+	// OutputKeySubmitter is the key of the output holding the GitHub login of the submitter
+	OutputKeySubmitter = "submitter"
+
+	// OutputKeySubmitterIp is the key of the output holding the IP address of the submitter
+	OutputKeySubmitterIp = "submitter-ip"
+
+	// OutputKeySubmitterUserAgent is the key of the output holding the user agent of the submitter
+	OutputKeySubmitterUserAgent = "submitter-user-agent"
+)
+
+// OutputKeys is the list of outputs set with the session's metadata once it is closed
+var OutputKeys = []string{
+	OutputKeyOutcome,
+	OutputKeyOpenedAt,
+	OutputKeySubmittedAt,
+	OutputKeyResponseSeconds,
+	OutputKeyPortalUrl,
+	OutputKeySubmitter,
+	OutputKeySubmitterIp,
+	OutputKeySubmitterUserAgent,
+}
+
+// Metadata is a snapshot of who answered the portal, when and how long it took, which
+// is set as outputs and made available to notifier messages
+type Metadata struct {
+
+	// Title is the title of the portal
+	Title string
+
+	// Outcome is how the portal was closed
+	Outcome string
+
+	// OpenedAt is when the portal was opened
+	OpenedAt time.Time
+
+	// ClosedAt is when the portal was closed, or the zero time if it is still open
+	ClosedAt time.Time
+
+	// SubmittedAt is when the portal was submitted, or the zero time if it wasn't
+	SubmittedAt time.Time
+
+	// ResponseSeconds is how long (in seconds) the portal was open for
+	ResponseSeconds int
+
+	// PortalUrl is the URL the portal was reachable at
+	PortalUrl string
+
+	// Submitter is the GitHub login of who closed the portal (if known)
+	Submitter string
+
+	// SubmitterIp is the IP address of who closed the portal
+	SubmitterIp string
+
+	// SubmitterUserAgent is the user agent of who closed the portal
+	SubmitterUserAgent string
+
+	// Decision is whether the request was approved or rejected, empty unless the portal
+	// asked for a decision
+	Decision string
+
+	// DecisionComment is the comment left with the decision
+	DecisionComment string
+
+	// Approver is who made the decision
+	Approver string
+}
+
+// Metadata returns a snapshot of the session's metadata
+func (s *Session) Metadata() Metadata {
+
+	metadata := Metadata{
+		Title:           s.Title(),
+		Outcome:         s.Outcome(),
+		OpenedAt:        s.OpenedAt(),
+		ClosedAt:        s.ClosedAt(),
+		ResponseSeconds: int(s.Duration().Round(time.Second).Seconds()),
+		PortalUrl:       s.PortalUrl(),
+	}
+
+	if decision := s.Decision(); decision != nil {
+		metadata.Decision = decision.Decision
+		metadata.DecisionComment = decision.Comment
+		metadata.Approver = decision.Approver
+	}
+
+	if metadata.Outcome == OutcomeSubmitted {
+		metadata.SubmittedAt = metadata.ClosedAt
+	}
+
+	if submitter := s.Submitter(); submitter != nil {
+		metadata.Submitter = submitter.Login
+		metadata.SubmitterIp = submitter.Ip
+		metadata.SubmitterUserAgent = submitter.UserAgent
+	}
+
+	return metadata
+}
+
+// Outputs returns the metadata keyed by output key, with times in RFC 3339 format and
+// empty values for anything that is unknown
+func (m Metadata) Outputs() map[string]string {
+	return map[string]string{
+		OutputKeyOutcome:            m.Outcome,
+		OutputKeyOpenedAt:           formatOutputTime(m.OpenedAt),
+		OutputKeySubmittedAt:        formatOutputTime(m.SubmittedAt),
+		OutputKeyResponseSeconds:    strconv.Itoa(m.ResponseSeconds),
+		OutputKeyPortalUrl:          m.PortalUrl,
+		OutputKeySubmitter:          m.Submitter,
+		OutputKeySubmitterIp:        m.SubmitterIp,
+		OutputKeySubmitterUserAgent: m.SubmitterUserAgent,
+	}
+}
+
+// Render renders the text/template with the metadata, i.e. "Answered by {{ .Submitter }}"
+func (m Metadata) Render(text string) (string, error) {
+
+	tmpl, err := template.New("metadata").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var rendered bytes.Buffer
+	err = tmpl.Execute(&rendered, m)
+	if err != nil {
+		return "", err
+	}
+
+	return rendered.String(), nil
+}
+
+// formatOutputTime returns the time in RFC 3339 format, or an empty string for the zero time
+func formatOutputTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
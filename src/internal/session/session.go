@@ -0,0 +1,346 @@
+package session
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/approval"
+)
+
+const (
+	// OutcomeOpen is the outcome of a session that has not been closed yet
+	OutcomeOpen = "open"
+
+	// OutcomeSubmitted is the outcome of a session closed by submitting the portal
+	OutcomeSubmitted = "submitted"
+
+	// OutcomeCancelled is the outcome of a session closed by cancelling the portal
+	OutcomeCancelled = "cancelled"
+
+	// OutcomeTimedOut is the outcome of a session closed by the portal timing out
+	OutcomeTimedOut = "timed out"
+)
+
+// NewSessionRequest is the request object for creating a new instance of Session
+type NewSessionRequest struct {
+
+	// Title is the title of the portal
+	Title string
+}
+
+// New returns a new instance of Session, opened now
+func New(r *NewSessionRequest) *Session {
+
+	openedAt := time.Now().UTC()
+
+	return &Session{
+		title:    r.Title,
+		openedAt: openedAt,
+		outcome:  OutcomeOpen,
+		timeline: []Event{{At: openedAt, Description: "Portal opened"}},
+	}
+}
+
+// Session tracks what happens to the portal from the moment it is opened to
+// the moment it is submitted, cancelled or times out
+type Session struct {
+
+	// mutex guards the session
+	mutex sync.Mutex
+
+	// title is the title of the portal
+	title string
+
+	// openedAt is when the portal was opened
+	openedAt time.Time
+
+	// closedAt is when the portal was submitted, cancelled or timed out
+	closedAt time.Time
+
+	// outcome is how the portal was closed
+	outcome string
+
+	// portalUrl is the URL the portal is reachable at
+	portalUrl string
+
+	// submitter is who closed the portal
+	submitter *Submitter
+
+	// decision is the approve or reject decision made with the portal, nil unless the
+	// portal asked for one
+	decision *approval.Decision
+
+	// values is the list of values submitted
+	values []FieldValue
+
+	// uploads is the list of uploads made to the portal's file fields
+	uploads []Upload
+
+	// timeline is the list of events that happened during the session
+	timeline []Event
+
+	// onClose is the list of functions called once the session is closed
+	onClose []func(s *Session)
+}
+
+// Submitter represents who submitted or cancelled the portal
+type Submitter struct {
+
+	// Login is the GitHub login of the submitter (if known)
+	Login string
+
+	// Ip is the IP address the request was made from
+	Ip string
+
+	// UserAgent is the user agent the request was made with
+	UserAgent string
+
+	// Link is the recipient of the signed link the portal was opened with (if used)
+	Link string
+}
+
+// Display returns who the submitter is in a human readable form
+func (s *Submitter) Display() string {
+	var display string
+	switch {
+	case s.Login != "":
+		display = "@" + s.Login
+	case s.Ip != "":
+		display = fmt.Sprintf("Anonymous (%s)", s.Ip)
+	default:
+		display = "Anonymous"
+	}
+
+	if s.Link != "" {
+		display += fmt.Sprintf(" via the %s link", s.Link)
+	}
+
+	return display
+}
+
+// FieldValue represents the value submitted for a field
+type FieldValue struct {
+
+	// Label is the label of the field
+	Label string
+
+	// Display is the display name of the field
+	Display string
+
+	// Type is the type of the field
+	Type string
+
+	// Value is the (encoded) value submitted for the field
+	Value string
+
+	// Sensitive is whether the value should be redacted
+	Sensitive bool
+}
+
+// Upload represents the files uploaded to a file field
+type Upload struct {
+
+	// Label is the label of the field
+	Label string
+
+	// ManifestPath is the path the upload manifest was written to
+	ManifestPath string
+
+	// Files is the list of files uploaded
+	Files []UploadFile
+}
+
+// UploadFile represents a single file uploaded to a file field
+type UploadFile struct {
+
+	// Name is the name of the file
+	Name string
+
+	// Size is the size of the file in bytes
+	Size int64
+
+	// Sha256 is the hex encoded SHA-256 checksum of the file
+	Sha256 string
+
+	// Status is whether the file was accepted or rejected
+	Status string
+
+	// Reason is why the file was rejected
+	Reason string
+}
+
+// Event represents something that happened during the session
+type Event struct {
+
+	// At is when the event happened
+	At time.Time
+
+	// Description is what happened
+	Description string
+}
+
+// Title returns the title of the portal
+func (s *Session) Title() string {
+	return s.title
+}
+
+// OpenedAt returns when the portal was opened
+func (s *Session) OpenedAt() time.Time {
+	return s.openedAt
+}
+
+// ClosedAt returns when the portal was closed, or the zero time if it is still open
+func (s *Session) ClosedAt() time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.closedAt
+}
+
+// Outcome returns how the portal was closed
+func (s *Session) Outcome() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.outcome
+}
+
+// Submitter returns who submitted or cancelled the portal, or nil if it is unknown
+func (s *Session) Submitter() *Submitter {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.submitter
+}
+
+// Values returns the values submitted
+func (s *Session) Values() []FieldValue {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]FieldValue{}, s.values...)
+}
+
+// Uploads returns the uploads made to the portal's file fields
+func (s *Session) Uploads() []Upload {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]Upload{}, s.uploads...)
+}
+
+// Timeline returns the events that happened during the session
+func (s *Session) Timeline() []Event {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]Event{}, s.timeline...)
+}
+
+// Duration returns how long the portal was (or has been) open
+func (s *Session) Duration() time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closedAt.IsZero() {
+		return time.Since(s.openedAt)
+	}
+
+	return s.closedAt.Sub(s.openedAt)
+}
+
+// PortalUrl returns the URL the portal is reachable at
+func (s *Session) PortalUrl() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.portalUrl
+}
+
+// SetPortalUrl sets the URL the portal is reachable at
+func (s *Session) SetPortalUrl(portalUrl string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.portalUrl = portalUrl
+}
+
+// Record adds an event to the session's timeline
+func (s *Session) Record(description string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.timeline = append(s.timeline, Event{At: time.Now().UTC(), Description: description})
+}
+
+// SetSubmitter sets who submitted or cancelled the portal
+func (s *Session) SetSubmitter(submitter *Submitter) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.submitter = submitter
+}
+
+// SetDecision sets the approve or reject decision made with the portal
+func (s *Session) SetDecision(decision *approval.Decision) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.decision = decision
+}
+
+// Decision returns the approve or reject decision made with the portal, or nil if the
+// portal didn't ask for one
+func (s *Session) Decision() *approval.Decision {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.decision
+}
+
+// AddValue adds a submitted value to the session
+func (s *Session) AddValue(value FieldValue) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.values = append(s.values, value)
+}
+
+// AddUpload adds the files uploaded to a file field to the session
+func (s *Session) AddUpload(upload Upload) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.uploads = append(s.uploads, upload)
+}
+
+// OnClose registers a function that is called once the session is closed
+func (s *Session) OnClose(fn func(s *Session)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.onClose = append(s.onClose, fn)
+}
+
+// Close closes the session with the given outcome and calls the functions registered
+// with OnClose. Closing an already closed session does nothing
+func (s *Session) Close(outcome string) {
+	s.mutex.Lock()
+
+	if s.outcome != OutcomeOpen {
+		s.mutex.Unlock()
+		return
+	}
+
+	s.closedAt = time.Now().UTC()
+	s.outcome = outcome
+	s.timeline = append(s.timeline, Event{At: s.closedAt, Description: "Portal " + outcome})
+	onClose := s.onClose
+
+	s.mutex.Unlock()
+
+	for _, fn := range onClose {
+		fn(s)
+	}
+}
@@ -0,0 +1,162 @@
+package session_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/approval"
+	"github.com/boasihq/interactive-inputs/internal/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSession_Close(t *testing.T) {
+
+	s := session.New(&session.NewSessionRequest{Title: "Deploy"})
+
+	var closedOutcomes []string
+	s.OnClose(func(s *session.Session) {
+		closedOutcomes = append(closedOutcomes, s.Outcome())
+	})
+
+	assert.Equal(t, session.OutcomeOpen, s.Outcome())
+	assert.True(t, s.ClosedAt().IsZero())
+
+	s.Close(session.OutcomeSubmitted)
+	s.Close(session.OutcomeTimedOut)
+
+	assert.Equal(t, session.OutcomeSubmitted, s.Outcome())
+	assert.False(t, s.ClosedAt().IsZero())
+	assert.Equal(t, []string{session.OutcomeSubmitted}, closedOutcomes)
+	assert.Len(t, s.Timeline(), 2)
+}
+
+func TestSession_Markdown(t *testing.T) {
+
+	tests := []struct {
+		name               string
+		outcome            string
+		submitter          *session.Submitter
+		decision           *approval.Decision
+		values             []session.FieldValue
+		uploads            []session.Upload
+		expectedContains   []string
+		expectedNotContain []string
+	}{
+		{
+			name:    "submitted with sensitive value redacted",
+			outcome: session.OutcomeSubmitted,
+			submitter: &session.Submitter{
+				Login: "octocat",
+			},
+			values: []session.FieldValue{
+				{Label: "environment", Display: "Environment", Value: "production"},
+				{Label: "api-key", Display: "API key", Value: "s3cr3t", Sensitive: true},
+				{Label: "notes", Value: "a | b"},
+			},
+			expectedContains: []string{
+				"## Deploy",
+				"✅ Submitted",
+				"| Closed by | @octocat |",
+				"| Environment (`environment`) | `production` |",
+				"| API key (`api-key`) | `***` |",
+				"| notes | `a \\| b` |",
+				"Portal submitted",
+			},
+			expectedNotContain: []string{"s3cr3t"},
+		},
+		{
+			name:    "cancelled with uploads",
+			outcome: session.OutcomeCancelled,
+			submitter: &session.Submitter{
+				Ip:   "203.0.113.7",
+				Link: "slack",
+			},
+			uploads: []session.Upload{
+				{
+					Label:        "artifacts",
+					ManifestPath: "/tmp/artifacts.manifest.json",
+					Files: []session.UploadFile{
+						{Name: "report.pdf", Size: 2048, Sha256: "abc", Status: "accepted"},
+						{Name: "virus.exe", Size: 12, Sha256: "def", Status: "rejected", Reason: "not allowed"},
+					},
+				},
+			},
+			expectedContains: []string{
+				"🚫 Cancelled",
+				"Anonymous (203.0.113.7) via the slack link",
+				"### Uploaded files",
+				"| report.pdf | 2.0 KiB | `abc` | accepted |",
+				"| virus.exe | 12 B | `def` | rejected (not allowed) |",
+			},
+			expectedNotContain: []string{"### Submitted values"},
+		},
+		{
+			name:    "rejected with a comment",
+			outcome: session.OutcomeSubmitted,
+			submitter: &session.Submitter{
+				Login: "octocat",
+			},
+			decision: &approval.Decision{Decision: approval.DecisionRejected, Comment: "Not during\nthe freeze", Approver: "octocat"},
+			expectedContains: []string{
+				"| Decision | ❌ Rejected |",
+				"| Comment | Not during<br>the freeze |",
+			},
+			expectedNotContain: []string{"### Submitted values"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := session.New(&session.NewSessionRequest{Title: "Deploy"})
+			for _, value := range tt.values {
+				s.AddValue(value)
+			}
+			for _, upload := range tt.uploads {
+				s.AddUpload(upload)
+			}
+			s.SetDecision(tt.decision)
+			s.SetSubmitter(tt.submitter)
+			s.Close(tt.outcome)
+
+			summary := s.Markdown()
+			for _, expected := range tt.expectedContains {
+				assert.Contains(t, summary, expected)
+			}
+			for _, unexpected := range tt.expectedNotContain {
+				assert.NotContains(t, summary, unexpected)
+			}
+		})
+	}
+}
+
+func TestSession_Metadata(t *testing.T) {
+
+	s := session.New(&session.NewSessionRequest{Title: "Deploy"})
+	s.SetPortalUrl("https://example.ngrok.app")
+	s.SetSubmitter(&session.Submitter{Login: "octocat", Ip: "203.0.113.7", UserAgent: "Mozilla/5.0"})
+
+	openOutputs := s.Metadata().Outputs()
+	assert.Equal(t, session.OutcomeOpen, openOutputs[session.OutputKeyOutcome])
+	assert.Equal(t, "", openOutputs[session.OutputKeySubmittedAt])
+
+	s.Close(session.OutcomeSubmitted)
+
+	metadata := s.Metadata()
+	outputs := metadata.Outputs()
+	assert.Len(t, outputs, len(session.OutputKeys))
+	assert.Equal(t, session.OutcomeSubmitted, outputs[session.OutputKeyOutcome])
+	assert.Equal(t, metadata.ClosedAt.Format(time.RFC3339), outputs[session.OutputKeySubmittedAt])
+	assert.Equal(t, metadata.OpenedAt.Format(time.RFC3339), outputs[session.OutputKeyOpenedAt])
+	assert.Equal(t, "0", outputs[session.OutputKeyResponseSeconds])
+	assert.Equal(t, "https://example.ngrok.app", outputs[session.OutputKeyPortalUrl])
+	assert.Equal(t, "octocat", outputs[session.OutputKeySubmitter])
+	assert.Equal(t, "203.0.113.7", outputs[session.OutputKeySubmitterIp])
+	assert.Equal(t, "Mozilla/5.0", outputs[session.OutputKeySubmitterUserAgent])
+
+	rendered, err := metadata.Render("{{ .Title }} {{ .Outcome }} by {{ .Submitter }} in {{ .ResponseSeconds }}s")
+	assert.NoError(t, err)
+	assert.Equal(t, "Deploy submitted by octocat in 0s", rendered)
+
+	_, err = metadata.Render("{{ .Unknown }")
+	assert.Error(t, err)
+}
@@ -0,0 +1,192 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/approval"
+)
+
+const (
+	// redactedValue is displayed in place of sensitive values
+	redactedValue = "`***`"
+
+	// defaultSummaryTitle is the title of the summary when the portal has no title
+	defaultSummaryTitle = "Interactive Inputs"
+)
+
+// Markdown returns a report of the session that can be added to the job summary,
+// with the values of sensitive fields redacted
+func (s *Session) Markdown() string {
+
+	var summary strings.Builder
+
+	title := s.Title()
+	if title == "" {
+		title = defaultSummaryTitle
+	}
+
+	metadata := s.Metadata()
+
+	closedAtDisplay := "-"
+	if !metadata.ClosedAt.IsZero() {
+		closedAtDisplay = formatTime(metadata.ClosedAt)
+	}
+
+	fmt.Fprintf(&summary, "## %s\n\n", escapeMarkdownTableCell(title))
+	summary.WriteString("| | |\n")
+	summary.WriteString("| --- | --- |\n")
+	fmt.Fprintf(&summary, "| Outcome | %s |\n", outcomeDisplay(metadata.Outcome))
+	if metadata.Decision != "" {
+		fmt.Fprintf(&summary, "| Decision | %s |\n", decisionDisplay(metadata.Decision))
+	}
+	if metadata.DecisionComment != "" {
+		fmt.Fprintf(&summary, "| Comment | %s |\n", escapeMarkdownTableCell(metadata.DecisionComment))
+	}
+	fmt.Fprintf(&summary, "| Closed by | %s |\n", escapeMarkdownTableCell(submitterDisplay(s.Submitter())))
+	if metadata.SubmitterUserAgent != "" {
+		fmt.Fprintf(&summary, "| User agent | `%s` |\n", escapeMarkdownTableCell(strings.ReplaceAll(metadata.SubmitterUserAgent, "`", "'")))
+	}
+	fmt.Fprintf(&summary, "| Opened at | %s |\n", formatTime(metadata.OpenedAt))
+	fmt.Fprintf(&summary, "| Closed at | %s |\n", closedAtDisplay)
+	fmt.Fprintf(&summary, "| Response time | %s |\n", time.Duration(metadata.ResponseSeconds)*time.Second)
+	if metadata.PortalUrl != "" {
+		fmt.Fprintf(&summary, "| Portal | %s |\n", escapeMarkdownTableCell(metadata.PortalUrl))
+	}
+	summary.WriteString("\n")
+
+	if values := s.Values(); len(values) > 0 {
+		summary.WriteString("### Submitted values\n\n")
+		summary.WriteString("| Field | Value |\n")
+		summary.WriteString("| --- | --- |\n")
+		for _, value := range values {
+			displayValue := "`" + strings.ReplaceAll(value.Value, "`", "'") + "`"
+			if value.Value == "" {
+				displayValue = "_empty_"
+			}
+			if value.Sensitive {
+				displayValue = redactedValue
+			}
+
+			fmt.Fprintf(&summary, "| %s | %s |\n", escapeMarkdownTableCell(fieldDisplay(value)), escapeMarkdownTableCell(displayValue))
+		}
+		summary.WriteString("\n")
+	}
+
+	if uploads := s.Uploads(); len(uploads) > 0 {
+		summary.WriteString("### Uploaded files\n\n")
+		for _, upload := range uploads {
+			fmt.Fprintf(&summary, "**%s** (manifest: `%s`)\n\n", escapeMarkdownTableCell(upload.Label), upload.ManifestPath)
+
+			if len(upload.Files) == 0 {
+				summary.WriteString("_No files uploaded_\n\n")
+				continue
+			}
+
+			summary.WriteString("| File | Size | SHA-256 | Status |\n")
+			summary.WriteString("| --- | --- | --- | --- |\n")
+			for _, file := range upload.Files {
+				status := file.Status
+				if file.Reason != "" {
+					status = fmt.Sprintf("%s (%s)", file.Status, file.Reason)
+				}
+
+				fmt.Fprintf(
+					&summary,
+					"| %s | %s | `%s` | %s |\n",
+					escapeMarkdownTableCell(file.Name),
+					formatBytes(file.Size),
+					file.Sha256,
+					escapeMarkdownTableCell(status),
+				)
+			}
+			summary.WriteString("\n")
+		}
+	}
+
+	summary.WriteString("### Timeline\n\n")
+	summary.WriteString("| Time (UTC) | Elapsed | Event |\n")
+	summary.WriteString("| --- | --- | --- |\n")
+	for _, event := range s.Timeline() {
+		fmt.Fprintf(
+			&summary,
+			"| %s | +%s | %s |\n",
+			formatTime(event.At),
+			event.At.Sub(s.OpenedAt()).Round(time.Second),
+			escapeMarkdownTableCell(event.Description),
+		)
+	}
+
+	return summary.String()
+}
+
+// outcomeDisplay returns the outcome with an emoji, making it easy to spot
+func outcomeDisplay(outcome string) string {
+	switch outcome {
+	case OutcomeSubmitted:
+		return "✅ Submitted"
+	case OutcomeCancelled:
+		return "🚫 Cancelled"
+	case OutcomeTimedOut:
+		return "⌛ Timed out"
+	}
+
+	return "⏳ Open"
+}
+
+// decisionDisplay returns the decision with an emoji, making it easy to spot
+func decisionDisplay(decision string) string {
+	switch decision {
+	case approval.DecisionApproved:
+		return "✅ Approved"
+	case approval.DecisionRejected:
+		return "❌ Rejected"
+	}
+
+	return decision
+}
+
+// submitterDisplay returns who closed the portal in a human readable form
+func submitterDisplay(submitter *Submitter) string {
+	if submitter == nil {
+		return "-"
+	}
+
+	return submitter.Display()
+}
+
+// fieldDisplay returns the display name of the field along with its label
+func fieldDisplay(value FieldValue) string {
+	if value.Display == "" || value.Display == value.Label {
+		return value.Label
+	}
+
+	return fmt.Sprintf("%s (`%s`)", value.Display, value.Label)
+}
+
+// escapeMarkdownTableCell makes the text safe to use within a markdown table cell
+func escapeMarkdownTableCell(text string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>").Replace(text)
+}
+
+// formatTime returns the time in a consistent, human readable format
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+// formatBytes returns the size in a human readable format
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,197 @@
+package state
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/session"
+)
+
+const (
+	// StateOpen is the state of a portal waiting for input
+	StateOpen = "open"
+
+	// StateSubmitting is the state of a portal whose submission is being handled
+	StateSubmitting = "submitting"
+
+	// StateSubmitted is the state of a portal that has been submitted
+	StateSubmitted = "submitted"
+
+	// StateCancelled is the state of a portal that has been cancelled
+	StateCancelled = "cancelled"
+
+	// StateExpired is the state of a portal that timed out before it was submitted or cancelled
+	StateExpired = "expired"
+
+	// IdempotencyKeyHeaderName is the header submit and cancel requests carry the idempotency
+	// key of the page they were made from in, so repeating them (i.e. double-clicking) is harmless
+	IdempotencyKeyHeaderName = "Idempotency-Key"
+)
+
+// New returns a new instance of Machine, in the open state
+func New() *Machine {
+	return &Machine{
+		state: StateOpen,
+	}
+}
+
+// Machine moves the portal from open to submitting and then submitted, or from open to
+// cancelled or expired, making sure only the first submission or cancellation wins
+type Machine struct {
+
+	// mutex guards the machine
+	mutex sync.Mutex
+
+	// state is the state the portal is in
+	state string
+
+	// submitter is who moved the portal out of the open state
+	submitter *session.Submitter
+
+	// changedAt is when the portal moved out of the open state
+	changedAt time.Time
+
+	// idempotencyKey is the idempotency key of the request that moved the portal out of the
+	// open state
+	idempotencyKey string
+}
+
+// Status represents the state the portal is in, and who put it there
+type Status struct {
+
+	// State is the state the portal is in
+	State string
+
+	// Submitter is who moved the portal out of the open state, nil while it is open or
+	// once it has expired
+	Submitter *session.Submitter
+
+	// ChangedAt is when the portal moved out of the open state
+	ChangedAt time.Time
+}
+
+// BeginSubmit moves an open portal to submitting. A repeat of the request that is
+// submitting (or has submitted) the portal returns ErrDuplicateRequest, any other request
+// returns ErrPortalNotOpen once the portal is no longer open
+func (m *Machine) BeginSubmit(idempotencyKey string, submitter *session.Submitter) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.state != StateOpen {
+		if m.isRepeat(idempotencyKey, StateSubmitting, StateSubmitted) {
+			return errors.ErrDuplicateRequest
+		}
+
+		return errors.ErrPortalNotOpen
+	}
+
+	m.leaveOpen(StateSubmitting, idempotencyKey, submitter)
+
+	return nil
+}
+
+// CompleteSubmit moves a submitting portal to submitted
+func (m *Machine) CompleteSubmit() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.state == StateSubmitting {
+		m.state = StateSubmitted
+	}
+}
+
+// AbortSubmit moves a submitting portal back to open, i.e. when the submission was
+// rejected, so it can be submitted again
+func (m *Machine) AbortSubmit() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.state == StateSubmitting {
+		m.state = StateOpen
+		m.submitter = nil
+		m.changedAt = time.Time{}
+		m.idempotencyKey = ""
+	}
+}
+
+// Cancel moves an open portal to cancelled. A repeat of the request that cancelled the
+// portal returns ErrDuplicateRequest, any other request returns ErrPortalNotOpen once the
+// portal is no longer open
+func (m *Machine) Cancel(idempotencyKey string, submitter *session.Submitter) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.state != StateOpen {
+		if m.isRepeat(idempotencyKey, StateCancelled) {
+			return errors.ErrDuplicateRequest
+		}
+
+		return errors.ErrPortalNotOpen
+	}
+
+	m.leaveOpen(StateCancelled, idempotencyKey, submitter)
+
+	return nil
+}
+
+// Expire moves an open portal to expired, returning false when it was already submitted,
+// cancelled or is being submitted
+func (m *Machine) Expire() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.state != StateOpen {
+		return false
+	}
+
+	m.leaveOpen(StateExpired, "", nil)
+
+	return true
+}
+
+// Status returns the state the portal is in, and who put it there
+func (m *Machine) Status() Status {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return Status{
+		State:     m.state,
+		Submitter: m.submitter,
+		ChangedAt: m.changedAt,
+	}
+}
+
+// leaveOpen moves the open portal to the state
+func (m *Machine) leaveOpen(state, idempotencyKey string, submitter *session.Submitter) {
+	m.state = state
+	m.submitter = submitter
+	m.changedAt = time.Now().UTC()
+	m.idempotencyKey = idempotencyKey
+}
+
+// isRepeat returns whether the idempotency key is the one of the request that moved the
+// portal to one of the states
+func (m *Machine) isRepeat(idempotencyKey string, states ...string) bool {
+	if idempotencyKey == "" || idempotencyKey != m.idempotencyKey {
+		return false
+	}
+
+	for _, state := range states {
+		if m.state == state {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewIdempotencyKey returns a new, random idempotency key
+func NewIdempotencyKey() string {
+	randomBytes := make([]byte, 16)
+	rand.Read(randomBytes)
+
+	return hex.EncodeToString(randomBytes)
+}
@@ -0,0 +1,139 @@
+package state_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/session"
+	"github.com/boasihq/interactive-inputs/internal/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMachine(t *testing.T) {
+
+	reviewerA := &session.Submitter{Login: "reviewer-a"}
+	reviewerB := &session.Submitter{Login: "reviewer-b"}
+
+	tests := []struct {
+		name              string
+		run               func(m *state.Machine) []error
+		expectedErrors    []error
+		expectedState     string
+		expectedSubmitter *session.Submitter
+	}{
+		{
+			name: "first submission wins",
+			run: func(m *state.Machine) []error {
+				errs := []error{m.BeginSubmit("key-a", reviewerA)}
+				errs = append(errs, m.BeginSubmit("key-b", reviewerB))
+				m.CompleteSubmit()
+				errs = append(errs, m.BeginSubmit("key-b", reviewerB))
+				return append(errs, m.Cancel("key-b", reviewerB))
+			},
+			expectedErrors:    []error{nil, errors.ErrPortalNotOpen, errors.ErrPortalNotOpen, errors.ErrPortalNotOpen},
+			expectedState:     state.StateSubmitted,
+			expectedSubmitter: reviewerA,
+		},
+		{
+			name: "repeated submission",
+			run: func(m *state.Machine) []error {
+				errs := []error{m.BeginSubmit("key-a", reviewerA)}
+				errs = append(errs, m.BeginSubmit("key-a", reviewerA))
+				m.CompleteSubmit()
+				return append(errs, m.BeginSubmit("key-a", reviewerA))
+			},
+			expectedErrors:    []error{nil, errors.ErrDuplicateRequest, errors.ErrDuplicateRequest},
+			expectedState:     state.StateSubmitted,
+			expectedSubmitter: reviewerA,
+		},
+		{
+			name: "submission without idempotency key isn't a repeat",
+			run: func(m *state.Machine) []error {
+				return []error{m.BeginSubmit("", reviewerA), m.BeginSubmit("", reviewerA)}
+			},
+			expectedErrors:    []error{nil, errors.ErrPortalNotOpen},
+			expectedState:     state.StateSubmitting,
+			expectedSubmitter: reviewerA,
+		},
+		{
+			name: "rejected submission reopens the portal",
+			run: func(m *state.Machine) []error {
+				errs := []error{m.BeginSubmit("key-a", reviewerA)}
+				m.AbortSubmit()
+				return append(errs, m.BeginSubmit("key-b", reviewerB))
+			},
+			expectedErrors:    []error{nil, nil},
+			expectedState:     state.StateSubmitting,
+			expectedSubmitter: reviewerB,
+		},
+		{
+			name: "repeated cancellation",
+			run: func(m *state.Machine) []error {
+				errs := []error{m.Cancel("key-a", reviewerA)}
+				errs = append(errs, m.Cancel("key-a", reviewerA))
+				return append(errs, m.BeginSubmit("key-a", reviewerA))
+			},
+			expectedErrors:    []error{nil, errors.ErrDuplicateRequest, errors.ErrPortalNotOpen},
+			expectedState:     state.StateCancelled,
+			expectedSubmitter: reviewerA,
+		},
+		{
+			name: "submission after expiry",
+			run: func(m *state.Machine) []error {
+				assert.True(t, m.Expire())
+				assert.False(t, m.Expire())
+				return []error{m.BeginSubmit("key-a", reviewerA)}
+			},
+			expectedErrors: []error{errors.ErrPortalNotOpen},
+			expectedState:  state.StateExpired,
+		},
+		{
+			name: "expiry after submission",
+			run: func(m *state.Machine) []error {
+				errs := []error{m.BeginSubmit("key-a", reviewerA)}
+				assert.False(t, m.Expire())
+				return errs
+			},
+			expectedErrors:    []error{nil},
+			expectedState:     state.StateSubmitting,
+			expectedSubmitter: reviewerA,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := state.New()
+
+			assert.Equal(t, tt.expectedErrors, tt.run(m))
+
+			status := m.Status()
+			assert.Equal(t, tt.expectedState, status.State)
+			assert.Equal(t, tt.expectedSubmitter, status.Submitter)
+		})
+	}
+}
+
+func TestMachine_ConcurrentSubmissions(t *testing.T) {
+	m := state.New()
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	winners := 0
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if m.BeginSubmit(state.NewIdempotencyKey(), &session.Submitter{Ip: "192.0.2.1"}) == nil {
+				mutex.Lock()
+				winners++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, winners)
+}
@@ -0,0 +1,128 @@
+package subcommand
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/boasihq/interactive-inputs/internal/encryption"
+	"github.com/boasihq/interactive-inputs/internal/errors"
+)
+
+const (
+	// DecryptIdentityEnvVar is the environment variable the private key used to decrypt
+	// uploaded files can be provided with, instead of a file
+	DecryptIdentityEnvVar = "IAIP_DECRYPT_IDENTITY"
+
+	// DecryptedFileExtension is appended to the name of a decrypted file when the encrypted
+	// file's name doesn't end in .age
+	DecryptedFileExtension = ".decrypted"
+)
+
+// runDecrypt decrypts the encrypted uploaded file(s) at the input path using the
+// provided private key. When the input is a directory every file ending in .age
+// is decrypted, preserving the directory structure in the output directory.
+//
+// Usage: decrypt --input <file|dir> [--output <file|dir>] [--identity <path>]
+func runDecrypt(args []string, stdout io.Writer) error {
+	var identityPath, inputPath, outputPath string
+
+	flags := flag.NewFlagSet("decrypt", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	flags.StringVar(&identityPath, "identity", "", fmt.Sprintf("path to the age identity file or SSH private key (defaults to the %s environment variable)", DecryptIdentityEnvVar))
+	flags.StringVar(&inputPath, "input", "", "path to the encrypted file or the directory holding the encrypted files")
+	flags.StringVar(&outputPath, "output", "", "path the decrypted file(s) are written to (defaults to next to the encrypted file(s), without the .age extension or with a .decrypted one)")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if inputPath == "" {
+		return fmt.Errorf("%w: --input is required", errors.ErrInvalidSubcommandArgumentsProvided)
+	}
+
+	privateKey := []byte(os.Getenv(DecryptIdentityEnvVar))
+	if identityPath != "" {
+		privateKey, err = os.ReadFile(identityPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(privateKey) == 0 {
+		return fmt.Errorf("%w: provide --identity or set %s", errors.ErrInvalidSubcommandArgumentsProvided, DecryptIdentityEnvVar)
+	}
+
+	identities, err := encryption.ParseIdentities(privateKey)
+	if err != nil {
+		return fmt.Errorf("unable to parse the private key: %w", err)
+	}
+
+	inputInfo, err := os.Stat(inputPath)
+	if err != nil {
+		return err
+	}
+
+	// decrypt a single file
+	if !inputInfo.IsDir() {
+		// files that don't end in .age get a suffix instead, so they aren't decrypted over
+		// themselves
+		if outputPath == "" {
+			outputPath = strings.TrimSuffix(inputPath, encryption.EncryptedFileExtension)
+			if outputPath == inputPath {
+				outputPath = inputPath + DecryptedFileExtension
+			}
+		}
+
+		err = encryption.DecryptFile(identities, inputPath, outputPath)
+		if err != nil {
+			return fmt.Errorf("unable to decrypt %s: %w", inputPath, err)
+		}
+
+		fmt.Fprintf(stdout, "Decrypted %s -> %s\n", inputPath, outputPath)
+		return nil
+	}
+
+	// decrypt every encrypted file in the directory
+	if outputPath == "" {
+		outputPath = inputPath
+	}
+
+	var totalDecrypted int
+	err = filepath.WalkDir(inputPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), encryption.EncryptedFileExtension) {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(inputPath, path)
+		if err != nil {
+			return err
+		}
+
+		destinationPath := filepath.Join(outputPath, strings.TrimSuffix(relativePath, encryption.EncryptedFileExtension))
+		err = encryption.DecryptFile(identities, path, destinationPath)
+		if err != nil {
+			return fmt.Errorf("unable to decrypt %s: %w", path, err)
+		}
+
+		totalDecrypted++
+		fmt.Fprintf(stdout, "Decrypted %s -> %s\n", path, destinationPath)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "Successfully decrypted %d file(s)\n", totalDecrypted)
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package subcommand
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+)
+
+const (
+	// OutputKeyCliPath is the key of the output holding the path of the action's binary, so
+	// later steps can run its helper subcommands
+	OutputKeyCliPath = "cli-path"
+)
+
+// subcommands is the mapping of subcommand name to the function that runs it
+var subcommands = map[string]func(args []string, stdout io.Writer) error{
+	"decrypt":        runDecrypt,
+	"verify-receipt": runVerifyReceipt,
+}
+
+// Run executes the named helper subcommand with the provided arguments, writing any
+// output intended for the user to stdout.
+func Run(name string, args []string, stdout io.Writer) error {
+	subcommand, ok := subcommands[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", errors.ErrUnknownSubcommand, name)
+	}
+
+	return subcommand(args, stdout)
+}
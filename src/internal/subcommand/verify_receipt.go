@@ -0,0 +1,78 @@
+package subcommand
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/receipt"
+)
+
+const (
+	// VerifyReceiptKeyEnvVar is the environment variable the key used to verify submission
+	// receipts can be provided with, instead of a file
+	VerifyReceiptKeyEnvVar = "IAIP_RECEIPT_KEY"
+)
+
+// runVerifyReceipt checks that the submission receipt was signed with the provided key and
+// hasn't been modified since. The algorithm has to be provided rather than read from the
+// receipt, so a forged receipt can't choose how it is checked.
+//
+// Usage: verify-receipt --receipt <path> [--key <path>] [--algorithm <ed25519|hmac-sha256>]
+func runVerifyReceipt(args []string, stdout io.Writer) error {
+	var receiptPath, keyPath, algorithm string
+
+	flags := flag.NewFlagSet("verify-receipt", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	flags.StringVar(&receiptPath, "receipt", "", "path to the submission receipt")
+	flags.StringVar(&keyPath, "key", "", fmt.Sprintf("path to the Ed25519 public key or HMAC secret (defaults to the %s environment variable)", VerifyReceiptKeyEnvVar))
+	flags.StringVar(&algorithm, "algorithm", receipt.AlgorithmHmacSha256, fmt.Sprintf("algorithm the receipt was signed with (%s)", strings.Join(receipt.ValidAlgorithms, ", ")))
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if receiptPath == "" {
+		return fmt.Errorf("%w: --receipt is required", errors.ErrInvalidSubcommandArgumentsProvided)
+	}
+
+	if !slices.Contains(receipt.ValidAlgorithms, algorithm) {
+		return fmt.Errorf("%w: --algorithm must be one of %s", errors.ErrInvalidSubcommandArgumentsProvided, strings.Join(receipt.ValidAlgorithms, ", "))
+	}
+
+	key := []byte(os.Getenv(VerifyReceiptKeyEnvVar))
+	if keyPath != "" {
+		key, err = os.ReadFile(keyPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(key) == 0 {
+		return fmt.Errorf("%w: provide --key or set %s", errors.ErrInvalidSubcommandArgumentsProvided, VerifyReceiptKeyEnvVar)
+	}
+
+	encodedReceipt, err := os.ReadFile(receiptPath)
+	if err != nil {
+		return err
+	}
+
+	submissionReceipt, err := receipt.Parse(encodedReceipt)
+	if err != nil {
+		return fmt.Errorf("unable to parse the receipt: %w", err)
+	}
+
+	err = receipt.Verify(submissionReceipt, algorithm, key)
+	if err != nil {
+		return fmt.Errorf("unable to verify %s: %w", receiptPath, err)
+	}
+
+	fmt.Fprintf(stdout, "Verified %s (run %d, attempt %d, submitted at %s)\n", receiptPath, submissionReceipt.RunId, submissionReceipt.RunAttempt, submissionReceipt.SubmittedAt)
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package subcommand_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/receipt"
+	"github.com/boasihq/interactive-inputs/internal/subcommand"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun_VerifyReceipt(t *testing.T) {
+
+	// the signing key is read from the (trimmed) action input
+	signer, err := receipt.NewSigner(&receipt.NewSignerRequest{Algorithm: receipt.AlgorithmHmacSha256, Key: []byte("s3cr3t")})
+	assert.NoError(t, err)
+
+	submittedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	submissionReceipt := receipt.New(&receipt.NewReceiptRequest{
+		Repository:  "octo-org/octo-repo",
+		RunId:       1234,
+		RunAttempt:  1,
+		OpenedAt:    submittedAt.Add(-time.Minute),
+		SubmittedAt: submittedAt,
+		Values:      []receipt.Value{receipt.NewValue("environment", "staging", false)},
+	})
+	assert.NoError(t, signer.Sign(submissionReceipt))
+
+	encodedReceipt, err := json.Marshal(submissionReceipt)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		receipt        string
+		key            string
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name:           "key file ending in a newline",
+			receipt:        string(encodedReceipt),
+			key:            "s3cr3t\n",
+			expectedOutput: "(run 1234, attempt 1, submitted at 2024-05-01T12:00:00Z)",
+		},
+		{
+			name:          "wrong key",
+			receipt:       string(encodedReceipt),
+			key:           "guess\n",
+			expectedError: errors.ErrInvalidReceiptSignature,
+		},
+		{
+			name:          "added key",
+			receipt:       strings.Replace(string(encodedReceipt), `"version":1,`, `"version":1,"approved_by":"mallory",`, 1),
+			key:           "s3cr3t\n",
+			expectedError: errors.ErrInvalidReceiptProvided,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			receiptPath := filepath.Join(dir, receipt.DefaultFileName)
+			keyPath := filepath.Join(dir, "receipt.key")
+			assert.NoError(t, os.WriteFile(receiptPath, []byte(tt.receipt), 0600))
+			assert.NoError(t, os.WriteFile(keyPath, []byte(tt.key), 0600))
+
+			var stdout bytes.Buffer
+			err := subcommand.Run("verify-receipt", []string{"--receipt", receiptPath, "--key", keyPath}, &stdout)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Contains(t, stdout.String(), tt.expectedOutput)
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package tunnel
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"golang.ngrok.com/ngrok"
+	nconfig "golang.ngrok.com/ngrok/config"
+)
+
+const (
+	// minBasicAuthPasswordLength is the shortest basic auth password ngrok accepts
+	minBasicAuthPasswordLength = 8
+
+	// maxBasicAuthPasswordLength is the longest basic auth password ngrok accepts
+	maxBasicAuthPasswordLength = 128
+)
+
+// ValidOAuthProviders is the list of OAuth providers ngrok can sign visitors in with
+// before they reach the portal
+var ValidOAuthProviders = []string{"amazon", "facebook", "github", "gitlab", "google", "linkedin", "microsoft", "twitch"}
+
+// ValidRegions is the list of regions the ngrok session can be started in
+var ValidRegions = []string{"us", "us-cal-1", "eu", "ap", "au", "sa", "jp", "in"}
+
+// NewEndpointRequest is the request object for creating a new instance of Endpoint
+type NewEndpointRequest struct {
+
+	// Authtoken is the ngrok authtoken the session is started with
+	Authtoken string
+
+	// Domain is the reserved (static) domain the portal is served on, a random one is
+	// used when empty
+	Domain string
+
+	// Region is the region the session is started in, the closest one is used when empty
+	Region string
+
+	// Metadata is the opaque metadata attached to the endpoint, shown in the ngrok dashboard
+	Metadata string
+
+	// OAuthProvider is the provider ngrok signs visitors in with before they reach the
+	// portal, disabled when empty
+	OAuthProvider string
+
+	// OAuthAllowedEmails is the list of email addresses allowed through ngrok's OAuth
+	OAuthAllowedEmails []string
+
+	// OAuthAllowedDomains is the list of email domains allowed through ngrok's OAuth
+	OAuthAllowedDomains []string
+
+	// BasicAuth is the list of username:password credentials accepted by ngrok
+	BasicAuth []string
+
+	// AllowCidrs is the list of CIDRs allowed to reach the portal
+	AllowCidrs []string
+
+	// DenyCidrs is the list of CIDRs refused from reaching the portal
+	DenyCidrs []string
+}
+
+// NewEndpoint returns a new instance of Endpoint
+func NewEndpoint(r *NewEndpointRequest) *Endpoint {
+	return &Endpoint{
+		authtoken:           r.Authtoken,
+		domain:              r.Domain,
+		region:              r.Region,
+		metadata:            r.Metadata,
+		oauthProvider:       r.OAuthProvider,
+		oauthAllowedEmails:  r.OAuthAllowedEmails,
+		oauthAllowedDomains: r.OAuthAllowedDomains,
+		basicAuth:           r.BasicAuth,
+		allowCidrs:          r.AllowCidrs,
+		denyCidrs:           r.DenyCidrs,
+	}
+}
+
+// Endpoint is the ngrok HTTP endpoint the portal is reachable through, along with the
+// protection ngrok applies at its edge before requests reach the portal
+type Endpoint struct {
+
+	// authtoken is the ngrok authtoken the session is started with
+	authtoken string
+
+	// domain is the reserved (static) domain the portal is served on
+	domain string
+
+	// region is the region the session is started in
+	region string
+
+	// metadata is the opaque metadata attached to the endpoint
+	metadata string
+
+	// oauthProvider is the provider ngrok signs visitors in with
+	oauthProvider string
+
+	// oauthAllowedEmails is the list of email addresses allowed through ngrok's OAuth
+	oauthAllowedEmails []string
+
+	// oauthAllowedDomains is the list of email domains allowed through ngrok's OAuth
+	oauthAllowedDomains []string
+
+	// basicAuth is the list of username:password credentials accepted by ngrok
+	basicAuth []string
+
+	// allowCidrs is the list of CIDRs allowed to reach the portal
+	allowCidrs []string
+
+	// denyCidrs is the list of CIDRs refused from reaching the portal
+	denyCidrs []string
+}
+
+// Listen starts the ngrok session and returns the tunnel the portal is served through
+func (e *Endpoint) Listen(ctx context.Context) (ngrok.Tunnel, error) {
+	return ngrok.Listen(ctx, nconfig.HTTPEndpoint(e.endpointOptions()...), e.connectOptions()...)
+}
+
+// Protected returns whether ngrok stops any requests before they reach the portal
+func (e *Endpoint) Protected() bool {
+	return e.oauthProvider != "" || len(e.basicAuth) > 0 || len(e.allowCidrs) > 0 || len(e.denyCidrs) > 0
+}
+
+// connectOptions returns the options the ngrok session is started with
+func (e *Endpoint) connectOptions() []ngrok.ConnectOption {
+	options := []ngrok.ConnectOption{ngrok.WithAuthtoken(e.authtoken)}
+
+	if e.region != "" {
+		options = append(options, ngrok.WithRegion(e.region))
+	}
+
+	return options
+}
+
+// endpointOptions returns the options the HTTP endpoint is created with
+func (e *Endpoint) endpointOptions() []nconfig.HTTPEndpointOption {
+	var options []nconfig.HTTPEndpointOption
+
+	if e.domain != "" {
+		options = append(options, nconfig.WithDomain(e.domain))
+	}
+
+	if e.metadata != "" {
+		options = append(options, nconfig.WithMetadata(e.metadata))
+	}
+
+	if e.oauthProvider != "" {
+		var oauthOptions []nconfig.OAuthOption
+		if len(e.oauthAllowedEmails) > 0 {
+			oauthOptions = append(oauthOptions, nconfig.WithAllowOAuthEmail(e.oauthAllowedEmails...))
+		}
+		if len(e.oauthAllowedDomains) > 0 {
+			oauthOptions = append(oauthOptions, nconfig.WithAllowOAuthDomain(e.oauthAllowedDomains...))
+		}
+
+		options = append(options, nconfig.WithOAuth(e.oauthProvider, oauthOptions...))
+	}
+
+	for _, credential := range e.basicAuth {
+		username, password, _ := ParseBasicAuth(credential)
+		options = append(options, nconfig.WithBasicAuth(username, password))
+	}
+
+	if len(e.allowCidrs) > 0 {
+		options = append(options, nconfig.WithAllowCIDRString(e.allowCidrs...))
+	}
+
+	if len(e.denyCidrs) > 0 {
+		options = append(options, nconfig.WithDenyCIDRString(e.denyCidrs...))
+	}
+
+	return options
+}
+
+// ParseBasicAuth returns the username and password of a username:password credential,
+// checking they are acceptable to ngrok
+func ParseBasicAuth(credential string) (string, string, error) {
+	username, password, ok := strings.Cut(credential, ":")
+	if !ok || username == "" {
+		return "", "", fmt.Errorf("%w: credentials must be in the username:password format", errors.ErrInvalidNgrokBasicAuthProvided)
+	}
+
+	if len(password) < minBasicAuthPasswordLength || len(password) > maxBasicAuthPasswordLength {
+		return "", "", fmt.Errorf("%w: passwords must be between %d and %d characters long", errors.ErrInvalidNgrokBasicAuthProvided, minBasicAuthPasswordLength, maxBasicAuthPasswordLength)
+	}
+
+	return username, password, nil
+}
+
+// ValidateCidr returns an error when the CIDR (i.e. 10.0.0.0/8) can't be parsed
+func ValidateCidr(cidr string) error {
+	if _, _, err := net.ParseCIDR(cidr); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrInvalidNgrokCidrProvided, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package tunnel_test
+
+import (
+	"testing"
+
+	"github.com/boasihq/interactive-inputs/internal/errors"
+	"github.com/boasihq/interactive-inputs/internal/tunnel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBasicAuth(t *testing.T) {
+
+	tests := []struct {
+		name             string
+		credential       string
+		expectedUsername string
+		expectedPassword string
+		expectedError    error
+	}{
+		{
+			name:             "username and password",
+			credential:       "release:correct-horse",
+			expectedUsername: "release",
+			expectedPassword: "correct-horse",
+		},
+		{
+			name:             "password containing a colon",
+			credential:       "release:correct:horse",
+			expectedUsername: "release",
+			expectedPassword: "correct:horse",
+		},
+		{
+			name:          "missing password",
+			credential:    "release",
+			expectedError: errors.ErrInvalidNgrokBasicAuthProvided,
+		},
+		{
+			name:          "missing username",
+			credential:    ":correct-horse",
+			expectedError: errors.ErrInvalidNgrokBasicAuthProvided,
+		},
+		{
+			name:          "short password",
+			credential:    "release:short",
+			expectedError: errors.ErrInvalidNgrokBasicAuthProvided,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			username, password, err := tunnel.ParseBasicAuth(tt.credential)
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expectedUsername, username)
+			assert.Equal(t, tt.expectedPassword, password)
+		})
+	}
+}
+
+func TestValidateCidr(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		cidr          string
+		expectedError error
+	}{
+		{
+			name: "ipv4",
+			cidr: "10.0.0.0/8",
+		},
+		{
+			name: "ipv6",
+			cidr: "2001:db8::/32",
+		},
+		{
+			name:          "missing prefix length",
+			cidr:          "10.0.0.1",
+			expectedError: errors.ErrInvalidNgrokCidrProvided,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tunnel.ValidateCidr(tt.cidr), tt.expectedError)
+		})
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"net/http"
 
 	"github.com/boasihq/interactive-inputs/internal/config"
+	"github.com/boasihq/interactive-inputs/internal/security"
+	"github.com/boasihq/interactive-inputs/internal/state"
 	"github.com/boasihq/interactive-inputs/internal/toolbox"
 	githubactions "github.com/sethvargo/go-githubactions"
 	"go.uber.org/zap"
@@ -63,6 +65,14 @@ func (h *Handler) Home(w http.ResponseWriter, r *http.Request) {
 		Title:     h.config.Title,
 		Fields:    h.config.Fields,
 		Timeout:   toolbox.SecondsToMinutes(h.config.Timeout),
+		CsrfToken: security.CsrfToken(r.Context()),
+		CspNonce:  security.CspNonce(r.Context()),
+		Approval:  h.config.Approval,
+
+		ApprovalQuorum: h.config.ApprovalQuorumEnabled(),
+
+		// every page load gets its own key, so only repeats from the same page are ignored
+		IdempotencyKey: state.NewIdempotencyKey(),
 	}
 
 	// list of template files to parse, must be in order of inheritence
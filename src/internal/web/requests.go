@@ -17,4 +17,22 @@ type CreateInteractiveInputsPortalRequest struct {
 	// Timeout is how long the portal will be available for users to use before it is
 	// automatically deactivated
 	Timeout string
+
+	// CsrfToken is the token requests from the portal are sent with
+	CsrfToken string
+
+	// CspNonce is the nonce the portal's inline scripts carry
+	CspNonce string
+
+	// IdempotencyKey is the key submit and cancel requests from the page are sent with,
+	// so repeating them is harmless
+	IdempotencyKey string
+
+	// Approval is true when the portal asks for an approve or reject decision (with a
+	// comment) instead of being submitted or cancelled
+	Approval bool
+
+	// ApprovalQuorum is true when the portal stays open until several approvers have
+	// decided, showing the live tally of their decisions
+	ApprovalQuorum bool
 }
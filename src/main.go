@@ -3,11 +3,13 @@ package main
 import (
 	"context"
 	"embed"
+	"fmt"
 	"os"
 	"time"
 
 	"github.com/boasihq/interactive-inputs/internal/config"
 	"github.com/boasihq/interactive-inputs/internal/runner"
+	"github.com/boasihq/interactive-inputs/internal/subcommand"
 	githubactions "github.com/sethvargo/go-githubactions"
 
 	_ "embed"
@@ -27,6 +29,14 @@ func run() error {
 		err    error
 	)
 
+	// the post step only needs to tidy up after the main step
+	if runner.IsPostStep() {
+		return runner.InvokePostAction(action)
+	}
+
+	// mark the state so that the post step knows it isn't the main step
+	action.SaveState(runner.PostStateKey, "true")
+
 	// Added logic to bypass the config parse
 	if os.Getenv("IAIP_SKIP_CONFIG_PARSE") == "" {
 		cfg, err = config.NewFromInputs(action)
@@ -47,6 +57,17 @@ func run() error {
 }
 
 func main() {
+
+	// handle helper subcommands, i.e. decrypt, which can be used in later steps
+	if len(os.Args) > 1 {
+		err := subcommand.Run(os.Args[1], os.Args[2:], os.Stdout)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	err := run()
 	if err != nil {
 		githubactions.Fatalf("%v", err)